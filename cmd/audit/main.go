@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/audit"
+)
+
+const usage = `Usage: audit <command> [options] <file>
+
+Check audit logs written by the scheduling service.
+Each record carries the hash of the record before it, across rotated logs.
+
+Commands:
+  verify [-prev hash] <log>                   Check the hash chain of the rotated logs and the log, exits 1 if broken
+`
+
+// errFailed signals a failed check that has already been reported
+var errFailed = errors.New("check failed")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "verify":
+		err = verify(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	switch err {
+	case nil:
+	case flag.ErrHelp:
+	case errFailed:
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	prevHash := fs.String(
+		"prev", "", "Hash of the record before the oldest log, if older logs were archived or removed",
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected one audit log")
+	}
+
+	files, err := audit.Files(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	lastHash, total := *prevHash, 0
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		var n int
+		lastHash, n, err = audit.Verify(file, lastHash)
+		file.Close()
+		total += n
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return errFailed
+		}
+	}
+
+	fmt.Printf("%d records in %d files verified, last hash %s\n", total, len(files), lastHash)
+	return nil
+}
@@ -0,0 +1,688 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// command registers its flags and returns the function that executes it
+type command func(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error
+
+var commands = map[string]command{
+	"week":          weekCmd,
+	"day":           dayCmd,
+	"show":          showCmd,
+	"create":        createCmd,
+	"delete":        deleteCmd,
+	"update":        updateCmd,
+	"move":          moveCmd,
+	"swap":          swapCmd,
+	"add-voted":     addVotedCmd,
+	"remove-voted":  removeVotedCmd,
+	"reorder-voted": reorderVotedCmd,
+	"vote":          voteCmd,
+	"import":        importCmd,
+	"batch":         batchCmd,
+	"export":        exportCmd,
+	"find":          findCmd,
+	"diff-draft":    diffDraftCmd,
+	"publish-draft": publishDraftCmd,
+	"discard-draft": discardDraftCmd,
+	"changes":       changesCmd,
+	"diff":          diffCmd,
+	"undo":          undoCmd,
+	"restore":       restoreCmd,
+}
+
+// flags identifying a show slot
+type slot struct {
+	prefix  string
+	weekDay int
+	screen  string
+	show    int
+	version int64
+}
+
+func slotFlags(fs *flag.FlagSet) *slot {
+	return prefixedSlotFlags(fs, "", "")
+}
+
+// registers flags for a second show slot, named with the prefix e.g -to-day
+func prefixedSlotFlags(fs *flag.FlagSet, prefix, label string) *slot {
+	s := &slot{prefix: prefix}
+	fs.IntVar(&s.weekDay, prefix+"day", 0, label+"Day of the week, 1 to 7")
+	fs.StringVar(&s.screen, prefix+"screen", "Screen 1", label+"Screen name")
+	fs.IntVar(&s.show, prefix+"show", 0, label+"Show number")
+	fs.Int64Var(&s.version, prefix+"version", 0, label+"Version the show must be at, 0 to skip the check")
+	return s
+}
+
+func (s *slot) validate() error {
+	switch {
+	case s.weekDay < 1 || s.weekDay > 7:
+		return fmt.Errorf("-%sday must be between 1 and 7", s.prefix)
+	case s.screen == "":
+		return fmt.Errorf("-%sscreen is required", s.prefix)
+	case s.show <= 0:
+		return fmt.Errorf("-%sshow is required", s.prefix)
+	}
+	return nil
+}
+
+func weekCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	from := fs.Int("from", 1, "First day of the week to show, 1 to 7")
+	to := fs.Int("to", 7, "Last day of the week to show, 1 to 7")
+	screens := fs.String("screens", "", "Comma separated screens to show, defaults to every screen")
+	movieID := fs.String("movie", "", "Only show the shows of this movie id")
+	fields := fs.String("fields", "", "Comma separated show fields to return e.g movie.id,movie.title")
+	draft := fs.Bool("draft", false, "Read the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		getReq := &scheduler.GetWeekScheduleRequest{
+			FromWeekDay: int32(*from),
+			ToWeekDay:   int32(*to),
+			MovieId:     *movieID,
+			Screens:     splitList(*screens),
+			Draft:       *draft,
+		}
+		if paths := splitList(*fields); len(paths) != 0 {
+			getReq.FieldMask = &field_mask.FieldMask{Paths: paths}
+		}
+		week, err := client.GetWeekSchedule(ctx, getReq)
+		if err != nil {
+			return err
+		}
+		return p.week(week)
+	}
+}
+
+// splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func dayCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	weekDay := fs.Int("day", 0, "Day of the week, 1 to 7")
+	draft := fs.Bool("draft", false, "Read the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *weekDay < 1 || *weekDay > 7 {
+			return errors.New("-day must be between 1 and 7")
+		}
+		daySchedule, err := client.GetDaySchedule(ctx, &scheduler.GetDayScheduleRequest{
+			WeekDay: int32(*weekDay),
+			Draft:   *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.day(int32(*weekDay), daySchedule)
+	}
+}
+
+func showCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	draft := fs.Bool("draft", false, "Read the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		showSchedule, err := client.GetShowSchedule(ctx, &scheduler.GetShowScheduleRequest{
+			WeekDay: int32(s.weekDay),
+			Screen:  s.screen,
+			Show:    int32(s.show),
+			Draft:   *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.show(showSchedule)
+	}
+}
+
+func createCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		_, err := client.CreateMovieDaySchedule(ctx, &scheduler.CreateMovieDayScheduleRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieId:         *movieID,
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done("movie %s scheduled for day %d %s show %d", *movieID, s.weekDay, s.screen, s.show)
+	}
+}
+
+func deleteCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		_, err := client.DeleteMovieDaySchedule(ctx, &scheduler.DeleteMovieDayScheduleRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieId:         *movieID,
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done("movie %s removed from day %d %s show %d", *movieID, s.weekDay, s.screen, s.show)
+	}
+}
+
+func updateCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie to show instead")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		_, err := client.UpdateMovieDaySchedule(ctx, &scheduler.UpdateMovieDayScheduleRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieId:         *movieID,
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done("movie %s now showing on day %d %s show %d", *movieID, s.weekDay, s.screen, s.show)
+	}
+}
+
+func moveCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie showing")
+	to := prefixedSlotFlags(fs, "to-", "Destination: ")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if err := to.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		_, err := client.MoveMovieDaySchedule(ctx, &scheduler.MoveMovieDayScheduleRequest{
+			WeekDay:           int32(s.weekDay),
+			Screen:            s.screen,
+			Show:              int32(s.show),
+			MovieId:           *movieID,
+			ToWeekDay:         int32(to.weekDay),
+			ToScreen:          to.screen,
+			ToShow:            int32(to.show),
+			ExpectedVersion:   s.version,
+			ToExpectedVersion: to.version,
+			Draft:             *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done(
+			"movie %s moved to day %d %s show %d", *movieID, to.weekDay, to.screen, to.show,
+		)
+	}
+}
+
+func swapCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	other := prefixedSlotFlags(fs, "other-", "Other show: ")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if err := other.validate(); err != nil {
+			return err
+		}
+		_, err := client.SwapMovieDaySchedules(ctx, &scheduler.SwapMovieDaySchedulesRequest{
+			WeekDay:              int32(s.weekDay),
+			Screen:               s.screen,
+			Show:                 int32(s.show),
+			OtherWeekDay:         int32(other.weekDay),
+			OtherScreen:          other.screen,
+			OtherShow:            int32(other.show),
+			ExpectedVersion:      s.version,
+			OtherExpectedVersion: other.version,
+			Draft:                *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done(
+			"swapped day %d %s show %d with day %d %s show %d",
+			s.weekDay, s.screen, s.show, other.weekDay, other.screen, other.show,
+		)
+	}
+}
+
+func addVotedCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		_, err := client.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieId:         *movieID,
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done("movie %s nominated for day %d %s show %d", *movieID, s.weekDay, s.screen, s.show)
+	}
+}
+
+func removeVotedCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie")
+	refund := fs.Bool("refund", false, "Refund votes cast for the movie to the users, instead of discarding them")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		res, err := client.RemoveVotedMovie(ctx, &scheduler.RemoveVotedMovieRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieId:         *movieID,
+			RefundVotes:     *refund,
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.removeResult(res)
+	}
+}
+
+func reorderVotedCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieIDs := fs.String("movies", "", "Comma separated ids of every voted movie in the new order")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		_, err := client.ReorderVotedMovies(ctx, &scheduler.ReorderVotedMoviesRequest{
+			WeekDay:         int32(s.weekDay),
+			Screen:          s.screen,
+			Show:            int32(s.show),
+			MovieIds:        splitList(*movieIDs),
+			ExpectedVersion: s.version,
+			Draft:           *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.done("voted movies reordered for day %d %s show %d", s.weekDay, s.screen, s.show)
+	}
+}
+
+func voteCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	s := slotFlags(fs)
+	movieID := fs.String("movie", "", "Id of the movie")
+	userID := fs.String("user", "", "Id of the user voting, must be the authenticated user if set")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		movieItem, err := client.VoteUpMovie(ctx, &scheduler.VoteUpMovieRequest{
+			WeekDay:    int32(s.weekDay),
+			Screen:     s.screen,
+			ShowNumber: int32(s.show),
+			MovieId:    *movieID,
+			UserId:     *userID,
+		})
+		if err != nil {
+			return err
+		}
+		return p.movie(movieItem)
+	}
+}
+
+// parses csv or json, guessing from the file extension when empty
+func scheduleFormat(format, file string) (scheduler.ScheduleFormat, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+	switch strings.ToLower(format) {
+	case "csv":
+		return scheduler.ScheduleFormat_CSV, nil
+	case "json":
+		return scheduler.ScheduleFormat_JSON, nil
+	}
+	return 0, fmt.Errorf("unknown schedule format %q, expected csv or json", format)
+}
+
+func importCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	file := fs.String("file", "", "CSV or JSON file with week_day, screen, show, movie_id and voted_movie_ids")
+	format := fs.String("format", "", "Format of the file: csv or json, defaults to the file extension")
+	dryRun := fs.Bool("dry-run", false, "Validate the rows without changing the schedule")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *file == "" {
+			return errors.New("-file is required")
+		}
+		scheduleFormat, err := scheduleFormat(*format, *file)
+		if err != nil {
+			return err
+		}
+		bs, err := ioutil.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		res, err := client.ImportSchedule(ctx, &scheduler.ImportScheduleRequest{
+			Format: scheduleFormat,
+			Data:   string(bs),
+			DryRun: *dryRun,
+			Draft:  *draft,
+		})
+		if err != nil {
+			return err
+		}
+		return p.importResult(res)
+	}
+}
+
+func batchCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	file := fs.String("file", "", `JSON file with the operations e.g {"operations": [{"create": {...}}, {"move": {...}}]}`)
+	validateOnly := fs.Bool("validate-only", false, "Check the operations without changing the schedule")
+	draft := fs.Bool("draft", false, "Change the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *file == "" {
+			return errors.New("-file is required")
+		}
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		batchReq := &scheduler.BatchUpdateScheduleRequest{}
+		err = jsonpb.Unmarshal(f, batchReq)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", *file, err)
+		}
+		batchReq.ValidateOnly = batchReq.ValidateOnly || *validateOnly
+		batchReq.Draft = batchReq.Draft || *draft
+
+		res, err := client.BatchUpdateSchedule(ctx, batchReq)
+		if err != nil {
+			return err
+		}
+		return p.batchResult(res)
+	}
+}
+
+func exportCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	format := fs.String("format", "csv", "Format of the export: csv or json")
+	file := fs.String("file", "", "File to write, defaults to standard output")
+	draft := fs.Bool("draft", false, "Export the draft instead of the published schedule")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		scheduleFormat, err := scheduleFormat(*format, "")
+		if err != nil {
+			return err
+		}
+		res, err := client.ExportSchedule(ctx, &scheduler.ExportScheduleRequest{
+			Format: scheduleFormat,
+			Draft:  *draft,
+		})
+		if err != nil {
+			return err
+		}
+		if *file == "" {
+			_, err = os.Stdout.WriteString(res.GetData())
+			return err
+		}
+		return ioutil.WriteFile(*file, []byte(res.GetData()), 0644)
+	}
+}
+
+func findCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	movieID := fs.String("movie", "", "Movie id")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *movieID == "" {
+			return errors.New("-movie is required")
+		}
+		res, err := client.FindMovieShows(ctx, &scheduler.FindMovieShowsRequest{
+			MovieId: *movieID,
+		})
+		if err != nil {
+			return err
+		}
+		return p.movieShows(res)
+	}
+}
+
+func diffDraftCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		res, err := client.DiffDraft(ctx, &scheduler.DiffDraftRequest{})
+		if err != nil {
+			return err
+		}
+		return p.differences(res, res.GetDifferences())
+	}
+}
+
+func publishDraftCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		res, err := client.PublishDraft(ctx, &scheduler.PublishDraftRequest{})
+		if err != nil {
+			return err
+		}
+		return p.differences(res, res.GetDifferences())
+	}
+}
+
+func discardDraftCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		_, err := client.DiscardDraft(ctx, &empty.Empty{})
+		if err != nil {
+			return err
+		}
+		return p.done("draft discarded")
+	}
+}
+
+// parses an RFC 3339 time, or a duration before now e.g 24h. An empty value is unset
+func parseTime(value string) (*timestamp.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		ago, durationErr := time.ParseDuration(value)
+		if durationErr != nil {
+			return nil, fmt.Errorf("invalid time %q, expected an RFC 3339 time or a duration e.g 24h", value)
+		}
+		t = time.Now().Add(-ago)
+	}
+	return ptypes.TimestampProto(t)
+}
+
+func changesCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	weekDay := fs.Int("day", 0, "Only list changes to this day of the week, 1 to 7")
+	screen := fs.String("screen", "", "Only list changes to this screen")
+	show := fs.Int("show", 0, "Only list changes to this show number")
+	actor := fs.String("actor", "", "Only list changes made by this user id")
+	from := fs.String("from", "", "Only list changes made since, as an RFC 3339 time or a duration before now e.g 24h")
+	to := fs.String("to", "", "Only list changes made until, as an RFC 3339 time or a duration before now")
+	pageSize := fs.Int("page-size", 0, "Number of changes to list, defaults to the service default")
+	pageToken := fs.String("page-token", "", "Token of the page to list, from a previous listing")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		fromTime, err := parseTime(*from)
+		if err != nil {
+			return err
+		}
+		toTime, err := parseTime(*to)
+		if err != nil {
+			return err
+		}
+		res, err := client.ListScheduleChanges(ctx, &scheduler.ListScheduleChangesRequest{
+			WeekDay:   int32(*weekDay),
+			Screen:    *screen,
+			Show:      int32(*show),
+			Actor:     *actor,
+			FromTime:  fromTime,
+			ToTime:    toTime,
+			PageSize:  int32(*pageSize),
+			PageToken: *pageToken,
+		})
+		if err != nil {
+			return err
+		}
+		return p.changes(res)
+	}
+}
+
+func diffCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	from := fs.String("from", "", "Time to compare from, as an RFC 3339 time or a duration before now e.g 24h")
+	to := fs.String("to", "", "Time to compare to, defaults to now")
+	weekDay := fs.Int("day", 0, "Only compare this day of the week, 1 to 7")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *from == "" {
+			return errors.New("-from is required")
+		}
+		fromTime, err := parseTime(*from)
+		if err != nil {
+			return err
+		}
+		toTime, err := parseTime(*to)
+		if err != nil {
+			return err
+		}
+		res, err := client.DiffSchedules(ctx, &scheduler.DiffSchedulesRequest{
+			FromTime: fromTime,
+			ToTime:   toTime,
+			WeekDay:  int32(*weekDay),
+		})
+		if err != nil {
+			return err
+		}
+		return p.differences(res, res.GetDifferences())
+	}
+}
+
+func undoCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	changeID := fs.Int64("change", 0, "Id of the change to revert")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *changeID <= 0 {
+			return errors.New("-change is required")
+		}
+		res, err := client.UndoChange(ctx, &scheduler.UndoChangeRequest{
+			ChangeId: *changeID,
+		})
+		if err != nil {
+			return err
+		}
+		return p.differences(res, res.GetDifferences())
+	}
+}
+
+func restoreCmd(fs *flag.FlagSet) func(context.Context, scheduler.ShowSchedulerClient, *printer) error {
+	at := fs.String("time", "", "Time to restore, as an RFC 3339 time or a duration before now e.g 2h")
+	weekDay := fs.Int("day", 0, "Only restore this day of the week, 1 to 7")
+
+	return func(ctx context.Context, client scheduler.ShowSchedulerClient, p *printer) error {
+		if *at == "" {
+			return errors.New("-time is required")
+		}
+		restoreTime, err := parseTime(*at)
+		if err != nil {
+			return err
+		}
+		res, err := client.RestoreSchedule(ctx, &scheduler.RestoreScheduleRequest{
+			RestoreTime: restoreTime,
+			WeekDay:     int32(*weekDay),
+		})
+		if err != nil {
+			return err
+		}
+		return p.differences(res, res.GetDifferences())
+	}
+}
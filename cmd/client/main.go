@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const usage = `Usage: client [options] <command> [command options]
+
+Manage the show schedule through the scheduling service gRPC API.
+
+Commands:
+  week           Show the schedule for the week, optionally filtered by days, screens and movie
+  day            Show the schedule for a day
+  show           Show a single show slot
+  create         Schedule a movie for a show
+  delete         Remove a scheduled movie from a show
+  update         Replace the movie showing in a show
+  move           Move a show with its voted movies to an empty show
+  swap           Swap the movies of two shows
+  add-voted      Nominate a movie for a show
+  remove-voted   Remove a nominated movie from a show, refunding or discarding its votes
+  reorder-voted  Reorder the nominated movies of a show
+  vote           Vote up a movie for a show
+  import         Import shows for the week from a CSV or JSON file
+  batch          Apply create, delete, add voted and move operations from a JSON file all together
+  export         Export shows for the week as CSV or JSON
+  find           Find the shows a movie is scheduled or nominated in
+  diff-draft     Show how the draft differs from the published schedule
+  publish-draft  Replace the published schedule with the draft
+  discard-draft  Discard the draft
+  changes        List changes made to the schedule, newest first
+  diff           Compare the schedule at two points in time
+  undo           Revert a change listed by changes
+  restore        Roll the schedule for a day or the week back to a point in time
+
+Commands that read or change the schedule take -draft to use the draft, which requires the programmer role.
+
+Run 'client <command> -h' for the options of a command.
+
+Options:
+`
+
+// options shared by every command
+type options struct {
+	address    string
+	caPath     string
+	certPath   string
+	keyPath    string
+	serverName string
+	insecure   bool
+	token      string
+	output     string
+	timeout    time.Duration
+	// idempotencyKey makes retries of a mutation with the same key apply it once
+	idempotencyKey string
+}
+
+func main() {
+	opts := &options{}
+
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	flag.StringVar(&opts.address, "address", "localhost:5600", "Address of the scheduling service")
+	flag.StringVar(&opts.caPath, "ca", "certs/cert.pem", "Path to CA bundle for verifying the scheduling service")
+	flag.StringVar(&opts.certPath, "cert", "", "Path to client certificate for mutual TLS")
+	flag.StringVar(&opts.keyPath, "key", "", "Path to client private key for mutual TLS")
+	flag.StringVar(&opts.serverName, "server-name", "", "Name the service certificate must be valid for, defaults to the address host")
+	flag.BoolVar(&opts.insecure, "insecure", false, "Connect without TLS, for services running in insecure mode")
+	flag.StringVar(&opts.token, "token", os.Getenv("SCHEDULER_TOKEN"), "Bearer token sent with requests, defaults to SCHEDULER_TOKEN")
+	flag.StringVar(&opts.output, "output", "table", "Output format: table or json")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "Timeout for each request")
+	flag.StringVar(&opts.idempotencyKey, "idempotency-key", "", "Key that makes a retried command apply only once")
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch opts.output {
+	case outputTable, outputJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", opts.output)
+		os.Exit(2)
+	}
+
+	err := run(opts, flag.Arg(0), cmd, flag.Args()[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options, name string, cmd command, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	exec := cmd(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, err := dial(opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	if opts.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "bearer "+opts.token)
+	}
+	if opts.idempotencyKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "idempotency-key", opts.idempotencyKey)
+	}
+
+	return exec(ctx, scheduler.NewShowSchedulerClient(conn), newPrinter(os.Stdout, opts.output))
+}
+
+// dials the scheduling service with TLS, or without in insecure mode
+func dial(opts *options) (*grpc.ClientConn, error) {
+	if opts.insecure {
+		return grpc.Dial(opts.address, grpc.WithInsecure())
+	}
+
+	tlsConfig, err := clientTLS(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.Dial(opts.address, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+}
+
+func clientTLS(opts *options) (*tls.Config, error) {
+	bs, err := ioutil.ReadFile(opts.caPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read CA bundle: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, errors.New("bad certs in CA bundle")
+	}
+
+	// gRPC checks the certificate against the address host if no server name is given
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		ServerName: opts.serverName,
+	}
+
+	switch {
+	case opts.certPath != "" && opts.keyPath != "":
+		cert, err := tls.LoadX509KeyPair(opts.certPath, opts.keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case opts.certPath != "" || opts.keyPath != "":
+		return nil, errors.New("both -cert and -key are required for mutual TLS")
+	}
+
+	return tlsConfig, nil
+}
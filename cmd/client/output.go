@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes responses as tables or JSON
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) *printer {
+	return &printer{w: w, format: format}
+}
+
+func (p *printer) json(msg proto.Message) error {
+	marshaler := &jsonpb.Marshaler{OrigName: true, EmitDefaults: true, Indent: "  "}
+	err := marshaler.Marshal(p.w, msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(p.w)
+	return err
+}
+
+func (p *printer) week(week *scheduler.DaysSchedule) error {
+	if p.format == outputJSON {
+		return p.json(week)
+	}
+
+	tw := newTable(p.w)
+	for _, weekDay := range sortedDays(week.DaysSchedule) {
+		writeDayRows(tw, weekDay, week.DaysSchedule[weekDay])
+	}
+	return tw.Flush()
+}
+
+func (p *printer) day(weekDay int32, daySchedule *scheduler.ScreensSchedule) error {
+	if p.format == outputJSON {
+		return p.json(daySchedule)
+	}
+
+	tw := newTable(p.w)
+	writeDayRows(tw, weekDay, daySchedule)
+	return tw.Flush()
+}
+
+func (p *printer) show(showSchedule *scheduler.ShowSchedule) error {
+	if p.format == outputJSON {
+		return p.json(showSchedule)
+	}
+
+	fmt.Fprintf(p.w, "Play time: %s\nVersion: %d\n\n", showSchedule.GetPlayTime(), showSchedule.GetVersion())
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tMOVIE ID\tTITLE\tVOTES")
+	writeMovieRow(tw, "showing", showSchedule.GetMovie())
+	for _, votedMovie := range showSchedule.GetVotedMovies() {
+		writeMovieRow(tw, "voted", votedMovie)
+	}
+	return tw.Flush()
+}
+
+func (p *printer) movie(movieItem *movie.Movie) error {
+	if p.format == outputJSON {
+		return p.json(movieItem)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tMOVIE ID\tTITLE\tVOTES")
+	writeMovieRow(tw, "showing", movieItem)
+	return tw.Flush()
+}
+
+func (p *printer) importResult(res *scheduler.ImportScheduleResponse) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	if len(res.GetErrors()) != 0 {
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ROW\tERROR")
+		for _, rowErr := range res.GetErrors() {
+			fmt.Fprintf(tw, "%d\t%s\n", rowErr.GetRow(), rowErr.GetMessage())
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(
+		p.w, "%d rows, %d errors, applied: %t\n", res.GetRows(), len(res.GetErrors()), res.GetApplied(),
+	)
+	return err
+}
+
+func (p *printer) batchResult(res *scheduler.BatchUpdateScheduleResponse) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	if len(res.GetErrors()) != 0 {
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "OPERATION\tCODE\tERROR")
+		for _, opErr := range res.GetErrors() {
+			fmt.Fprintf(
+				tw, "%d\t%s\t%s\n", opErr.GetOperation(), codes.Code(opErr.GetCode()), opErr.GetMessage(),
+			)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(p.w, "%d errors, applied: %t\n", len(res.GetErrors()), res.GetApplied())
+	return err
+}
+
+func (p *printer) removeResult(res *scheduler.RemoveVotedMovieResponse) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	if len(res.GetRefunds()) != 0 {
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "USER ID\tVOTES REFUNDED")
+		for _, refund := range res.GetRefunds() {
+			fmt.Fprintf(tw, "%s\t%d\n", refund.GetUserId(), refund.GetVotes())
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(
+		p.w, "%d refunds, %d votes discarded\n", len(res.GetRefunds()), res.GetDiscardedVotes(),
+	)
+	return err
+}
+
+func (p *printer) movieShows(res *scheduler.FindMovieShowsResponse) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tSCREEN\tSHOW\tTIME\tROLE\tVOTES\tRANK")
+	for _, movieShow := range res.GetShows() {
+		fmt.Fprintf(
+			tw, "%d\t%s\t%d\t%s\t%s\t%d\t%d\n",
+			movieShow.GetWeekDay(), movieShow.GetScreen(), movieShow.GetShow(), movieShow.GetPlayTime(),
+			strings.ToLower(movieShow.GetRole().String()), movieShow.GetVotes(), movieShow.GetRank(),
+		)
+	}
+	return tw.Flush()
+}
+
+// differences prints the differences carried by res
+func (p *printer) differences(res proto.Message, diffs []*scheduler.ScheduleDifference) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tSCREEN\tSHOW\tFIELD\tBEFORE\tAFTER")
+	for _, diff := range diffs {
+		fmt.Fprintf(
+			tw, "%d\t%s\t%d\t%s\t%s\t%s\n",
+			diff.GetWeekDay(), diff.GetScreen(), diff.GetShow(), diff.GetField(), diff.GetBefore(), diff.GetAfter(),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(p.w, "%d differences\n", len(diffs))
+	return err
+}
+
+func (p *printer) changes(res *scheduler.ListScheduleChangesResponse) error {
+	if p.format == outputJSON {
+		return p.json(res)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTIME\tACTOR\tMETHOD\tDAY\tSCREEN\tSHOW\tMOVIE BEFORE\tMOVIE AFTER\tVOTED MOVIES")
+	for _, change := range res.GetChanges() {
+		changeTime, _ := ptypes.Timestamp(change.GetChangeTime())
+		actor := change.GetActor()
+		if actor == "" {
+			actor = "-"
+		}
+		for _, showChange := range change.GetShows() {
+			fmt.Fprintf(
+				tw, "%d\t%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t%d -> %d\n",
+				change.GetId(), changeTime.Local().Format(time.RFC3339), actor, change.GetMethod(),
+				showChange.GetWeekDay(), showChange.GetScreen(), showChange.GetShow(),
+				movieName(showChange.GetBefore().GetMovie()), movieName(showChange.GetAfter().GetMovie()),
+				len(showChange.GetBefore().GetVotedMovies()), len(showChange.GetAfter().GetVotedMovies()),
+			)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if res.GetNextPageToken() != "" {
+		_, err := fmt.Fprintf(p.w, "more changes with -page-token %s\n", res.GetNextPageToken())
+		return err
+	}
+	return nil
+}
+
+func (p *printer) done(format string, args ...interface{}) error {
+	if p.format == outputJSON {
+		_, err := fmt.Fprintln(p.w, "{}")
+		return err
+	}
+	_, err := fmt.Fprintf(p.w, format+"\n", args...)
+	return err
+}
+
+func newTable(w io.Writer) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tSCREEN\tSHOW\tTIME\tMOVIE\tVOTES\tVOTED MOVIES")
+	return tw
+}
+
+func writeDayRows(tw io.Writer, weekDay int32, daySchedule *scheduler.ScreensSchedule) {
+	screens := make([]string, 0, len(daySchedule.GetScreensSchedule()))
+	for screen := range daySchedule.GetScreensSchedule() {
+		screens = append(screens, screen)
+	}
+	sort.Strings(screens)
+
+	for _, screen := range screens {
+		showsSchedule := daySchedule.GetScreensSchedule()[screen].GetShowsSchedule()
+		shows := make([]int32, 0, len(showsSchedule))
+		for show := range showsSchedule {
+			shows = append(shows, show)
+		}
+		sort.Slice(shows, func(i, j int) bool { return shows[i] < shows[j] })
+
+		for _, show := range shows {
+			showSchedule := showsSchedule[show]
+			fmt.Fprintf(
+				tw, "%d\t%s\t%d\t%s\t%s\t%d\t%d\n",
+				weekDay, screen, show, showSchedule.GetPlayTime(),
+				movieName(showSchedule.GetMovie()), showSchedule.GetMovie().GetCurrentVotes(),
+				len(showSchedule.GetVotedMovies()),
+			)
+		}
+	}
+}
+
+func writeMovieRow(tw io.Writer, label string, movieItem *movie.Movie) {
+	fmt.Fprintf(
+		tw, "%s\t%s\t%s\t%d\n",
+		label, movieItem.GetId(), movieItem.GetTitle(), movieItem.GetCurrentVotes(),
+	)
+}
+
+func movieName(movieItem *movie.Movie) string {
+	switch {
+	case movieItem.GetId() == "":
+		return "-"
+	case movieItem.GetTitle() == "":
+		return movieItem.GetId()
+	}
+	return movieItem.GetTitle()
+}
+
+func sortedDays(days map[int32]*scheduler.ScreensSchedule) []int32 {
+	weekDays := make([]int32, 0, len(days))
+	for weekDay := range days {
+		weekDays = append(weekDays, weekDay)
+	}
+	sort.Slice(weekDays, func(i, j int) bool { return weekDays[i] < weekDays[j] })
+	return weekDays
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+const usage = `Usage: snapshot <command> [options] <file>...
+
+Inspect and repair snapshots of the weekly schedule written by the scheduling service.
+Snapshots may be in binary or JSON form; the form is detected when reading.
+
+Commands:
+  dump [-output table|json] <file>            Print a snapshot
+  diff <before> <after>                       Print differences between two snapshots, exits 1 if any
+  validate [-config file] <file>              Check a snapshot against the service configuration, exits 1 on violations
+  convert -to json|binary <in> <out>          Convert a snapshot between binary and JSON form
+`
+
+// errFailed signals a failed check that has already been reported
+var errFailed = errors.New("check failed")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "dump":
+		err = dump(args)
+	case "diff":
+		err = diff(args)
+	case "validate":
+		err = validate(args)
+	case "convert":
+		err = convert(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	switch err {
+	case nil:
+	case flag.ErrHelp:
+	case errFailed:
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func dump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	output := fs.String("output", "table", "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected one snapshot file")
+	}
+
+	weeklySchedule, err := snapshot.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "json":
+		bs, err := snapshot.MarshalJSON(weeklySchedule)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(bs)
+		return err
+	case "table":
+		return writeTable(weeklySchedule)
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+}
+
+func writeTable(weeklySchedule *scheduler.DaysSchedule) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tSCREEN\tSHOW\tTIME\tMOVIE\tVOTES\tVOTED MOVIES")
+	for _, slot := range snapshot.Slots(weeklySchedule) {
+		showSchedule := snapshot.Lookup(weeklySchedule, slot)
+
+		movieID := showSchedule.GetMovie().GetId()
+		if movieID == "" {
+			movieID = "-"
+		}
+
+		votedMovies := make([]string, 0, len(showSchedule.GetVotedMovies()))
+		for _, votedMovie := range showSchedule.GetVotedMovies() {
+			votedMovies = append(votedMovies, fmt.Sprintf("%s(%d)", votedMovie.GetId(), votedMovie.GetCurrentVotes()))
+		}
+		sort.Strings(votedMovies)
+
+		fmt.Fprintf(
+			tw, "%d\t%s\t%d\t%s\t%s\t%d\t%v\n",
+			slot.WeekDay, slot.Screen, slot.Show, showSchedule.GetPlayTime(),
+			movieID, showSchedule.GetMovie().GetCurrentVotes(), votedMovies,
+		)
+	}
+	return tw.Flush()
+}
+
+func diff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("expected two snapshot files")
+	}
+
+	before, err := snapshot.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	after, err := snapshot.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diffs := snapshot.Diff(before, after)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) != 0 {
+		return errFailed
+	}
+	return nil
+}
+
+func validate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFile := fs.String("config", "", "Service config file with screens, showtimes and max_movies_voted; environment variables apply too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected one snapshot file")
+	}
+
+	// Use the same configuration the service would run with
+	loadArgs := []string{}
+	if *configFile != "" {
+		loadArgs = append(loadArgs, "-config", *configFile)
+	}
+	cfg, err := config.Load(loadArgs)
+	if err != nil {
+		return err
+	}
+
+	weeklySchedule, err := snapshot.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	layout := snapshot.Layout{
+		Screens:        cfg.Screens,
+		MaxMoviesVoted: cfg.MaxMoviesVoted,
+	}
+	for _, showtime := range cfg.Showtimes {
+		layout.Shows = append(layout.Shows, snapshot.Show{ID: showtime.ID, PlayTime: showtime.PlayTime})
+	}
+
+	violations := snapshot.Validate(weeklySchedule, layout)
+	for _, violation := range violations {
+		fmt.Println(violation)
+	}
+	if len(violations) != 0 {
+		return errFailed
+	}
+
+	fmt.Println("snapshot is valid")
+	return nil
+}
+
+func convert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "json", "Form to convert to: json or binary")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("expected input and output files")
+	}
+
+	var asJSON bool
+	switch *to {
+	case "json":
+		asJSON = true
+	case "binary":
+	default:
+		return fmt.Errorf("unknown form %q", *to)
+	}
+
+	weeklySchedule, err := snapshot.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return snapshot.WriteFile(fs.Arg(1), weeklySchedule, asJSON)
+}
@@ -0,0 +1,448 @@
+// Package audit writes a record of every authenticated mutation to an append-only JSON lines file.
+// Each record carries the hash of the record before it, so that records changed, removed or
+// reordered after they were written are detected by Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// forwardedForHeader is the metadata key the REST gateway sets to the address of the HTTP client
+	forwardedForHeader = "x-forwarded-for"
+	// rotatedTimeFormat is the suffix added to rotated logs. Rotated logs sort in the order they were written
+	rotatedTimeFormat = "20060102T150405.000000000Z"
+)
+
+// Record is an audited request. A request is written twice: before it is served, without a code,
+// and once it has been served, with the code and the sequence number of the first record as IntentSeq
+type Record struct {
+	Seq       int64           `json:"seq"`
+	Time      time.Time       `json:"time"`
+	UserID    string          `json:"user_id"`
+	Roles     []string        `json:"roles,omitempty"`
+	Method    string          `json:"method"`
+	Request   json.RawMessage `json:"request"`
+	IntentSeq int64           `json:"intent_seq,omitempty"`
+	Code      string          `json:"code,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	ClientIP  string          `json:"client_ip,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash,omitempty"`
+}
+
+// hash returns the hash of the record without its own hash
+func (rec Record) hash() (string, error) {
+	rec.Hash = ""
+	bs, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an append-only audit log file that is rotated once it reaches a maximum size.
+// Rotated files are renamed with the time of rotation. The oldest rotated files are removed
+// once there are more than the maximum number of files; they must be archived before if they are to be kept
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+	seq      int64
+	lastHash string
+}
+
+// Open opens the audit log at path, continuing the hash chain of the records already written.
+// A last record torn by a crash while it was written is removed.
+// The log is rotated when writing a record would make it larger than maxSize bytes,
+// keeping at most maxFiles rotated logs, or every rotated log if maxFiles is 0
+func Open(path string, maxSize int64, maxFiles int) (*Log, error) {
+	auditLog := &Log{path: path, maxSize: maxSize, maxFiles: maxFiles}
+
+	// The chain continues from the last record of the log, or of the last rotated log if it is empty
+	files, err := Files(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		last, err := lastRecord(files[i])
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			auditLog.seq, auditLog.lastHash = last.Seq, last.Hash
+			break
+		}
+	}
+
+	err = auditLog.open()
+	if err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// opens the log file for appending
+func (auditLog *Log) open() error {
+	file, err := os.OpenFile(auditLog.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	auditLog.file, auditLog.size = file, info.Size()
+	return nil
+}
+
+// rotate renames the log file with the current time, starts a new one and removes the rotated files
+// beyond the maximum number of files
+func (auditLog *Log) rotate() error {
+	err := auditLog.file.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(auditLog.path, auditLog.path+"."+time.Now().UTC().Format(rotatedTimeFormat))
+	if err != nil {
+		return err
+	}
+	err = auditLog.open()
+	if err != nil {
+		return err
+	}
+	return auditLog.removeRotated()
+}
+
+// removes the oldest rotated files beyond the maximum number of files
+func (auditLog *Log) removeRotated() error {
+	if auditLog.maxFiles <= 0 {
+		return nil
+	}
+	files, err := Files(auditLog.path)
+	if err != nil {
+		return err
+	}
+	rotated := files[:len(files)-1]
+	for len(rotated) > auditLog.maxFiles {
+		err = os.Remove(rotated[0])
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// Write appends a record to the log, setting its sequence number and hashes
+func (auditLog *Log) Write(rec *Record) error {
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+
+	rec.Seq, rec.PrevHash = auditLog.seq+1, auditLog.lastHash
+	hash, err := rec.hash()
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if auditLog.size > 0 && auditLog.size+int64(len(line)) > auditLog.maxSize {
+		err = auditLog.rotate()
+		if err != nil {
+			return fmt.Errorf("failed to rotate audit log: %v", err)
+		}
+	}
+
+	n, err := auditLog.file.Write(line)
+	if err != nil {
+		// Remove what was written of the record so that the next one starts on its own line
+		if n > 0 && auditLog.file.Truncate(auditLog.size) != nil {
+			auditLog.size += int64(n)
+		}
+		return err
+	}
+	auditLog.size += int64(n)
+	// Records must survive a crash of the service
+	err = auditLog.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	auditLog.seq, auditLog.lastHash = rec.Seq, rec.Hash
+	return nil
+}
+
+// Close closes the log file
+func (auditLog *Log) Close() error {
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+	return auditLog.file.Close()
+}
+
+// String describes where the log is written
+func (auditLog *Log) String() string {
+	return "file " + auditLog.path
+}
+
+// Files returns the files of the log at path in the order they were written: the rotated files, oldest first,
+// followed by the log itself
+func Files(path string) ([]string, error) {
+	files, err := filepath.Glob(path + ".[0-9]*Z")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return append(files, path), nil
+}
+
+// returns the last record in a file, or nil if the file is empty or does not exist.
+// A last line without a newline was torn by a crash while it was written and is truncated
+func lastRecord(path string) (*Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		last   []byte
+		offset int64
+	)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) != 0 {
+				err = os.Truncate(path, offset)
+				if err != nil {
+					return nil, fmt.Errorf("failed to truncate torn record of audit log %s: %v", path, err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			last = line
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	rec := &Record{}
+	err = json.Unmarshal(last, rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last record of audit log %s: %v", path, err)
+	}
+	return rec, nil
+}
+
+// Verify checks the hash chain of the records read from r, which must follow the record with hash prevHash.
+// It returns the hash of the last record and the number of records read
+func Verify(r io.Reader, prevHash string) (string, int, error) {
+	reader := bufio.NewReader(r)
+	n := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			n++
+			rec := Record{}
+			jsonErr := json.Unmarshal(line, &rec)
+			if jsonErr != nil {
+				return prevHash, n, fmt.Errorf("record %d: %v", n, jsonErr)
+			}
+			if rec.PrevHash != prevHash {
+				return prevHash, n, fmt.Errorf("record %d (seq %d): does not follow the record before it", n, rec.Seq)
+			}
+			hash, hashErr := rec.hash()
+			if hashErr != nil {
+				return prevHash, n, fmt.Errorf("record %d (seq %d): %v", n, rec.Seq, hashErr)
+			}
+			if hash != rec.Hash {
+				return prevHash, n, fmt.Errorf("record %d (seq %d): hash does not match its contents", n, rec.Seq)
+			}
+			prevHash = rec.Hash
+		}
+		if err == io.EOF {
+			return prevHash, n, nil
+		}
+		if err != nil {
+			return prevHash, n, err
+		}
+	}
+}
+
+// ParseTrustedProxies parses the addresses or CIDR ranges of proxies trusted to forward the address of clients
+func ParseTrustedProxies(addrs []string) ([]*net.IPNet, error) {
+	proxies := make([]*net.IPNet, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q is not an address or CIDR range", addr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is not an address or CIDR range", addr)
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies, nil
+}
+
+// UnaryServerInterceptor writes a record of every authenticated request to the given methods
+// before it is served and another with its outcome once it has been served.
+// It must run after the authentication interceptor so that the caller is known, and after
+// the idempotency interceptor so that replayed results are not audited as new requests.
+// Requests whose first record cannot be written fail with codes.Internal without being served.
+// The client address forwarded by trustedProxies, such as the REST gateway, is recorded instead of theirs.
+// onError is called with the error when the outcome of a served request cannot be written
+func UnaryServerInterceptor(
+	auditLog *Log, trustedProxies []*net.IPNet, onError func(error), methods ...string,
+) grpc.UnaryServerInterceptor {
+	audited := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		audited[method] = true
+	}
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		claims, ok := auth.FromContext(ctx)
+		if !audited[info.FullMethod] || !ok {
+			return handler(ctx, req)
+		}
+
+		rec := &Record{
+			Time:     time.Now().UTC(),
+			UserID:   claims.UserID,
+			Roles:    claims.Roles,
+			Method:   info.FullMethod,
+			Request:  json.RawMessage("null"),
+			ClientIP: clientIP(ctx, trustedProxies),
+		}
+		if msg, ok := req.(proto.Message); ok {
+			payload, err := marshaler.MarshalToString(msg)
+			if err == nil {
+				rec.Request = json.RawMessage(payload)
+			}
+		}
+
+		// Requests are only served once there is a record of them
+		intent := *rec
+		if err := auditLog.Write(&intent); err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return nil, status.Error(codes.Internal, "failed to write audit record")
+		}
+
+		resp, err := handler(ctx, req)
+
+		st := status.Convert(err)
+		rec.Time, rec.IntentSeq = time.Now().UTC(), intent.Seq
+		rec.Code, rec.Message = st.Code().String(), st.Message()
+		if writeErr := auditLog.Write(rec); writeErr != nil && onError != nil {
+			onError(writeErr)
+		}
+
+		return resp, err
+	}
+}
+
+// returns the address of the client. Requests from trusted proxies carry the address of the client
+// in the forwarded addresses, each proxy appending the address it was reached from. The client is the last
+// forwarded address that is not a trusted proxy
+func clientIP(ctx context.Context, trustedProxies []*net.IPNet) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if !trusted(host, trustedProxies) {
+		return host
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	forwarded := md.Get(forwardedForHeader)
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addrs := strings.Split(forwarded[i], ",")
+		for j := len(addrs) - 1; j >= 0; j-- {
+			addr := strings.TrimSpace(addrs[j])
+			if addr == "" {
+				continue
+			}
+			host = addr
+			if !trusted(addr, trustedProxies) {
+				return addr
+			}
+		}
+	}
+	return host
+}
+
+// reports whether addr is the address of a trusted proxy
+func trusted(addr string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,411 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// writes records for the methods to a new log and returns its lines
+func writeLog(t *testing.T, methods ...string) [][]byte {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	auditLog, err := Open(path, 1<<20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range methods {
+		err = auditLog.Write(&Record{UserID: "u1", Method: method, Request: json.RawMessage("{}"), Code: "OK"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	auditLog.Close()
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.SplitAfter(bs, []byte("\n"))
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(lines [][]byte) [][]byte
+		prevHash string
+		wantN    int
+		wantErr  string
+	}{
+		{
+			name:  "intact",
+			wantN: 3,
+		},
+		{
+			name: "record changed",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(`"user_id":"u1"`), []byte(`"user_id":"u2"`), 1)
+				return lines
+			},
+			wantN:   2,
+			wantErr: "hash does not match",
+		},
+		{
+			name: "record removed",
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantN:   2,
+			wantErr: "does not follow",
+		},
+		{
+			name: "records reordered",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			wantN:   1,
+			wantErr: "does not follow",
+		},
+		{
+			name: "record that is not JSON",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[2] = []byte("not a record\n")
+				return lines
+			},
+			wantN:   3,
+			wantErr: "record 3",
+		},
+		{
+			name:     "unexpected previous hash",
+			prevHash: "abc",
+			wantN:    1,
+			wantErr:  "does not follow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := writeLog(t, "/a", "/b", "/c")
+			if tt.tamper != nil {
+				lines = tt.tamper(lines)
+			}
+
+			_, n, err := Verify(bytes.NewReader(bytes.Join(lines, nil)), tt.prevHash)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Verify() failed: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("Verify() error = %v, want error containing %q", err, tt.wantErr)
+			}
+			if n != tt.wantN {
+				t.Errorf("Verify() read %d records, want %d", n, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestOpenTornRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		tail  string
+		wantN int
+	}{
+		{name: "no torn record", tail: "", wantN: 3},
+		{name: "torn record", tail: `{"seq":3,"time":"20`, wantN: 3},
+		{name: "record without newline", tail: `{"seq":3}`, wantN: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "audit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "audit.log")
+
+			lines := writeLog(t, "/a", "/b")
+			err = ioutil.WriteFile(path, append(bytes.Join(lines, nil), tt.tail...), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			auditLog, err := Open(path, 1<<20, 0)
+			if err != nil {
+				t.Fatalf("Open() failed: %v", err)
+			}
+			err = auditLog.Write(&Record{UserID: "u1", Method: "/c", Request: json.RawMessage("{}"), Code: "OK"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			auditLog.Close()
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			_, n, err := Verify(file, "")
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if n != tt.wantN {
+				t.Errorf("Verify() read %d records, want %d", n, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		claims      *auth.Claims
+		broken      bool // whether records cannot be written
+		wantCode    codes.Code
+		wantServed  bool
+		wantRecords int
+	}{
+		{
+			name: "audited", method: "/m", claims: &auth.Claims{UserID: "u1"},
+			wantServed: true, wantRecords: 2,
+		},
+		{
+			name: "other method", method: "/other", claims: &auth.Claims{UserID: "u1"},
+			wantServed: true,
+		},
+		{
+			name: "unauthenticated", method: "/m",
+			wantServed: true,
+		},
+		{
+			name: "record cannot be written", method: "/m", claims: &auth.Claims{UserID: "u1"}, broken: true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "audit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "audit.log")
+
+			auditLog, err := Open(path, 1<<20, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer auditLog.Close()
+			if tt.broken {
+				auditLog.file.Close()
+			}
+
+			var writeErr error
+			interceptor := UnaryServerInterceptor(auditLog, nil, func(err error) { writeErr = err }, "/m")
+
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = auth.NewContext(ctx, tt.claims)
+			}
+			served := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				served = true
+				return nil, nil
+			}
+
+			_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("interceptor error = %v, want code %s", err, tt.wantCode)
+			}
+			if served != tt.wantServed {
+				t.Errorf("served = %t, want %t", served, tt.wantServed)
+			}
+			if tt.broken && writeErr == nil {
+				t.Errorf("write error was not reported")
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			_, n, err := Verify(file, "")
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if n != tt.wantRecords {
+				t.Errorf("%d records written, want %d", n, tt.wantRecords)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxFiles  int
+		wantFiles int // rotated files kept
+	}{
+		{name: "keep every rotated log", maxFiles: 0, wantFiles: 4},
+		{name: "keep the newest rotated logs", maxFiles: 2, wantFiles: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "audit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "audit.log")
+
+			// Every record is larger than the maximum size, so each is written to its own file
+			auditLog, err := Open(path, 1, tt.maxFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 5; i++ {
+				err = auditLog.Write(&Record{UserID: "u1", Method: "/m", Request: json.RawMessage("{}")})
+				if err != nil {
+					t.Fatalf("Write() failed: %v", err)
+				}
+				// Rotated logs are named by time
+				time.Sleep(time.Millisecond)
+			}
+			auditLog.Close()
+
+			files, err := Files(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files)-1 != tt.wantFiles {
+				t.Fatalf("%d rotated logs kept, want %d", len(files)-1, tt.wantFiles)
+			}
+
+			// The kept logs continue the chain of the removed ones
+			lastHash, total := "", 0
+			for i, name := range files {
+				bs, err := ioutil.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i == 0 {
+					rec := Record{}
+					if err = json.Unmarshal(bytes.SplitN(bs, []byte("\n"), 2)[0], &rec); err != nil {
+						t.Fatal(err)
+					}
+					if wantSeq := int64(5 - tt.wantFiles); rec.Seq != wantSeq {
+						t.Errorf("oldest kept record seq = %d, want %d", rec.Seq, wantSeq)
+					}
+					lastHash = rec.PrevHash
+				}
+				var n int
+				lastHash, n, err = Verify(bytes.NewReader(bs), lastHash)
+				if err != nil {
+					t.Fatalf("Verify(%s) failed: %v", name, err)
+				}
+				total += n
+			}
+			if total != tt.wantFiles+1 {
+				t.Errorf("%d records kept, want %d", total, tt.wantFiles+1)
+			}
+
+			// Reopening continues the chain
+			auditLog, err = Open(path, 1, tt.maxFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer auditLog.Close()
+			if auditLog.seq != 5 || auditLog.lastHash != lastHash {
+				t.Errorf("reopened log continues from seq %d, want 5", auditLog.seq)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"127.0.0.0/8", "::1", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded []string
+		want      string
+	}{
+		{name: "direct client", peer: "192.0.2.1:4000", want: "192.0.2.1"},
+		{
+			name: "forwarded address from an untrusted peer", peer: "192.0.2.1:4000",
+			forwarded: []string{"198.51.100.7"}, want: "192.0.2.1",
+		},
+		{
+			name: "gateway over loopback", peer: "127.0.0.1:4000",
+			forwarded: []string{"198.51.100.7"}, want: "198.51.100.7",
+		},
+		{
+			name: "gateway over IPv6 loopback", peer: "[::1]:4000",
+			forwarded: []string{"198.51.100.7"}, want: "198.51.100.7",
+		},
+		{
+			name: "gateway behind a trusted load balancer", peer: "10.0.0.5:4000",
+			forwarded: []string{"203.0.113.9, 198.51.100.7, 10.0.0.2"}, want: "198.51.100.7",
+		},
+		{
+			name: "forwarded addresses in separate headers", peer: "127.0.0.1:4000",
+			forwarded: []string{"198.51.100.7", "10.0.0.2"}, want: "198.51.100.7",
+		},
+		{name: "trusted peer without forwarded address", peer: "127.0.0.1:4000", want: "127.0.0.1"},
+		{
+			name: "only trusted forwarded addresses", peer: "127.0.0.1:4000",
+			forwarded: []string{"10.0.0.3, 10.0.0.2"}, want: "10.0.0.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			md := metadata.MD{}
+			for _, forwarded := range tt.forwarded {
+				md.Append(forwardedForHeader, forwarded)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			if got := clientIP(ctx, trustedProxies); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err = ParseTrustedProxies([]string{"gateway"}); err == nil {
+		t.Error("ParseTrustedProxies() accepted a host name")
+	}
+}
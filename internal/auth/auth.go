@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gidyon/rupacinema/account/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+)
+
+const (
+	// ModeRemote authenticates every request against the account service, and identifies the caller
+	// from the claims of the token it accepted
+	ModeRemote = "remote"
+	// ModeLocal verifies JWT tokens locally using a public key or JWKS file
+	ModeLocal = "local"
+)
+
+const (
+	// RoleProgrammer is the role of users that edit the schedule and its draft, and publish the draft
+	RoleProgrammer = "programmer"
+	// RoleAdmin is the role of users that restore the schedule to an earlier time
+	RoleAdmin = "admin"
+)
+
+// Claims contains identity of the caller extracted from a verified token
+type Claims struct {
+	UserID string
+	Roles  []string
+}
+
+// HasRole checks whether the claims contain a given role
+func (claims *Claims) HasRole(role string) bool {
+	if claims == nil {
+		return false
+	}
+	for _, r := range claims.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator authenticates an incoming request and returns the caller claims
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Claims, error)
+}
+
+type claimsKey struct{}
+
+// NewContext returns a copy of ctx carrying the caller claims
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the caller claims stored in ctx by the authentication interceptor
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+type errorKey struct{}
+
+// NewErrorContext returns a copy of ctx carrying the error that failed authenticating
+// the caller of a method that may be called anonymously
+func NewErrorContext(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// ErrorFromContext returns the error stored in ctx by NewErrorContext, if any
+func ErrorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
+
+// NewAuthenticator creates an authenticator for the mode set in config
+func NewAuthenticator(
+	cfg *config.Config, accountServiceClient account.AccountAPIClient,
+) (Authenticator, error) {
+	switch strings.ToLower(strings.Trim(cfg.AuthMode, " ")) {
+	case ModeLocal:
+		return newLocalAuthenticator(cfg.JWTPublicKeyPath, cfg.JWKSPath)
+	case ModeRemote, "":
+		return newRemoteAuthenticator(accountServiceClient), nil
+	default:
+		return nil, fmt.Errorf("unknown authentication mode: %q", cfg.AuthMode)
+	}
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tokenClaims are the claims expected in tokens issued by the account service
+type tokenClaims struct {
+	UserID string   `json:"user_id,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	jwt.StandardClaims
+}
+
+func (tc *tokenClaims) toClaims() *Claims {
+	userID := tc.UserID
+	if userID == "" {
+		userID = tc.Subject
+	}
+	return &Claims{
+		UserID: userID,
+		Roles:  tc.Roles,
+	}
+}
+
+type localAuthenticator struct {
+	// keys by key id, the empty key id is used when token has no kid header
+	keys map[string]interface{}
+}
+
+func newLocalAuthenticator(publicKeyPath, jwksPath string) (*localAuthenticator, error) {
+	var (
+		keys map[string]interface{}
+		err  error
+	)
+	switch {
+	case strings.Trim(jwksPath, " ") != "":
+		keys, err = readJWKS(jwksPath)
+	case strings.Trim(publicKeyPath, " ") != "":
+		keys, err = readPublicKey(publicKeyPath)
+	default:
+		err = errors.New("local authentication requires a public key or JWKS file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &localAuthenticator{keys: keys}, nil
+}
+
+func (localAuth *localAuthenticator) Authenticate(ctx context.Context) (*Claims, error) {
+	tokenString, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return nil, err
+	}
+
+	return localAuth.verify(tokenString)
+}
+
+// verifies the signature and expiry of a token and returns its claims
+func (localAuth *localAuthenticator) verify(tokenString string) (*Claims, error) {
+	tc := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, tc, localAuth.keyFunc)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	// Tokens without an expiry would be valid forever
+	if tc.ExpiresAt == 0 {
+		return nil, status.Error(codes.Unauthenticated, "invalid token: token has no expiry")
+	}
+
+	return tc.toClaims(), nil
+}
+
+// keyFunc selects the verification key for a token and ensures the signing algorithm matches it
+func (localAuth *localAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := localAuth.keys[kid]
+	if !ok {
+		// A single configured key verifies tokens regardless of kid
+		if len(localAuth.keys) != 1 {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		for _, k := range localAuth.keys {
+			key = k
+		}
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Header["alg"])
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Header["alg"])
+		}
+	}
+
+	return key, nil
+}
+
+// reads a PEM encoded RSA or ECDSA public key
+func readPublicKey(path string) (map[string]interface{}, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file: %s", err)
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(bs); err == nil {
+		return map[string]interface{}{"": key}, nil
+	}
+
+	key, err := jwt.ParseECPublicKeyFromPEM(bs)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse public key %s: %v", path, err)
+	}
+
+	return map[string]interface{}{"": key}, nil
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// reads RSA and EC signing keys from a JSON Web Key Set file
+func readJWKS(path string) (map[string]interface{}, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file: %s", err)
+	}
+
+	jwks := struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}{}
+	err = json.Unmarshal(bs, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse JWKS %s: %v", path, err)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("bad key %q in JWKS %s: %v", jwk.Kid, path, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys in JWKS %s", path)
+	}
+
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URLInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBase64URLInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URLInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bs), nil
+}
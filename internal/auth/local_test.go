@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testKeys are signing keys and the files their public keys are written to
+type testKeys struct {
+	rsaKey, otherRSAKey *rsa.PrivateKey
+	ecKey               *ecdsa.PrivateKey
+	rsaPEM, ecPEM, jwks string // paths
+	rsaPEMBytes         []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	keys := &testKeys{
+		rsaPEM: filepath.Join(dir, "rsa.pem"),
+		ecPEM:  filepath.Join(dir, "ec.pem"),
+		jwks:   filepath.Join(dir, "jwks.json"),
+	}
+	keys.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.otherRSAKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.rsaPEMBytes = writePublicKey(t, keys.rsaPEM, &keys.rsaKey.PublicKey)
+	writePublicKey(t, keys.ecPEM, &keys.ecKey.PublicKey)
+
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	jwks := map[string][]map[string]string{
+		"keys": {
+			{
+				"kid": "r1", "kty": "RSA", "use": "sig",
+				"n": encode(keys.rsaKey.N), "e": encode(big.NewInt(int64(keys.rsaKey.E))),
+			},
+			{
+				"kid": "e1", "kty": "EC", "crv": "P-256",
+				"x": encode(keys.ecKey.X), "y": encode(keys.ecKey.Y),
+			},
+			{
+				"kid": "enc", "kty": "RSA", "use": "enc",
+				"n": encode(keys.otherRSAKey.N), "e": encode(big.NewInt(int64(keys.otherRSAKey.E))),
+			},
+		},
+	}
+	bs, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keys.jwks, bs, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func writePublicKey(t *testing.T, path string, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	err = ioutil.WriteFile(path, bs, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bs
+}
+
+// signs a token for u1 with the programmer role that expires after expiresIn
+func signToken(
+	t *testing.T, method jwt.SigningMethod, key interface{}, kid string, expiresIn time.Duration,
+) string {
+	t.Helper()
+	tc := &tokenClaims{
+		UserID:         "u1",
+		Roles:          []string{RoleProgrammer},
+		StandardClaims: jwt.StandardClaims{Subject: "s1"},
+	}
+	// Tokens expiring in 0 have no expiry
+	if expiresIn != 0 {
+		tc.ExpiresAt = time.Now().Add(expiresIn).Unix()
+	}
+	token := jwt.NewWithClaims(method, tc)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestLocalAuthenticate(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name      string
+		publicKey string
+		jwks      string
+		token     string
+		wantCode  codes.Code
+	}{
+		{
+			name:      "RSA public key",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "", time.Hour),
+		},
+		{
+			name:      "EC public key",
+			publicKey: keys.ecPEM,
+			token:     signToken(t, jwt.SigningMethodES256, keys.ecKey, "", time.Hour),
+		},
+		{
+			name:      "single key ignores the key id",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "other", time.Hour),
+		},
+		{
+			name:  "JWKS RSA key",
+			jwks:  keys.jwks,
+			token: signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "r1", time.Hour),
+		},
+		{
+			name:  "JWKS EC key",
+			jwks:  keys.jwks,
+			token: signToken(t, jwt.SigningMethodES256, keys.ecKey, "e1", time.Hour),
+		},
+		{
+			name:     "JWKS unknown key id",
+			jwks:     keys.jwks,
+			token:    signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "r2", time.Hour),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "JWKS key not for signing",
+			jwks:     keys.jwks,
+			token:    signToken(t, jwt.SigningMethodRS256, keys.otherRSAKey, "enc", time.Hour),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:      "expired token",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "", -time.Minute),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:      "no expiry",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "", 0),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:      "signed by another key",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodRS256, keys.otherRSAKey, "", time.Hour),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:      "HMAC with the public key as secret",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodHS256, keys.rsaPEMBytes, "", time.Hour),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:     "EC algorithm for an RSA key",
+			jwks:     keys.jwks,
+			token:    signToken(t, jwt.SigningMethodES256, keys.ecKey, "r1", time.Hour),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:      "unsigned token",
+			publicKey: keys.rsaPEM,
+			token:     signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", time.Hour),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:      "not a token",
+			publicKey: keys.rsaPEM,
+			token:     "opaque",
+			wantCode:  codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localAuth, err := newLocalAuthenticator(tt.publicKey, tt.jwks)
+			if err != nil {
+				t.Fatalf("newLocalAuthenticator() failed: %v", err)
+			}
+
+			claims, err := localAuth.Authenticate(bearerContext(tt.token))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Authenticate() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if claims.UserID != "u1" || !claims.HasRole(RoleProgrammer) {
+				t.Errorf("claims = %+v, want user u1 with the programmer role", claims)
+			}
+		})
+	}
+}
+
+func TestNewLocalAuthenticator(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name      string
+		publicKey string
+		jwks      string
+		wantErr   bool
+	}{
+		{name: "public key", publicKey: keys.rsaPEM},
+		{name: "JWKS", jwks: keys.jwks},
+		{name: "JWKS is used before the public key", publicKey: "missing.pem", jwks: keys.jwks},
+		{name: "no keys", wantErr: true},
+		{name: "missing public key", publicKey: "missing.pem", wantErr: true},
+		{name: "public key that is not PEM", publicKey: keys.jwks, wantErr: true},
+		{name: "JWKS that is not JSON", jwks: keys.rsaPEM, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newLocalAuthenticator(tt.publicKey, tt.jwks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newLocalAuthenticator() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutToken(t *testing.T) {
+	keys := newTestKeys(t)
+	localAuth, err := newLocalAuthenticator(keys.rsaPEM, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = localAuth.Authenticate(context.Background())
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Authenticate() error = %v, want code %s", err, codes.Unauthenticated)
+	}
+}
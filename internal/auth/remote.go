@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gidyon/rupacinema/account/pkg/api"
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type remoteAuthenticator struct {
+	accountServiceClient account.AccountAPIClient
+}
+
+func newRemoteAuthenticator(accountServiceClient account.AccountAPIClient) *remoteAuthenticator {
+	return &remoteAuthenticator{accountServiceClient: accountServiceClient}
+}
+
+// Authenticate asks the account service to verify the token in the request.
+// AuthenticateRequest answers with an empty message, so it only accepts or rejects the token.
+// The caller is identified by the claims of the token the account service accepted, which it issued as a JWT;
+// the signature is not verified again locally.
+func (remoteAuth *remoteAuthenticator) Authenticate(ctx context.Context) (*Claims, error) {
+	tokenString, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return nil, err
+	}
+
+	// Forward the token to the account service
+	outCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tokenString)
+
+	_, err = remoteAuth.accountServiceClient.AuthenticateRequest(outCtx, &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &tokenClaims{}
+	_, _, err = new(jwt.Parser).ParseUnverified(tokenString, tc)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "token accepted by account service has no claims: %v", err)
+	}
+
+	return tc.toClaims(), nil
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/account/pkg/api"
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAccountClient answers AuthenticateRequest with an empty message, like the account service, or an error
+type fakeAccountClient struct {
+	account.AccountAPIClient
+	err   error
+	token string // token forwarded by the last request
+}
+
+func (client *fakeAccountClient) AuthenticateRequest(
+	ctx context.Context, in *empty.Empty, opts ...grpc.CallOption,
+) (*empty.Empty, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 0 {
+		client.token = values[0]
+	}
+	if client.err != nil {
+		return nil, client.err
+	}
+	return &empty.Empty{}, nil
+}
+
+func TestRemoteAuthenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	signed := signToken(t, jwt.SigningMethodRS256, keys.rsaKey, "", time.Hour)
+
+	tests := []struct {
+		name      string
+		client    *fakeAccountClient
+		token     string
+		wantCode  codes.Code
+		wantUser  string
+		wantRoles []string
+	}{
+		{
+			name:      "accepted token",
+			client:    &fakeAccountClient{},
+			token:     signed,
+			wantUser:  "u1",
+			wantRoles: []string{RoleProgrammer},
+		},
+		{
+			name:     "accepted opaque token without identity",
+			client:   &fakeAccountClient{},
+			token:    "opaque",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "account service rejects the token",
+			client:   &fakeAccountClient{err: status.Error(codes.PermissionDenied, "revoked")},
+			token:    signed,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remoteAuth := newRemoteAuthenticator(tt.client)
+
+			claims, err := remoteAuth.Authenticate(bearerContext(tt.token))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Authenticate() error = %v, want code %s", err, tt.wantCode)
+			}
+			if tt.client.token != "Bearer "+tt.token {
+				t.Errorf("forwarded token = %q, want %q", tt.client.token, "Bearer "+tt.token)
+			}
+			if err != nil {
+				return
+			}
+			if claims.UserID != tt.wantUser {
+				t.Errorf("user id = %q, want %q", claims.UserID, tt.wantUser)
+			}
+			if !equalRoles(claims.Roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", claims.Roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func equalRoles(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
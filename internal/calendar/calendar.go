@@ -0,0 +1,236 @@
+// Package calendar renders the weekly schedule as an RFC 5545 iCalendar feed
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+const (
+	prodID = "-//rupacinema//scheduling//EN"
+	// domain part of event UIDs
+	uidDomain = "scheduling.rupacinema"
+	// length of shows whose movie has no duration
+	defaultDuration = 2 * time.Hour
+	// timestamps in UTC
+	utcFormat = "20060102T150405Z"
+)
+
+// Options selects the events of a feed
+type Options struct {
+	// Name of the calendar shown by calendar apps
+	Name string
+	// Location is the time zone of the cinema
+	Location *time.Location
+	// Now decides the week that is rendered, week day 1 of the schedule is its Monday
+	Now time.Time
+	// Screen limits the feed to one screen if not empty
+	Screen string
+	// MovieID limits the feed to one movie if not empty
+	MovieID string
+	// OnSkip is called for shows left out of the feed because they cannot be rendered. Optional
+	OnSkip func(slot snapshot.Slot, err error)
+}
+
+// event is a show of a movie
+type event struct {
+	uid      string
+	start    time.Time
+	end      time.Time
+	summary  string
+	desc     string
+	location string
+}
+
+// Render writes the shows in the weekly schedule that have a movie as iCalendar events.
+// Event UIDs are derived from the date, screen and show so that a changed movie replaces the previous event.
+// Shows with a play time that cannot be parsed are left out rather than failing the whole feed.
+func Render(w io.Writer, weeklySchedule *scheduler.DaysSchedule, opts Options) error {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := opts.Now.In(loc)
+
+	// Monday of the current week in the cinema time zone
+	monday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	monday = monday.AddDate(0, 0, -((int(monday.Weekday()) + 6) % 7))
+
+	events := make([]event, 0)
+	for _, slot := range snapshot.Slots(weeklySchedule) {
+		if opts.Screen != "" && slot.Screen != opts.Screen {
+			continue
+		}
+		showSchedule := snapshot.Lookup(weeklySchedule, slot)
+		movieItem := showSchedule.GetMovie()
+		if movieItem.GetId() == "" || (opts.MovieID != "" && movieItem.GetId() != opts.MovieID) {
+			continue
+		}
+
+		hour, minute, err := ParsePlayTime(showSchedule.GetPlayTime())
+		if err != nil {
+			if opts.OnSkip != nil {
+				opts.OnSkip(slot, err)
+			}
+			continue
+		}
+
+		day := monday.AddDate(0, 0, int(slot.WeekDay)-1)
+		start := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+
+		duration := time.Duration(movieItem.GetDurationMinutes()) * time.Minute
+		if duration <= 0 {
+			duration = defaultDuration
+		}
+
+		title := movieItem.GetTitle()
+		if title == "" {
+			title = movieItem.GetId()
+		}
+
+		events = append(events, event{
+			uid:      fmt.Sprintf("%s-%s-show%d@%s", day.Format("20060102"), slug(slot.Screen), slot.Show, uidDomain),
+			start:    start,
+			end:      start.Add(duration),
+			summary:  title,
+			desc:     movieItem.GetDescription(),
+			location: slot.Screen,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+
+	bw := bufio.NewWriter(w)
+	lw := &lineWriter{w: bw}
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + prodID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if opts.Name != "" {
+		lw.line("X-WR-CALNAME:" + escape(opts.Name))
+	}
+	lw.line("X-WR-TIMEZONE:" + loc.String())
+
+	stamp := opts.Now.UTC().Format(utcFormat)
+	for _, e := range events {
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.uid)
+		lw.line("DTSTAMP:" + stamp)
+		lw.line("DTSTART:" + e.start.UTC().Format(utcFormat))
+		lw.line("DTEND:" + e.end.UTC().Format(utcFormat))
+		lw.line("SUMMARY:" + escape(e.summary))
+		if e.desc != "" {
+			lw.line("DESCRIPTION:" + escape(e.desc))
+		}
+		lw.line("LOCATION:" + escape(e.location))
+		lw.line("END:VEVENT")
+	}
+
+	lw.line("END:VCALENDAR")
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return bw.Flush()
+}
+
+var playTimeRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// ParsePlayTime parses play times such as 11am, 6:30pm or 18:00 into hour and minute
+func ParsePlayTime(playTime string) (int, int, error) {
+	m := playTimeRe.FindStringSubmatch(strings.ToLower(strings.Trim(playTime, " ")))
+	if m == nil {
+		return 0, 0, fmt.Errorf("play time %q is not in a form like 11am, 6:30pm or 18:00", playTime)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("play time %q has an hour outside 1 to 12", playTime)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	default:
+		if m[2] == "" {
+			return 0, 0, fmt.Errorf("play time %q needs am, pm or minutes", playTime)
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("play time %q is not a valid time of day", playTime)
+	}
+
+	return hour, minute, nil
+}
+
+// escapes TEXT values as described in RFC 5545 section 3.3.11
+func escape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// makes a screen name safe for UIDs e.g Screen 1 becomes screen-1
+func slug(name string) string {
+	return strings.Trim(slugRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// lineWriter writes content lines ending in CRLF, folded at 75 octets
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (lw *lineWriter) line(content string) {
+	if lw.err != nil {
+		return
+	}
+
+	const limit = 75
+	first := true
+	for len(content) > 0 {
+		max := limit
+		if !first {
+			// Continuation lines start with a space
+			max = limit - 1
+			lw.w.WriteByte(' ')
+		}
+		n := len(content)
+		if n > max {
+			n = max
+			// Never split a UTF-8 sequence
+			for n > 0 && content[n]&0xC0 == 0x80 {
+				n--
+			}
+		}
+		lw.w.WriteString(content[:n])
+		_, lw.err = lw.w.WriteString("\r\n")
+		content = content[n:]
+		first = false
+	}
+}
@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+// returns a schedule with shows on Monday and Wednesday, one with a play time that cannot be parsed
+func testSchedule() *scheduler.DaysSchedule {
+	show := func(playTime string, movieItem *movie.Movie) *scheduler.ShowSchedule {
+		return &scheduler.ShowSchedule{PlayTime: playTime, Movie: movieItem}
+	}
+	one := &movie.Movie{Id: "m1", Title: "Movie, One", Description: "First; of two", DurationMinutes: 90}
+	two := &movie.Movie{Id: "m2"}
+
+	return &scheduler.DaysSchedule{
+		DaysSchedule: map[int32]*scheduler.ScreensSchedule{
+			1: {ScreensSchedule: map[string]*scheduler.ShowsSchedule{
+				"Screen 1": {ShowsSchedule: map[int32]*scheduler.ShowSchedule{1: show("10:00", one)}},
+				"Screen 2": {ShowsSchedule: map[int32]*scheduler.ShowSchedule{2: show("14:00", &movie.Movie{})}},
+			}},
+			2: {ScreensSchedule: map[string]*scheduler.ShowsSchedule{
+				"Screen 1": {ShowsSchedule: map[int32]*scheduler.ShowSchedule{2: show("noon", one)}},
+			}},
+			3: {ScreensSchedule: map[string]*scheduler.ShowsSchedule{
+				"Screen 2": {ShowsSchedule: map[int32]*scheduler.ShowSchedule{1: show("6:30pm", two)}},
+			}},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	// Wednesday in a cinema three hours ahead of UTC
+	loc := time.FixedZone("EAT", 3*60*60)
+	now := time.Date(2026, 10, 14, 1, 30, 0, 0, loc)
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantLines []string
+		wantNot   []string
+		wantSkips []snapshot.Slot
+	}{
+		{
+			name: "screen feed",
+			opts: Options{Name: "Screen 1", Screen: "Screen 1"},
+			wantLines: []string{
+				"X-WR-CALNAME:Screen 1",
+				"X-WR-TIMEZONE:EAT",
+				"UID:20261012-screen-1-show1@scheduling.rupacinema",
+				"DTSTAMP:20261013T223000Z",
+				"DTSTART:20261012T070000Z",
+				"DTEND:20261012T083000Z",
+				`SUMMARY:Movie\, One`,
+				`DESCRIPTION:First\; of two`,
+				"LOCATION:Screen 1",
+			},
+			wantNot:   []string{"m2", "Screen 2"},
+			wantSkips: []snapshot.Slot{{WeekDay: 2, Screen: "Screen 1", Show: 2}},
+		},
+		{
+			name: "movie feed",
+			opts: Options{MovieID: "m2"},
+			wantLines: []string{
+				"UID:20261014-screen-2-show1@scheduling.rupacinema",
+				"DTSTART:20261014T153000Z",
+				"DTEND:20261014T173000Z",
+				"SUMMARY:m2",
+				"LOCATION:Screen 2",
+			},
+			wantNot: []string{"Movie\\, One", "DESCRIPTION", "X-WR-CALNAME"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var skips []snapshot.Slot
+			tt.opts.Location, tt.opts.Now = loc, now
+			tt.opts.OnSkip = func(slot snapshot.Slot, err error) {
+				if err == nil {
+					t.Errorf("show %v skipped without an error", slot)
+				}
+				skips = append(skips, slot)
+			}
+
+			var buf bytes.Buffer
+			err := Render(&buf, testSchedule(), tt.opts)
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+			out := buf.String()
+
+			if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+				t.Errorf("output is not a calendar:\n%s", out)
+			}
+			if got := strings.Count(out, "BEGIN:VEVENT\r\n"); got != 1 {
+				t.Errorf("output has %d events, want 1:\n%s", got, out)
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(out, "\r\n"+line+"\r\n") {
+					t.Errorf("output has no line %q:\n%s", line, out)
+				}
+			}
+			for _, text := range tt.wantNot {
+				if strings.Contains(out, text) {
+					t.Errorf("output contains %q:\n%s", text, out)
+				}
+			}
+			if len(skips) != len(tt.wantSkips) {
+				t.Fatalf("skipped shows %v, want %v", skips, tt.wantSkips)
+			}
+			for i := range skips {
+				if skips[i] != tt.wantSkips[i] {
+					t.Errorf("skipped shows %v, want %v", skips, tt.wantSkips)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderFoldsLongLines(t *testing.T) {
+	weeklySchedule := testSchedule()
+	longTitle := strings.Repeat("é", 60)
+	weeklySchedule.DaysSchedule[1].ScreensSchedule["Screen 1"].ShowsSchedule[1].Movie.Title = longTitle
+
+	var buf bytes.Buffer
+	err := Render(&buf, weeklySchedule, Options{Screen: "Screen 1", Now: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	unfolded := strings.Replace(buf.String(), "\r\n ", "", -1)
+	if !strings.Contains(unfolded, "\r\nSUMMARY:"+longTitle+"\r\n") {
+		t.Errorf("folded summary does not unfold to the title:\n%s", buf.String())
+	}
+}
+
+func TestParsePlayTime(t *testing.T) {
+	tests := []struct {
+		playTime   string
+		wantHour   int
+		wantMinute int
+		wantErr    bool
+	}{
+		{playTime: "11am", wantHour: 11},
+		{playTime: "12am", wantHour: 0},
+		{playTime: "12pm", wantHour: 12},
+		{playTime: "6:30pm", wantHour: 18, wantMinute: 30},
+		{playTime: " 6:30 PM ", wantHour: 18, wantMinute: 30},
+		{playTime: "18:00", wantHour: 18},
+		{playTime: "18", wantErr: true},
+		{playTime: "13pm", wantErr: true},
+		{playTime: "24:00", wantErr: true},
+		{playTime: "10:75", wantErr: true},
+		{playTime: "noon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.playTime, func(t *testing.T) {
+			hour, minute, err := ParsePlayTime(tt.playTime)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlayTime() error = %v, want error %t", err, tt.wantErr)
+			}
+			if hour != tt.wantHour || minute != tt.wantMinute {
+				t.Errorf("ParsePlayTime() = %d:%02d, want %d:%02d", hour, minute, tt.wantHour, tt.wantMinute)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Package idempotency replays the result of requests retried with the same idempotency key,
+// so that a retried mutation is applied only once.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Header is the metadata key carrying the idempotency key of a request.
+	// The REST gateway forwards the Idempotency-Key header under it
+	Header = "idempotency-key"
+	// ReplayedHeader is set on the response metadata of a replayed request
+	ReplayedHeader = "idempotent-replayed"
+
+	maxKeyLength = 255
+)
+
+// fingerprintHeaders are metadata keys that change how a request is applied, so they are part of
+// its fingerprint. The REST gateway forwards the If-Match header under if-match, which the service
+// reads as the expected version when the request message has none
+var fingerprintHeaders = []string{"if-match"}
+
+// record is the result of a request made with an idempotency key
+type record struct {
+	Fingerprint string     `json:"fingerprint"`
+	Response    []byte     `json:"response,omitempty"` // google.protobuf.Any
+	Code        codes.Code `json:"code"`
+	Message     string     `json:"message,omitempty"`
+	Expires     time.Time  `json:"expires"`
+}
+
+// queued is a key in the order its result expires
+type queued struct {
+	key     string
+	expires time.Time
+}
+
+// Cache keeps the results of requests made with an idempotency key for a window of time
+type Cache struct {
+	mu       sync.Mutex
+	window   time.Duration
+	maxKeys  int
+	records  map[string]*record
+	queue    []queued // keys of records, soonest to expire first
+	inflight map[string]chan struct{}
+}
+
+// NewCache creates a cache that keeps results for window.
+// Once it holds maxKeys results, the oldest results are removed to make room
+func NewCache(window time.Duration, maxKeys int) *Cache {
+	return &Cache{
+		window:   window,
+		maxKeys:  maxKeys,
+		records:  make(map[string]*record),
+		queue:    make([]queued, 0),
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+// Marshal encodes the results that have not expired
+func (cache *Cache) Marshal() ([]byte, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.expire(time.Now())
+	return json.Marshal(cache.records)
+}
+
+// Unmarshal replaces the results in the cache with those encoded by Marshal
+func (cache *Cache) Unmarshal(bs []byte) error {
+	records := make(map[string]*record)
+	err := json.Unmarshal(bs, &records)
+	if err != nil {
+		return err
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.records = records
+	cache.queue = make([]queued, 0, len(records))
+	for key, rec := range records {
+		cache.queue = append(cache.queue, queued{key: key, expires: rec.Expires})
+	}
+	sort.Slice(cache.queue, func(i, j int) bool { return cache.queue[i].expires.Before(cache.queue[j].expires) })
+	cache.expire(time.Now())
+	return nil
+}
+
+// Len returns the number of results in the cache
+func (cache *Cache) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return len(cache.records)
+}
+
+// removes expired results, and the oldest results while there are more than maxKeys.
+// Results expire in the order they were put, so only the front of the queue is looked at.
+// Assumes that the mutex is locked
+func (cache *Cache) expire(now time.Time) {
+	for len(cache.queue) != 0 {
+		oldest := cache.queue[0]
+		if !now.After(oldest.expires) && len(cache.records) <= cache.maxKeys {
+			return
+		}
+		cache.queue = cache.queue[1:]
+		// The key may have been put again since, with a later expiry
+		if rec, ok := cache.records[oldest.key]; ok && rec.Expires.Equal(oldest.expires) {
+			delete(cache.records, oldest.key)
+		}
+	}
+}
+
+// acquire waits until no other request with the key is in flight, then marks the key in flight
+func (cache *Cache) acquire(ctx context.Context, key string) (func(), error) {
+	for {
+		cache.mu.Lock()
+		done, busy := cache.inflight[key]
+		if !busy {
+			done = make(chan struct{})
+			cache.inflight[key] = done
+			cache.mu.Unlock()
+			return func() {
+				cache.mu.Lock()
+				delete(cache.inflight, key)
+				cache.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		cache.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, status.Errorf(codes.Canceled, "waiting for request with the same idempotency key: %v", ctx.Err())
+		}
+	}
+}
+
+func (cache *Cache) get(key string) (*record, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	rec, ok := cache.records[key]
+	if !ok || time.Now().After(rec.Expires) {
+		delete(cache.records, key)
+		return nil, false
+	}
+	return rec, true
+}
+
+func (cache *Cache) put(key string, rec *record) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	now := time.Now()
+	rec.Expires = now.Add(cache.window)
+	cache.records[key] = rec
+	cache.queue = append(cache.queue, queued{key: key, expires: rec.Expires})
+	cache.expire(now)
+}
+
+// UnaryServerInterceptor replays the recorded result of the given methods when they are called
+// again with the same idempotency key. Keys are scoped to the method and the caller.
+// Reusing a key with a different request fails with InvalidArgument.
+func UnaryServerInterceptor(cache *Cache, methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		idempotent[method] = true
+	}
+
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		idempotencyKey := keyFromContext(ctx)
+		if idempotencyKey == "" || !idempotent[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if len(idempotencyKey) > maxKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "idempotency key longer than %d characters", maxKeyLength)
+		}
+
+		fingerprint, err := fingerprint(ctx, req)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fingerprint request: %v", err)
+		}
+
+		var userID string
+		if claims, ok := auth.FromContext(ctx); ok {
+			userID = claims.UserID
+		}
+		key := info.FullMethod + "\x00" + userID + "\x00" + idempotencyKey
+
+		// Duplicates sent while the first request is in flight wait for its result
+		release, err := cache.acquire(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		if rec, ok := cache.get(key); ok {
+			if rec.Fingerprint != fingerprint {
+				return nil, status.Error(codes.InvalidArgument, "idempotency key was used with a different request")
+			}
+			grpc.SetHeader(ctx, metadata.Pairs(ReplayedHeader, "true"))
+			return replay(rec)
+		}
+
+		resp, err := handler(ctx, req)
+
+		// Errors that may not happen again are not recorded so that the request can be retried.
+		// Aborted requests expected another version, and are retried with the same key once refreshed
+		switch status.Code(err) {
+		case codes.Canceled, codes.DeadlineExceeded, codes.Unavailable, codes.Internal, codes.Aborted:
+			return resp, err
+		}
+
+		rec := &record{Fingerprint: fingerprint}
+		if err != nil {
+			st := status.Convert(err)
+			rec.Code, rec.Message = st.Code(), st.Message()
+		} else if msg, ok := resp.(proto.Message); ok {
+			anyResp, marshalErr := ptypes.MarshalAny(msg)
+			if marshalErr != nil {
+				return resp, err
+			}
+			rec.Response, marshalErr = proto.Marshal(anyResp)
+			if marshalErr != nil {
+				return resp, err
+			}
+		}
+		cache.put(key, rec)
+
+		return resp, err
+	}
+}
+
+// returns the idempotency key in the request metadata
+func keyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(Header)) == 0 {
+		return ""
+	}
+	return md.Get(Header)[0]
+}
+
+// returns a hash of the request message and the metadata in fingerprintHeaders
+func fingerprint(ctx context.Context, req interface{}) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", nil
+	}
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+	err := buf.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(proto.MessageName(msg) + "\x00"))
+	hash.Write(buf.Bytes())
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range fingerprintHeaders {
+		for _, value := range md.Get(header) {
+			hash.Write([]byte("\x00" + header + ":" + value))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// returns the recorded result of a request
+func replay(rec *record) (interface{}, error) {
+	if rec.Code != codes.OK {
+		return nil, status.Error(rec.Code, rec.Message)
+	}
+	anyResp := &any.Any{}
+	err := proto.Unmarshal(rec.Response, anyResp)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to replay response: %v", err)
+	}
+	var resp ptypes.DynamicAny
+	err = ptypes.UnmarshalAny(anyResp, &resp)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to replay response: %v", err)
+	}
+	return resp.Message, nil
+}
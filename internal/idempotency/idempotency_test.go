@@ -0,0 +1,223 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/test.Service/Mutate"
+
+// call is a request made through the interceptor and the result expected for it
+type call struct {
+	userID   string
+	key      string
+	method   string
+	req      string
+	ifMatch  string
+	fail     codes.Code // code the handler fails with, if not OK
+	wantCode codes.Code
+	wantResp string // response expected when wantCode is OK
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "retry is replayed",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+			},
+		},
+		{
+			name: "key reused with a different request",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+				{userID: "u1", key: "k1", req: "b", wantCode: codes.InvalidArgument},
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+			},
+		},
+		{
+			name: "keys are scoped to the caller",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+				{userID: "u2", key: "k1", req: "a", wantResp: "a 2"},
+			},
+		},
+		{
+			name: "keys are scoped to the method",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 1"},
+				{userID: "u1", key: "k1", method: testMethod + "2", req: "a", wantResp: "a 2"},
+			},
+		},
+		{
+			name: "requests without a key are not replayed",
+			calls: []call{
+				{userID: "u1", req: "a", wantResp: "a 1"},
+				{userID: "u1", req: "a", wantResp: "a 2"},
+			},
+		},
+		{
+			name: "other methods are not replayed",
+			calls: []call{
+				{userID: "u1", key: "k1", method: "/test.Service/Get", req: "a", wantResp: "a 1"},
+				{userID: "u1", key: "k1", method: "/test.Service/Get", req: "a", wantResp: "a 2"},
+			},
+		},
+		{
+			name: "errors are replayed",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", fail: codes.NotFound, wantCode: codes.NotFound},
+				{userID: "u1", key: "k1", req: "a", wantCode: codes.NotFound},
+			},
+		},
+		{
+			name: "errors that may not happen again are retried",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", fail: codes.Unavailable, wantCode: codes.Unavailable},
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 2"},
+				{userID: "u1", key: "k1", req: "a", wantResp: "a 2"},
+			},
+		},
+		{
+			name: "key reused with a different expected version",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"1"`, wantResp: "a 1"},
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"2"`, wantCode: codes.InvalidArgument},
+				{userID: "u1", key: "k1", req: "a", wantCode: codes.InvalidArgument},
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"1"`, wantResp: "a 1"},
+			},
+		},
+		{
+			name: "version mismatches are retried",
+			calls: []call{
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"1"`, fail: codes.Aborted, wantCode: codes.Aborted},
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"1"`, wantResp: "a 2"},
+				{userID: "u1", key: "k1", req: "a", ifMatch: `"1"`, wantResp: "a 2"},
+			},
+		},
+		{
+			name: "key too long",
+			calls: []call{
+				{userID: "u1", key: strings.Repeat("k", maxKeyLength+1), req: "a", wantCode: codes.InvalidArgument},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryServerInterceptor(NewCache(time.Hour, 100), testMethod, testMethod+"2")
+
+			served := 0
+			for i, c := range tt.calls {
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					served++
+					if c.fail != codes.OK {
+						return nil, status.Error(c.fail, "failed")
+					}
+					value := req.(*wrappers.StringValue).GetValue()
+					return &wrappers.StringValue{Value: fmt.Sprintf("%s %d", value, served)}, nil
+				}
+
+				ctx := auth.NewContext(context.Background(), &auth.Claims{UserID: c.userID})
+				md := metadata.MD{}
+				if c.key != "" {
+					md.Set(Header, c.key)
+				}
+				if c.ifMatch != "" {
+					md.Set("if-match", c.ifMatch)
+				}
+				ctx = metadata.NewIncomingContext(ctx, md)
+				method := c.method
+				if method == "" {
+					method = testMethod
+				}
+
+				resp, err := interceptor(
+					ctx, &wrappers.StringValue{Value: c.req}, &grpc.UnaryServerInfo{FullMethod: method}, handler,
+				)
+				if status.Code(err) != c.wantCode {
+					t.Fatalf("call %d: error = %v, want code %s", i+1, err, c.wantCode)
+				}
+				if err != nil {
+					continue
+				}
+				want := &wrappers.StringValue{Value: c.wantResp}
+				if !proto.Equal(resp.(proto.Message), want) {
+					t.Errorf("call %d: response = %v, want %v", i+1, resp, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheExpire(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   time.Duration
+		maxKeys  int
+		keys     []string
+		wantKeys []string
+	}{
+		{
+			name:     "results within the window are kept",
+			window:   time.Hour,
+			maxKeys:  10,
+			keys:     []string{"a", "b", "c"},
+			wantKeys: []string{"a", "b", "c"},
+		},
+		{
+			name:     "oldest results are removed past the maximum",
+			window:   time.Hour,
+			maxKeys:  2,
+			keys:     []string{"a", "b", "c"},
+			wantKeys: []string{"b", "c"},
+		},
+		{
+			name:     "result put again is kept",
+			window:   time.Hour,
+			maxKeys:  2,
+			keys:     []string{"a", "b", "a", "c"},
+			wantKeys: []string{"a", "c"},
+		},
+		{
+			name:     "expired results are removed",
+			window:   -time.Second,
+			maxKeys:  10,
+			keys:     []string{"a", "b"},
+			wantKeys: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache(tt.window, tt.maxKeys)
+			for _, key := range tt.keys {
+				cache.put(key, &record{})
+			}
+
+			if cache.Len() != len(tt.wantKeys) {
+				t.Errorf("Len() = %d, want %d", cache.Len(), len(tt.wantKeys))
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := cache.get(key); !ok {
+					t.Errorf("result for %q was removed", key)
+				}
+			}
+		})
+	}
+}
@@ -3,12 +3,19 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"github.com/gidyon/rupacinema/scheduling/internal/audit"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/internal/idempotency"
 	"github.com/gidyon/rupacinema/scheduling/internal/protocol"
 	"github.com/gidyon/rupacinema/scheduling/pkg/config"
 	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"io"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc/middleware"
@@ -16,59 +23,204 @@ import (
 	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
 )
 
-// CreateGRPCServer ...
-func CreateGRPCServer(ctx context.Context, cfg *config.Config) (*grpc.Server, error) {
+// methods that can be called without authentication
+var publicMethods = []string{
+	"/rupacinema.movie.ShowScheduler/GetDaySchedule",
+	"/rupacinema.movie.ShowScheduler/GetShowSchedule",
+	"/rupacinema.movie.ShowScheduler/GetWeekSchedule",
+	"/rupacinema.movie.ShowScheduler/FindMovieShows",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+	"/grpc.health.v1.Health/Check",
+	"/grpc.health.v1.Health/Watch",
+}
 
-	tlsConfig, err := protocol.GRPCServerTLS()
-	if err != nil {
-		return nil, err
+// methods whose results are replayed when retried with an idempotency key
+var idempotentMethods = []string{
+	"/rupacinema.movie.ShowScheduler/VoteUpMovie",
+	"/rupacinema.movie.ShowScheduler/AddVotedMovie",
+	"/rupacinema.movie.ShowScheduler/RemoveVotedMovie",
+	"/rupacinema.movie.ShowScheduler/ReorderVotedMovies",
+	"/rupacinema.movie.ShowScheduler/CreateMovieDaySchedule",
+	"/rupacinema.movie.ShowScheduler/DeleteMovieDaySchedule",
+	"/rupacinema.movie.ShowScheduler/UpdateMovieDaySchedule",
+	"/rupacinema.movie.ShowScheduler/MoveMovieDaySchedule",
+	"/rupacinema.movie.ShowScheduler/SwapMovieDaySchedules",
+	"/rupacinema.movie.ShowScheduler/BatchUpdateSchedule",
+	"/rupacinema.movie.ShowScheduler/ImportSchedule",
+	"/rupacinema.movie.ShowScheduler/UndoChange",
+	"/rupacinema.movie.ShowScheduler/RestoreSchedule",
+}
+
+// methods whose authenticated calls are written to the audit log
+var auditedMethods = append([]string{
+	"/rupacinema.movie.ShowScheduler/PublishDraft",
+	"/rupacinema.movie.ShowScheduler/DiscardDraft",
+}, idempotentMethods...)
+
+// Server is the gRPC server together with the scheduling service it serves
+type Server struct {
+	*grpc.Server
+	// HealthChecker reports readiness of the service and its dependencies
+	HealthChecker *HealthChecker
+	service       scheduler.ShowSchedulerServer
+	auditLog      *audit.Log
+}
+
+// Close flushes state held by the scheduling service and closes the audit log.
+// It should be called after the server has stopped serving requests.
+func (s *Server) Close() error {
+	if s.auditLog != nil {
+		err := s.auditLog.Close()
+		if err != nil {
+			return fmt.Errorf("failed to close audit log: %v", err)
+		}
+	}
+	if closer, ok := s.service.(io.Closer); ok {
+		return closer.Close()
 	}
+	return nil
+}
+
+// weeklyScheduler is implemented by services that can return a copy of the weekly schedule
+type weeklyScheduler interface {
+	WeeklySchedule() *scheduler.DaysSchedule
+}
+
+// WeeklySchedule returns a copy of the weekly schedule held by the scheduling service
+func (s *Server) WeeklySchedule() (*scheduler.DaysSchedule, error) {
+	if ws, ok := s.service.(weeklyScheduler); ok {
+		return ws.WeeklySchedule(), nil
+	}
+	return nil, fmt.Errorf("scheduling service does not expose the weekly schedule")
+}
 
-	creds := credentials.NewTLS(tlsConfig)
+// CreateGRPCServer creates the gRPC server along with the scheduling service and its health checker
+func CreateGRPCServer(ctx context.Context, cfg *config.Config) (*Server, error) {
 
 	opts := []grpc.ServerOption{
-		grpc.Creds(creds),
+		// Continues traces propagated from the gateway and other clients
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 	}
 
-	err = logger.Init(cfg.LogLevel, cfg.LogTimeFormat)
+	if !cfg.Insecure {
+		tlsConfig, err := protocol.GRPCServerTLS()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	err := logger.Init(cfg.LogLevel, cfg.LogTimeFormat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %v", err)
 	}
 
+	if cfg.Insecure {
+		logger.Log.Warn("insecure mode: serving and dialing upstream services without TLS, do not use in production")
+	}
+
 	// add logging middleware
 	unaryLoggerInterceptors, streamLoggerInterceptors := middleware.AddLogging(logger.Log)
 
-	// add recovery from panic middleware
+	// Remote services
+	remoteServices, err := dialRemoteServices(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// add authentication middleware
+	authenticator, err := auth.NewAuthenticator(cfg, remoteServices.accountServiceClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %v", err)
+	}
+	unaryAuthInterceptors, streamAuthInterceptors := middleware.AddAuthentication(
+		authenticator, publicMethods...,
+	)
+
+	// add metrics middleware
+	unaryMetricsInterceptors, streamMetricsInterceptors := middleware.AddMetrics()
+
+	// replay results of mutations retried with an idempotency key.
+	// Replays run before auditing so that only requests that are served are audited
+	idempotencyCache := idempotency.NewCache(cfg.IdempotencyWindow, cfg.IdempotencyMaxKeys)
+	unaryIdempotencyInterceptors := []grpc.UnaryServerInterceptor{
+		idempotency.UnaryServerInterceptor(idempotencyCache, idempotentMethods...),
+	}
+
+	// write authenticated mutations to the audit log
+	var auditLog *audit.Log
+	unaryAuditInterceptors := []grpc.UnaryServerInterceptor{}
+	if cfg.AuditLogPath != "" {
+		trustedProxies, err := audit.ParseTrustedProxies(cfg.AuditTrustedProxies)
+		if err != nil {
+			return nil, err
+		}
+		auditLog, err = audit.Open(cfg.AuditLogPath, int64(cfg.AuditLogMaxSize)<<20, cfg.AuditLogMaxFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %v", err)
+		}
+		onError := func(err error) {
+			logger.Log.Error("failed to write audit record", zap.Error(err))
+		}
+		unaryAuditInterceptors = append(unaryAuditInterceptors,
+			audit.UnaryServerInterceptor(auditLog, trustedProxies, onError, auditedMethods...),
+		)
+		logger.Log.Info("writing audit records", zap.Stringer("log", auditLog))
+	}
+
+	// add recovery from panic middleware.
+	// Recovery runs before authentication so that panics in authentication, replays and auditing are recovered,
+	// and again next to the handler so that a panic in the handler is audited as a failed request
 	unaryRecoveryInterceptors, streamRecoveryInterceptors := middleware.AddRecovery()
 
 	opts = append(opts,
 		grpc_middleware.WithUnaryServerChain(
 			chainUnaryInterceptors(
 				unaryLoggerInterceptors,
+				unaryMetricsInterceptors,
+				unaryRecoveryInterceptors,
+				unaryAuthInterceptors,
+				unaryIdempotencyInterceptors,
+				unaryAuditInterceptors,
 				unaryRecoveryInterceptors,
 			)...,
 		),
 		grpc_middleware.WithStreamServerChain(
 			chainStreamInterceptors(
 				streamLoggerInterceptors,
+				streamMetricsInterceptors,
 				streamRecoveryInterceptors,
+				streamAuthInterceptors,
 			)...,
 		),
 	)
 
 	s := grpc.NewServer(opts...)
 
-	schedulingService, err := createSchedulerServer(ctx, cfg)
+	schedulingService, err := createSchedulerServer(ctx, cfg, remoteServices, idempotencyCache)
 	if err != nil {
 		return nil, err
 	}
 
 	scheduler.RegisterShowSchedulerServer(s, schedulingService)
 
+	// Apply configuration changes without restart
+	go watchConfig(ctx, cfg, schedulingService)
+
+	// Register health checking service on gRPC server.
+	healthChecker := newHealthChecker(schedulingService, remoteServices)
+	grpc_health_v1.RegisterHealthServer(s, healthChecker.healthServer)
+	go healthChecker.run(ctx)
+
 	// Register reflection service on gRPC server.
 	reflection.Register(s)
 
-	return s, nil
+	return &Server{
+		Server:        s,
+		HealthChecker: healthChecker,
+		service:       schedulingService,
+		auditLog:      auditLog,
+	}, nil
 }
 
 type grpcUnaryInterceptorsSlice []grpc.UnaryServerInterceptor
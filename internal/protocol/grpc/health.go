@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	schedulerServiceName = "rupacinema.movie.ShowScheduler"
+	healthCheckInterval  = 10 * time.Second
+)
+
+// readiness is implemented by services that can report whether they are ready to serve
+type readiness interface {
+	Ready() error
+}
+
+// HealthChecker reports readiness of the scheduling service and its dependencies.
+// It keeps the gRPC health service in sync with the readiness checks.
+type HealthChecker struct {
+	healthServer *health.Server
+	service      readiness
+	conns        map[string]*grpc.ClientConn
+	draining     int32 // set atomically once the server starts shutting down
+}
+
+func newHealthChecker(service interface{}, remote *remoteServices) *HealthChecker {
+	hc := &HealthChecker{
+		healthServer: health.NewServer(),
+		conns: map[string]*grpc.ClientConn{
+			"movie service":   remote.movieServiceConn,
+			"account service": remote.accountServiceConn,
+		},
+	}
+	if r, ok := service.(readiness); ok {
+		hc.service = r
+	}
+	return hc
+}
+
+// Drain marks the service as not ready so that no new traffic is routed to it
+func (hc *HealthChecker) Drain() {
+	atomic.StoreInt32(&hc.draining, 1)
+	hc.healthServer.Shutdown()
+}
+
+// Ready returns an error describing the first dependency that is not ready
+func (hc *HealthChecker) Ready() error {
+	if atomic.LoadInt32(&hc.draining) == 1 {
+		return errors.New("scheduling service is shutting down")
+	}
+
+	if hc.service != nil {
+		if err := hc.service.Ready(); err != nil {
+			return err
+		}
+	}
+
+	for name, conn := range hc.conns {
+		switch state := conn.GetState(); state {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection to %s is %s", name, state)
+		}
+	}
+
+	return nil
+}
+
+// updates the gRPC health service serving status periodically
+func (hc *HealthChecker) run(ctx context.Context) {
+	for {
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if err := hc.Ready(); err != nil {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			logger.Log.Warn("scheduling service not ready", zap.Error(err))
+		}
+		// Once shut down, the health server ignores serving status updates
+		hc.healthServer.SetServingStatus("", status)
+		hc.healthServer.SetServingStatus(schedulerServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			hc.healthServer.Shutdown()
+			return
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeService reports the readiness of the scheduling service
+type fakeService struct {
+	err error
+}
+
+func (service fakeService) Ready() error {
+	return service.err
+}
+
+// returns a connection to a gRPC server that is listening, which never fails
+func dialTestServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHealthCheckerReady(t *testing.T) {
+	tests := []struct {
+		name          string
+		service       interface{}
+		closeUpstream bool
+		drain         bool
+		wantReady     bool
+	}{
+		{name: "ready", service: fakeService{}, wantReady: true},
+		{name: "service without readiness", service: struct{}{}, wantReady: true},
+		{name: "schedule not loaded", service: fakeService{err: errors.New("weekly schedule is not loaded")}},
+		{name: "upstream connection shut down", service: fakeService{}, closeUpstream: true},
+		{name: "draining", service: fakeService{}, drain: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := &remoteServices{
+				movieServiceConn:   dialTestServer(t),
+				accountServiceConn: dialTestServer(t),
+			}
+			hc := newHealthChecker(tt.service, remote)
+			if tt.closeUpstream {
+				remote.movieServiceConn.Close()
+			}
+			if tt.drain {
+				hc.Drain()
+			}
+
+			err := hc.Ready()
+			if (err == nil) != tt.wantReady {
+				t.Errorf("Ready() = %v, want ready %t", err, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerDrainStopsServing(t *testing.T) {
+	remote := &remoteServices{
+		movieServiceConn:   dialTestServer(t),
+		accountServiceConn: dialTestServer(t),
+	}
+	hc := newHealthChecker(fakeService{}, remote)
+
+	hc.Drain()
+
+	res, err := hc.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after Drain() = %s, want NOT_SERVING", res.GetStatus())
+	}
+
+	// Later readiness checks do not bring a draining server back
+	hc.healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	res, err = hc.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after an update while draining = %s, want NOT_SERVING", res.GetStatus())
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const tracerName = "github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc/middleware"
+
+// draftRequest is implemented by requests that may read the draft schedule
+type draftRequest interface {
+	GetDraft() bool
+}
+
+// AddAuthentication returns interceptors that authenticate every method except the public ones.
+// Public methods are served anonymously. Their callers are authenticated only when they have a token and,
+// for unary methods, ask for the draft schedule; a failure is kept in the context for the service to report.
+// Claims of the caller are stored in the request context.
+func AddAuthentication(
+	authenticator auth.Authenticator, publicMethods ...string,
+) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	authenticate := func(ctx context.Context) (context.Context, error) {
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "auth.Authenticate")
+		claims, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			span.SetStatus(otelcodes.Error, err.Error())
+			span.End()
+			return nil, err
+		}
+		span.SetAttributes(attribute.String("auth.user_id", claims.UserID))
+		span.End()
+
+		// So that the caller appears in the request logs
+		grpc_ctxtags.Extract(ctx).Set("auth.user_id", claims.UserID)
+
+		return auth.NewContext(ctx, claims), nil
+	}
+
+	// public requests continue without a caller when authentication fails
+	authenticatePublic := func(ctx context.Context) context.Context {
+		if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) == 0 {
+			return ctx
+		}
+		authCtx, err := authenticate(ctx)
+		if err != nil {
+			return auth.NewErrorContext(ctx, err)
+		}
+		return authCtx
+	}
+
+	unary := func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if public[info.FullMethod] {
+			// Only the draft schedule needs the caller, so other public reads skip authentication
+			if draftReq, ok := req.(draftRequest); ok && draftReq.GetDraft() {
+				ctx = authenticatePublic(ctx)
+			}
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		var ctx context.Context
+		if public[info.FullMethod] {
+			ctx = authenticatePublic(ss.Context())
+		} else {
+			var err error
+			ctx, err = authenticate(ss.Context())
+			if err != nil {
+				return err
+			}
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return []grpc.UnaryServerInterceptor{unary}, []grpc.StreamServerInterceptor{stream}
+}
+
+// serverStream is a server stream with the context of the authenticated caller
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
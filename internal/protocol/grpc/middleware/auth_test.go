@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAuthenticator accepts the token "valid" and counts its calls
+type fakeAuthenticator struct {
+	calls int
+}
+
+func (fakeAuth *fakeAuthenticator) Authenticate(ctx context.Context) (*auth.Claims, error) {
+	fakeAuth.calls++
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 0 && values[0] == "Bearer valid" {
+		return &auth.Claims{UserID: "u1", Roles: []string{auth.RoleProgrammer}}, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "bad token")
+}
+
+type testRequest struct {
+	draft bool
+}
+
+func (req *testRequest) GetDraft() bool {
+	return req.draft
+}
+
+func TestAuthenticationUnary(t *testing.T) {
+	const (
+		publicMethod  = "/scheduler.ShowScheduler/GetSchedule"
+		privateMethod = "/scheduler.ShowScheduler/CreateMovieDaySchedule"
+	)
+
+	tests := []struct {
+		name      string
+		method    string
+		token     string
+		req       interface{}
+		wantCode  codes.Code
+		wantCalls int
+		wantUser  string
+		wantErr   codes.Code // error kept in the handler context
+	}{
+		{
+			name:   "public method without token",
+			method: publicMethod,
+			req:    &testRequest{},
+		},
+		{
+			name:   "public method skips authentication outside the draft",
+			method: publicMethod,
+			token:  "valid",
+			req:    &testRequest{},
+		},
+		{
+			name:   "public draft request without token",
+			method: publicMethod,
+			req:    &testRequest{draft: true},
+		},
+		{
+			name:      "public draft request",
+			method:    publicMethod,
+			token:     "valid",
+			req:       &testRequest{draft: true},
+			wantCalls: 1,
+			wantUser:  "u1",
+		},
+		{
+			name:      "public draft request with a bad token",
+			method:    publicMethod,
+			token:     "bad",
+			req:       &testRequest{draft: true},
+			wantCalls: 1,
+			wantErr:   codes.Unauthenticated,
+		},
+		{
+			name:      "private method",
+			method:    privateMethod,
+			token:     "valid",
+			req:       &testRequest{},
+			wantCalls: 1,
+			wantUser:  "u1",
+		},
+		{
+			name:      "private method without token",
+			method:    privateMethod,
+			req:       &testRequest{},
+			wantCode:  codes.Unauthenticated,
+			wantCalls: 1,
+		},
+		{
+			name:      "private method with a bad token",
+			method:    privateMethod,
+			token:     "bad",
+			req:       &testRequest{},
+			wantCode:  codes.Unauthenticated,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAuth := &fakeAuthenticator{}
+			unary, _ := AddAuthentication(fakeAuth, publicMethod)
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+
+			handled := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled = true
+				claims, ok := auth.FromContext(ctx)
+				if tt.wantUser == "" && ok {
+					t.Errorf("handler got caller %q, want none", claims.UserID)
+				}
+				if tt.wantUser != "" && (!ok || claims.UserID != tt.wantUser) {
+					t.Errorf("handler got caller %v, want %q", claims, tt.wantUser)
+				}
+				if code := status.Code(auth.ErrorFromContext(ctx)); code != tt.wantErr {
+					t.Errorf("context error code = %s, want %s", code, tt.wantErr)
+				}
+				return nil, nil
+			}
+
+			_, err := unary[0](ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("interceptor error = %v, want code %s", err, tt.wantCode)
+			}
+			if handled != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %t, want %t", handled, tt.wantCode == codes.OK)
+			}
+			if fakeAuth.calls != tt.wantCalls {
+				t.Errorf("Authenticate() calls = %d, want %d", fakeAuth.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// testStream is a server stream with a fixed context
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *testStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestAuthenticationStream(t *testing.T) {
+	const (
+		publicMethod  = "/scheduler.ShowScheduler/WatchSchedule"
+		privateMethod = "/scheduler.ShowScheduler/WatchDraft"
+	)
+
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		wantCode codes.Code
+		wantUser string
+		wantErr  codes.Code
+	}{
+		{name: "public method without token", method: publicMethod},
+		{name: "public method", method: publicMethod, token: "valid", wantUser: "u1"},
+		{name: "public method with a bad token", method: publicMethod, token: "bad", wantErr: codes.Unauthenticated},
+		{name: "private method", method: privateMethod, token: "valid", wantUser: "u1"},
+		{name: "private method without token", method: privateMethod, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stream := AddAuthentication(&fakeAuthenticator{}, publicMethod)
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+
+			handler := func(srv interface{}, ss grpc.ServerStream) error {
+				claims, ok := auth.FromContext(ss.Context())
+				if ok != (tt.wantUser != "") || (ok && claims.UserID != tt.wantUser) {
+					t.Errorf("handler got caller %v, want %q", claims, tt.wantUser)
+				}
+				if code := status.Code(auth.ErrorFromContext(ss.Context())); code != tt.wantErr {
+					t.Errorf("context error code = %s, want %s", code, tt.wantErr)
+				}
+				return nil
+			}
+
+			err := stream[0](nil, &testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("interceptor error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
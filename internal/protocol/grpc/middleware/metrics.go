@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Methods are only those registered on the server or called on upstream services so labels stay bounded
+var (
+	serverHandledCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, regardless of success or failure.",
+		},
+		[]string{"grpc_service", "grpc_method", "grpc_code"},
+	)
+
+	serverHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Histogram of response latency of RPCs handled by the server.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"grpc_service", "grpc_method"},
+	)
+
+	clientHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_client_handling_seconds",
+			Help:    "Histogram of response latency of RPCs made to upstream services.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"upstream", "grpc_method", "grpc_code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		serverHandledCounter,
+		serverHandlingSeconds,
+		clientHandlingSeconds,
+	)
+}
+
+// splits /package.Service/Method into service and method names.
+// Names of any other form are labelled unknown so that they share one label
+func splitMethodName(fullMethodName string) (string, string) {
+	parts := strings.Split(strings.TrimPrefix(fullMethodName, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "unknown", "unknown"
+	}
+	return parts[0], parts[1]
+}
+
+func observeServer(fullMethodName string, start time.Time, err error) {
+	service, method := splitMethodName(fullMethodName)
+	serverHandledCounter.WithLabelValues(service, method, status.Code(err).String()).Inc()
+	serverHandlingSeconds.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+}
+
+// AddMetrics returns interceptors that record Prometheus metrics for every RPC handled by the server
+func AddMetrics() ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	unary := func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeServer(info.FullMethod, start, err)
+		return resp, err
+	}
+
+	stream := func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeServer(info.FullMethod, start, err)
+		return err
+	}
+
+	return []grpc.UnaryServerInterceptor{unary}, []grpc.StreamServerInterceptor{stream}
+}
+
+// UnaryClientMetrics returns a client interceptor that records latency of calls to an upstream service
+func UnaryClientMetrics(upstream string) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		fullMethodName string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		start := time.Now()
+		err := invoker(ctx, fullMethodName, req, reply, cc, opts...)
+		_, method := splitMethodName(fullMethodName)
+		clientHandlingSeconds.WithLabelValues(
+			upstream, method, status.Code(err).String(),
+		).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
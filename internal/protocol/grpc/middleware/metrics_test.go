@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSplitMethodName(t *testing.T) {
+	tests := []struct {
+		fullMethodName string
+		wantService    string
+		wantMethod     string
+	}{
+		{"/rupacinema.movie.ShowScheduler/VoteUpMovie", "rupacinema.movie.ShowScheduler", "VoteUpMovie"},
+		{"rupacinema.movie.ShowScheduler/VoteUpMovie", "rupacinema.movie.ShowScheduler", "VoteUpMovie"},
+		{"/rupacinema.movie.ShowScheduler/VoteUpMovie/extra", "unknown", "unknown"},
+		{"/rupacinema.movie.ShowScheduler/", "unknown", "unknown"},
+		{"//VoteUpMovie", "unknown", "unknown"},
+		{"/VoteUpMovie", "unknown", "unknown"},
+		{"", "unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullMethodName, func(t *testing.T) {
+			service, method := splitMethodName(tt.fullMethodName)
+			if service != tt.wantService || method != tt.wantMethod {
+				t.Errorf("splitMethodName() = %q, %q, want %q, %q", service, method, tt.wantService, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	unary, _ := AddMetrics()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no show")
+	}
+
+	notFound := testutil.ToFloat64(serverHandledCounter.WithLabelValues("test.Service", "Get", "NotFound"))
+	unknown := testutil.ToFloat64(serverHandledCounter.WithLabelValues("unknown", "unknown", "NotFound"))
+	series := testutil.CollectAndCount(serverHandledCounter)
+
+	for _, fullMethod := range []string{"/test.Service/Get", "/test.Service/Get", "bad", "/a/b/c", "/x/"} {
+		_, err := unary[0](context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("interceptor error = %v, want the handler error", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(serverHandledCounter.WithLabelValues("test.Service", "Get", "NotFound")); got != notFound+2 {
+		t.Errorf("handled Get = %v, want %v", got, notFound+2)
+	}
+	if got := testutil.ToFloat64(serverHandledCounter.WithLabelValues("unknown", "unknown", "NotFound")); got != unknown+3 {
+		t.Errorf("handled unknown methods = %v, want %v", got, unknown+3)
+	}
+	// Malformed method names share the unknown series instead of adding their own
+	if got := testutil.CollectAndCount(serverHandledCounter); got != series {
+		t.Errorf("%d handled series, want %d", got, series)
+	}
+}
+
+func TestUnaryClientMetrics(t *testing.T) {
+	interceptor := UnaryClientMetrics("movie")
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "movie service down")
+	}
+
+	series := testutil.CollectAndCount(clientHandlingSeconds)
+	for _, method := range []string{"/rupacinema.movie.MovieAPI/GetMovie", "/rupacinema.movie.MovieAPI/GetMovie"} {
+		err := interceptor(context.Background(), method, nil, nil, nil, invoker)
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("interceptor error = %v, want the invoker error", err)
+		}
+	}
+
+	// Both calls are observed in one series labelled with the upstream, method and code
+	if got := testutil.CollectAndCount(clientHandlingSeconds); got != series+1 {
+		t.Errorf("%d client series, want %d", got, series+1)
+	}
+}
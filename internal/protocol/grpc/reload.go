@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/service"
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// reconfigurable is implemented by services whose options can change while running
+type reconfigurable interface {
+	Reconfigure(service.Options) error
+}
+
+// watches the configuration and applies changed scheduler options to the running service.
+// Settings that cannot be changed while running are logged and ignored until restart.
+func watchConfig(ctx context.Context, cfg *config.Config, schedulingService interface{}) {
+	svc, ok := schedulingService.(reconfigurable)
+	if !ok {
+		return
+	}
+
+	current := cfg
+
+	err := config.Watch(ctx, cfg, func(newCfg *config.Config, err error) {
+		if err != nil {
+			logger.Log.Error("configuration not reloaded", zap.Error(err))
+			return
+		}
+
+		if fields := restartRequired(current, newCfg); len(fields) != 0 {
+			logger.Log.Warn(
+				"configuration changes that take effect after restart",
+				zap.Strings("fields", fields),
+			)
+		}
+
+		err = svc.Reconfigure(schedulerOptions(newCfg))
+		if err != nil {
+			logger.Log.Error("configuration change rejected", zap.Error(err))
+			return
+		}
+
+		current = newCfg
+
+		logger.Log.Info(
+			"configuration reloaded",
+			zap.Strings("screens", newCfg.Screens),
+			zap.Stringer("showtimes", &newCfg.Showtimes),
+			zap.Int("max movies voted", newCfg.MaxMoviesVoted),
+			zap.Duration("snapshot interval", newCfg.SnapshotInterval),
+			zap.Duration("movie refresh interval", newCfg.MovieRefreshInterval),
+			zap.Int("history size", newCfg.HistorySize),
+			zap.Int("history archive size", newCfg.HistoryArchiveSize),
+		)
+	})
+	if err != nil {
+		logger.Log.Error("failed to watch configuration", zap.Error(err))
+	}
+}
+
+// settings applied by Reconfigure
+var reloadableFields = map[string]bool{
+	"Screens":              true,
+	"Showtimes":            true,
+	"MaxMoviesVoted":       true,
+	"SnapshotInterval":     true,
+	"MovieRefreshInterval": true,
+	"HistorySize":          true,
+	"HistoryArchiveSize":   true,
+}
+
+// returns names of changed fields that only take effect after restart
+func restartRequired(oldCfg, newCfg *config.Config) []string {
+	fields := make([]string, 0)
+	oldVal, newVal := reflect.ValueOf(oldCfg).Elem(), reflect.ValueOf(newCfg).Elem()
+	for i := 0; i < oldVal.NumField(); i++ {
+		field := oldVal.Type().Field(i)
+		if field.PkgPath != "" || reloadableFields[field.Name] {
+			continue
+		}
+		if !reflect.DeepEqual(oldVal.Field(i).Interface(), newVal.Field(i).Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+)
+
+func TestRestartRequired(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *config.Config)
+		want   []string
+	}{
+		{name: "unchanged", change: func(cfg *config.Config) {}},
+		{
+			name: "reloadable settings",
+			change: func(cfg *config.Config) {
+				cfg.Screens = []string{"Screen 1", "Screen 2"}
+				cfg.MaxMoviesVoted++
+				cfg.SnapshotInterval += time.Minute
+				cfg.HistorySize++
+				cfg.HistoryArchiveSize++
+			},
+		},
+		{
+			name:   "port",
+			change: func(cfg *config.Config) { cfg.GRPCPort = ":7000" },
+			want:   []string{"GRPCPort"},
+		},
+		{
+			name: "port and screens",
+			change: func(cfg *config.Config) {
+				cfg.Screens = []string{"Screen 2"}
+				cfg.GRPCPort = ":7000"
+				cfg.LogLevel = 1
+				cfg.HistorySize = 0
+			},
+			want: []string{"GRPCPort", "LogLevel"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCfg := &config.Config{
+				GRPCPort: ":5600", Screens: []string{"Screen 1"}, MaxMoviesVoted: 3, HistorySize: 100, HistoryArchiveSize: 1000,
+			}
+			newCfg := *oldCfg
+			tt.change(&newCfg)
+
+			got := restartRequired(oldCfg, &newCfg)
+			if len(got) != len(tt.want) {
+				t.Fatalf("restartRequired() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("restartRequired() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -5,19 +5,30 @@ import (
 	"fmt"
 	"github.com/gidyon/rupacinema/account/pkg/api"
 	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/idempotency"
+	"github.com/gidyon/rupacinema/scheduling/internal/protocol"
+	"github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc/middleware"
 	"github.com/gidyon/rupacinema/scheduling/internal/service"
 	"github.com/gidyon/rupacinema/scheduling/pkg/api"
 	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"strings"
 )
 
-// Creates the service
-func createSchedulerServer(
-	ctx context.Context, cfg *config.Config,
-) (scheduler.ShowSchedulerServer, error) {
+// clients to the remote services used by the scheduler
+type remoteServices struct {
+	accountServiceConn   *grpc.ClientConn
+	movieServiceConn     *grpc.ClientConn
+	accountServiceClient account.AccountAPIClient
+	movieAPIClient       movie.MovieAPIClient
+}
 
-	// Remote services
+// Dials the remote services
+func dialRemoteServices(
+	ctx context.Context, cfg *config.Config,
+) (*remoteServices, error) {
 	// MovieService service
 	movieServiceConn, err := dialDialMovieServiceService(ctx, cfg)
 	if err != nil {
@@ -36,19 +47,76 @@ func createSchedulerServer(
 		accountServiceConn.Close()
 	}()
 
+	return &remoteServices{
+		accountServiceConn:   accountServiceConn,
+		movieServiceConn:     movieServiceConn,
+		accountServiceClient: account.NewAccountAPIClient(accountServiceConn),
+		movieAPIClient:       movie.NewMovieAPIClient(movieServiceConn),
+	}, nil
+}
+
+// Creates the service. Results of idempotent requests kept in cache are saved with the schedule
+func createSchedulerServer(
+	ctx context.Context, cfg *config.Config, remote *remoteServices, cache *idempotency.Cache,
+) (scheduler.ShowSchedulerServer, error) {
+	opts := schedulerOptions(cfg)
+	opts.Idempotency = cache
+
 	return service.NewShowScheduler(
 		ctx,
-		account.NewAccountAPIClient(accountServiceConn),
-		movie.NewMovieAPIClient(movieServiceConn),
+		remote.movieAPIClient,
+		opts,
 	)
 }
 
+// converts configuration into options for the scheduler
+func schedulerOptions(cfg *config.Config) service.Options {
+	shows := make([]service.Show, 0, len(cfg.Showtimes))
+	for _, showtime := range cfg.Showtimes {
+		shows = append(shows, service.Show{ID: showtime.ID, PlayTime: showtime.PlayTime})
+	}
+
+	var store, idempotencyStore, draftStore, historyStore, ledgerStore, historyArchive service.SnapshotStore
+	switch strings.ToLower(cfg.StoreBackend) {
+	case "memory":
+		store = service.NewMemoryStore()
+		idempotencyStore = service.NewMemoryStore()
+		draftStore = service.NewMemoryStore()
+		historyStore = service.NewMemoryStore()
+		ledgerStore = service.NewMemoryStore()
+		historyArchive = service.NewMemoryStore()
+	default:
+		store = service.NewFileStore(cfg.SnapshotPath)
+		idempotencyStore = service.NewFileStore(cfg.SnapshotPath + ".idempotency")
+		draftStore = service.NewFileStore(cfg.SnapshotPath + ".draft")
+		historyStore = service.NewFileStore(cfg.SnapshotPath + ".history")
+		ledgerStore = service.NewFileStore(cfg.SnapshotPath + ".ledger")
+		historyArchive = service.NewFileStore(cfg.SnapshotPath + ".history-archive")
+	}
+
+	return service.Options{
+		Screens:              cfg.Screens,
+		Shows:                shows,
+		MaxMoviesVoted:       cfg.MaxMoviesVoted,
+		SnapshotInterval:     cfg.SnapshotInterval,
+		MovieRefreshInterval: cfg.MovieRefreshInterval,
+		Store:                store,
+		IdempotencyStore:     idempotencyStore,
+		DraftStore:           draftStore,
+		HistorySize:          cfg.HistorySize,
+		HistoryStore:         historyStore,
+		LedgerStore:          ledgerStore,
+		HistoryArchiveSize:   cfg.HistoryArchiveSize,
+		HistoryArchive:       historyArchive,
+	}
+}
+
 // creates a connection to the movie service
 func dialDialMovieServiceService(
 	ctx context.Context, cfg *config.Config,
 ) (*grpc.ClientConn, error) {
 
-	creds, err := credentials.NewClientTLSFromFile(cfg.MovieAPICertPath, "rupa-movie")
+	transportCreds, err := upstreamCredentials(cfg, cfg.MovieAPICAPath, "rupa-movie")
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +124,9 @@ func dialDialMovieServiceService(
 	return grpc.DialContext(
 		ctx,
 		cfg.MovieAPIAddress+cfg.MovieAPIPort,
-		grpc.WithTransportCredentials(creds),
+		transportCreds,
+		grpc.WithUnaryInterceptor(middleware.UnaryClientMetrics("movie")),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 }
 
@@ -65,7 +135,7 @@ func dialAccountService(
 	ctx context.Context, cfg *config.Config,
 ) (*grpc.ClientConn, error) {
 
-	creds, err := credentials.NewClientTLSFromFile(cfg.AccountServiceCertPath, "rupa-account")
+	transportCreds, err := upstreamCredentials(cfg, cfg.AccountServiceCAPath, "rupa-account")
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +143,22 @@ func dialAccountService(
 	return grpc.DialContext(
 		ctx,
 		cfg.AccountServiceAddress+cfg.AccountServicePort,
-		grpc.WithTransportCredentials(creds),
+		transportCreds,
+		grpc.WithUnaryInterceptor(middleware.UnaryClientMetrics("account")),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 }
+
+// returns TLS credentials for an upstream service, or none in insecure mode
+func upstreamCredentials(cfg *config.Config, caPath, serverName string) (grpc.DialOption, error) {
+	if cfg.Insecure {
+		return grpc.WithInsecure(), nil
+	}
+
+	tlsConfig, err := protocol.UpstreamTLS(caPath, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
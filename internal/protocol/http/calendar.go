@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/calendar"
+	grpc_server "github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc"
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"go.uber.org/zap"
+)
+
+const calendarPath = "/api/scheduler/calendar"
+
+// registerCalendar adds iCalendar feeds of the programme to mux:
+//
+//	/api/scheduler/calendar.ics                      - the whole week
+//	/api/scheduler/calendar/screens/{screen}.ics     - a screen
+//	/api/scheduler/calendar/movies/{movie_id}.ics    - a movie
+func registerCalendar(mux *http.ServeMux, grpcServer *grpc_server.Server, loc *time.Location) {
+	mux.Handle(calendarPath+".ics", calendarFeed(grpcServer, loc))
+	mux.Handle(calendarPath+"/", calendarFeed(grpcServer, loc))
+}
+
+func calendarFeed(grpcServer *grpc_server.Server, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		opts := calendar.Options{
+			Name:     "Rupa Cinema",
+			Location: loc,
+			Now:      time.Now(),
+			OnSkip: func(slot snapshot.Slot, err error) {
+				logger.Log.Warn("show left out of calendar feed", zap.Stringer("show", slot), zap.Error(err))
+			},
+		}
+
+		if r.URL.Path != calendarPath+".ics" {
+			kind, name, ok := parseFeedPath(strings.TrimPrefix(r.URL.Path, calendarPath+"/"))
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			switch kind {
+			case "screens":
+				opts.Screen = name
+				opts.Name += " - " + name
+			case "movies":
+				opts.MovieID = name
+			}
+		}
+
+		weeklySchedule, err := grpcServer.WeeklySchedule()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		err = calendar.Render(buf, weeklySchedule, opts)
+		if err != nil {
+			logger.Log.Error("failed to render calendar feed", zap.String("path", r.URL.Path), zap.Error(err))
+			http.Error(w, "failed to render calendar", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(buf.Bytes())
+	}
+}
+
+// splits screens/{screen}.ics or movies/{movie_id}.ics
+func parseFeedPath(path string) (string, string, bool) {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 || !strings.HasSuffix(parts[1], ".ics") {
+		return "", "", false
+	}
+
+	name := strings.TrimSuffix(parts[1], ".ics")
+	if name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+
+	switch parts[0] {
+	case "screens", "movies":
+		return parts[0], name, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/proto"
+)
+
+// setETag sets the ETag header of responses that carry a version.
+// The entity tag is the version followed by the votes version e.g "3.42", so cached reads change with votes
+// while If-Match checks only the version
+func setETag(ctx context.Context, w http.ResponseWriter, resp proto.Message) error {
+	var version, votesVersion int64
+	switch schedule := resp.(type) {
+	case *scheduler.ShowSchedule:
+		version, votesVersion = schedule.GetVersion(), schedule.GetVotesVersion()
+	case *scheduler.ScreensSchedule:
+		version, votesVersion = schedule.GetVersion(), schedule.GetVotesVersion()
+	}
+	if version != 0 {
+		w.Header().Set("ETag", fmt.Sprintf(`"%d.%d"`, version, votesVersion))
+	}
+	return nil
+}
+
+// notModified answers GET and HEAD requests with 304 Not Modified
+// when the If-None-Match header matches the ETag of the response
+func notModified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		if ifNoneMatch == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		if buf.status == http.StatusOK && etagMatch(ifNoneMatch, w.Header().Get("ETag")) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(buf.status)
+		w.Write(buf.body.Bytes())
+	})
+}
+
+// etagMatch reports whether an If-None-Match header lists etag, using weak comparison
+func etagMatch(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse holds back the status and body of a response until the handler returns
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (buf *bufferedResponse) WriteHeader(status int) {
+	buf.status = status
+}
+
+func (buf *bufferedResponse) Write(b []byte) (int, error) {
+	return buf.body.Write(b)
+}
+
+// Flush is a no-op; the response is written once the handler returns
+func (buf *bufferedResponse) Flush() {}
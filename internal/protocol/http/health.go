@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"net/http"
+)
+
+// readiness is implemented by the gRPC server health checker
+type readiness interface {
+	Ready() error
+}
+
+// healthz reports that the process is alive and serving HTTP
+func healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok"))
+}
+
+// readyz reports whether the schedule is loaded and remote services are reachable
+func readyz(healthChecker readiness) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := healthChecker.Ready(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+}
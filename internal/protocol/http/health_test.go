@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeReadiness reports readiness like the gRPC server health checker
+type fakeReadiness struct {
+	err error
+}
+
+func (ready fakeReadiness) Ready() error {
+	return ready.err
+}
+
+func TestHealthHandlers(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{name: "ready", path: "/readyz", wantCode: http.StatusOK, wantBody: "ok"},
+		{
+			name:     "not ready",
+			path:     "/readyz",
+			err:      errors.New("connection to movie service is TRANSIENT_FAILURE"),
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "connection to movie service is TRANSIENT_FAILURE",
+		},
+		{
+			name:     "draining",
+			path:     "/readyz",
+			err:      errors.New("scheduling service is shutting down"),
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "scheduling service is shutting down",
+		},
+		{
+			name:     "alive while not ready",
+			path:     "/healthz",
+			err:      errors.New("scheduling service is shutting down"),
+			wantCode: http.StatusOK,
+			wantBody: "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthz", healthz)
+			mux.HandleFunc("/readyz", readyz(fakeReadiness{err: tt.err}))
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
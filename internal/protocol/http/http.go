@@ -2,7 +2,7 @@ package rest
 
 import (
 	"context"
-	"crypto/tls"
+	"fmt"
 	"github.com/gidyon/rupacinema/scheduling/internal/protocol"
 	"github.com/gidyon/rupacinema/scheduling/pkg/api"
 	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
@@ -10,13 +10,14 @@ import (
 	"google.golang.org/grpc/credentials"
 	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"strings"
+	"sync"
 
 	grpc_server "github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc"
 	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+	"github.com/gidyon/rupacinema/scheduling/pkg/tracing"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
@@ -27,24 +28,34 @@ func createRESTMux(
 	opts ...runtime.ServeMuxOption,
 ) (*runtime.ServeMux, error) {
 
-	tlsConfig, err := protocol.ClientTLS()
-	if err != nil {
-		return nil, err
+	dopts := []grpc.DialOption{
+		// Propagates the span started by the HTTP handler to the gRPC server
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	}
 
-	dopts := []grpc.DialOption{
-		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	if cfg.Insecure {
+		dopts = append(dopts, grpc.WithInsecure())
+	} else {
+		tlsConfig, err := protocol.ClientTLS()
+		if err != nil {
+			return nil, err
+		}
+		dopts = append(dopts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
 	// gwmux := runtime.NewServeMux()
-	gwmux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard,
+	opts = append(opts, runtime.WithMarshalerOption(runtime.MIMEWildcard,
 		&runtime.JSONPb{OrigName: true, EmitDefaults: true}))
+	// Versions of shows and days are sent as ETag headers
+	opts = append(opts, runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher))
+	opts = append(opts, runtime.WithForwardResponseOption(setETag))
+	gwmux := runtime.NewServeMux(opts...)
 
 	// Register the reverse proxy server
-	err = scheduling.RegisterBookingServiceHandlerFromEndpoint(
+	err := scheduler.RegisterShowSchedulerHandlerFromEndpoint(
 		ctx,
 		gwmux,
-		cfg.GRPCPort,
+		gatewayUpstream(cfg),
 		dopts,
 	)
 	if err != nil {
@@ -54,91 +65,167 @@ func createRESTMux(
 	return gwmux, nil
 }
 
-// Serve serves both GRPC and HTTP on the same port
+// gatewayUpstream returns the address of the gRPC server for the REST gateway.
+// The server certificate is verified against the service certificate, so the upstream must serve it too.
+func gatewayUpstream(cfg *config.Config) string {
+	if upstream := strings.Trim(cfg.GatewayUpstream, " "); upstream != "" {
+		return upstream
+	}
+	if strings.HasPrefix(cfg.GRPCPort, ":") {
+		return "localhost" + cfg.GRPCPort
+	}
+	return cfg.GRPCPort
+}
+
+// headers forwarded to the service under their own names instead of with the grpcgateway- prefix.
+// If-Match is the version a mutation expects the show to be at
+var forwardedHeaders = map[string]string{
+	"If-Match":        "if-match",
+	"Idempotency-Key": "idempotency-key",
+}
+
+// incomingHeaderMatcher forwards If-Match and Idempotency-Key headers as they are
+// and other headers as the gateway does by default
+func incomingHeaderMatcher(key string) (string, bool) {
+	if name, ok := forwardedHeaders[http.CanonicalHeaderKey(key)]; ok {
+		return name, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// serve serves every listener until ctx is cancelled or one of them fails.
+// The server then stops accepting requests and waits up to cfg.ShutdownTimeout
+// for in-flight requests before the schedule is flushed.
 func serve(
 	ctx context.Context,
 	cfg *config.Config,
-	grpcServer *grpc.Server,
-	httpMux *http.ServeMux,
+	grpcServer *grpc_server.Server,
+	listeners []*listener,
 ) error {
-	// the grpcHandlerFunc takes an grpc server and a http muxer and will
-	// route the request to the right place at runtime.
-	mergeHandler := grpcHandlerFunc(grpcServer, httpMux)
-
-	// Http server tls config
-	tlsConfig, err := protocol.HTTPServerTLS()
-	if err != nil {
-		return err
+	// Bind every address before serving so that a taken port fails startup
+	netListeners := make([]net.Listener, 0, len(listeners))
+	for _, l := range listeners {
+		lis, err := net.Listen("tcp", l.addr)
+		if err != nil {
+			for _, netListener := range netListeners {
+				netListener.Close()
+			}
+			return fmt.Errorf("failed to listen for %s on %s: %v", l.name, l.addr, err)
+		}
+		netListeners = append(netListeners, lis)
 	}
 
-	srv := &http.Server{
-		Addr:    cfg.GRPCPort,
-		Handler: mergeHandler,
+	errChan := make(chan error, len(listeners))
+	for i, l := range listeners {
+		logger.Log.Info(
+			fmt.Sprintf("<%s> server for scheduling service running", l.name),
+			zap.String("address", l.addr),
+			zap.Bool("insecure", cfg.Insecure),
+		)
+		go func(l *listener, lis net.Listener) {
+			errChan <- l.serve(lis)
+		}(l, netListeners[i])
 	}
 
-	lis, err := net.Listen("tcp", cfg.GRPCPort)
-	if err != nil {
-		return err
+	var serveErr error
+	select {
+	case serveErr = <-errChan:
+		logger.Log.Error("server stopped unexpectedly", zap.Error(serveErr))
+	case <-ctx.Done():
 	}
 
-	logger.Log.Info("<gRPC and REST> server for scheduling service running", zap.String("gRPC Port", cfg.GRPCPort))
+	logger.Log.Warn("shutting down scheduling service....", zap.Duration("drain timeout", cfg.ShutdownTimeout))
+
+	// Stop receiving traffic from load balancers
+	grpcServer.HealthChecker.Drain()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and wait for in-flight REST and gRPC requests
+	wg := &sync.WaitGroup{}
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l *listener) {
+			defer wg.Done()
+			err := l.shutdown(shutdownCtx)
+			if err != nil {
+				logger.Log.Error(
+					"in-flight requests did not finish before drain timeout",
+					zap.String("server", l.name),
+					zap.Error(err),
+				)
+				l.close()
+			}
+		}(l)
+	}
+	wg.Wait()
+	grpcServer.Stop()
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		for range c {
-			logger.Log.Warn("shutting down scheduling service....")
-			srv.Shutdown(ctx)
+	// Flush the schedule now that no request can mutate it
+	err := grpcServer.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write final snapshot: %v", err)
+	}
 
-			<-ctx.Done()
-		}
-	}()
+	logger.Log.Info("scheduling service stopped")
 
-	return srv.Serve(tls.NewListener(lis, tlsConfig))
+	return serveErr
 }
 
-// Serve serves both GRPC and HTTP on the same port
+// Serve serves GRPC, REST and admin endpoints until ctx is cancelled.
+// They share the gRPC port unless separate REST and admin ports are configured.
 func Serve(
 	ctx context.Context,
 	cfg *config.Config,
 ) error {
 	// Initialize paths to cert and key
 	protocol.SetKeyAndCertPaths(cfg.TLSKeyPath, cfg.TLSCertPath)
+	err := protocol.SetClientAuth(cfg.TLSClientAuth, cfg.TLSClientCAPath)
+	if err != nil {
+		return err
+	}
+
+	// Tracing
+	shutdownTracing, err := tracing.Init(ctx, cfg.TraceExporter, cfg.OTLPEndpoint)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	// Context for the service and its remote connections. It outlives ctx so that
+	// in-flight requests can complete while the server is draining.
+	serviceCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// gRPC server
-	gRPCServer, err := grpc_server.CreateGRPCServer(ctx, cfg)
+	gRPCServer, err := grpc_server.CreateGRPCServer(serviceCtx, cfg)
 	if err != nil {
 		return err
 	}
 
 	// REST muxer
-	restMux, err := createRESTMux(ctx, cfg)
+	restMux, err := createRESTMux(serviceCtx, cfg)
 	if err != nil {
 		return err
 	}
 
-	// register root Http multiplexer (mux)
-	mux := http.NewServeMux()
-
-	// register the gateway mux onto the root path.
-	mux.Handle("/", restMux)
-
-	// Test endpoint
-	mux.HandleFunc("/api/hello", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("schedulings will work!"))
-	})
+	listeners, err := createListeners(cfg, gRPCServer, restMux)
+	if err != nil {
+		return err
+	}
 
-	return serve(ctx, cfg, gRPCServer, mux)
+	return serve(ctx, cfg, gRPCServer, listeners)
 }
 
-// grpcHandlerFunc returns an http.Handler that delegates to grpcServer on incoming gRPC
+// grpcHandlerFunc returns an http.Handler that delegates to grpcHandler on incoming gRPC
 // connections or otherHandler otherwise. Copied from cockroachdb.
-func grpcHandlerFunc(grpcServer *grpc.Server, otherHandler http.Handler) http.Handler {
+func grpcHandlerFunc(grpcHandler http.Handler, otherHandler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// TODO(tamird): point to merged gRPC code rather than a PR.
 		// This is a partial recreation of gRPC's internal checks https://github.com/grpc/grpc-go/pull/514/files#diff-95e9a25b738459a2d3030e1e6fa2a718R61
 		if r.ProtoMajor == 2 && strings.Contains(r.Header.Get("Content-Type"), "application/grpc") {
-			grpcServer.ServeHTTP(w, r)
+			grpcHandler.ServeHTTP(w, r)
 		} else {
 			otherHandler.ServeHTTP(w, r)
 		}
@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+)
+
+func TestGatewayUpstream(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{name: "gRPC port", cfg: config.Config{GRPCPort: ":5600"}, want: "localhost:5600"},
+		{name: "gRPC address", cfg: config.Config{GRPCPort: "10.0.0.5:5600"}, want: "10.0.0.5:5600"},
+		{
+			name: "configured upstream",
+			cfg:  config.Config{GRPCPort: ":5600", GatewayUpstream: "scheduling.internal:5600"},
+			want: "scheduling.internal:5600",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gatewayUpstream(&tt.cfg); got != tt.want {
+				t.Errorf("gatewayUpstream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
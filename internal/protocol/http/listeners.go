@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/protocol"
+	grpc_server "github.com/gidyon/rupacinema/scheduling/internal/protocol/grpc"
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc/codes"
+)
+
+// listener is an address serving one or more of the gRPC, REST and admin surfaces
+type listener struct {
+	name     string
+	addr     string
+	serve    func(net.Listener) error
+	shutdown func(context.Context) error
+	close    func()
+}
+
+// createListeners returns the listeners for the configured ports.
+// By default gRPC, REST and admin endpoints are multiplexed on the gRPC port.
+func createListeners(
+	cfg *config.Config,
+	grpcServer *grpc_server.Server,
+	restMux *runtime.ServeMux,
+) ([]*listener, error) {
+	// Liveness and readiness probes and Prometheus metrics
+	adminMux := http.NewServeMux()
+	adminMux.HandleFunc("/healthz", healthz)
+	adminMux.HandleFunc("/readyz", readyz(grpcServer.HealthChecker))
+	adminMux.Handle("/metrics", promhttp.Handler())
+
+	// register root Http multiplexer (mux)
+	mux := http.NewServeMux()
+
+	// register the gateway mux onto the root path.
+	// Spans for REST requests start here and continue through the gateway.
+	// Reads whose ETag matches If-None-Match are answered with 304 Not Modified
+	mux.Handle("/", otelhttp.NewHandler(notModified(restMux), "scheduling-gateway"))
+
+	// iCalendar feeds of the programme
+	loc, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	registerCalendar(mux, grpcServer, loc)
+
+	listeners := make([]*listener, 0, 3)
+
+	if cfg.AdminPort == "" {
+		mux.Handle("/healthz", adminMux)
+		mux.Handle("/readyz", adminMux)
+		mux.Handle("/metrics", adminMux)
+	} else {
+		// Internal traffic only, bound to the admin host if one is configured
+		listeners = append(listeners, httpListener("admin", cfg.AdminHost+cfg.AdminPort, adminMux, nil))
+	}
+
+	if cfg.RESTPort == "" || cfg.RESTPort == cfg.GRPCPort {
+		// the grpcHandlerFunc takes an grpc server and a http muxer and will
+		// route the request to the right place at runtime.
+		var grpcHandler http.Handler = grpcServer.Server
+		if !cfg.Insecure && protocol.ClientCertRequired() {
+			// REST clients share the TLS config, so strict mutual TLS is enforced per gRPC request
+			grpcHandler = requireClientCert(grpcHandler)
+		}
+		mergeHandler := grpcHandlerFunc(grpcHandler, mux)
+
+		if cfg.Insecure {
+			// HTTP/2 without TLS so that gRPC can share the port with REST
+			return append(listeners, httpListener(
+				"gRPC and REST", cfg.GRPCPort, h2c.NewHandler(mergeHandler, &http2.Server{}), nil,
+			)), nil
+		}
+
+		// Http server tls config
+		tlsConfig, err := protocol.HTTPServerTLS()
+		if err != nil {
+			return nil, err
+		}
+
+		return append(listeners, httpListener("gRPC and REST", cfg.GRPCPort, mergeHandler, tlsConfig)), nil
+	}
+
+	// gRPC server has its own TLS credentials unless insecure
+	listeners = append(listeners, &listener{
+		name:  "gRPC",
+		addr:  cfg.GRPCPort,
+		serve: grpcServer.Serve,
+		shutdown: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		close: grpcServer.Stop,
+	})
+
+	var tlsConfig *tls.Config
+	if !cfg.Insecure {
+		var err error
+		tlsConfig, err = protocol.RESTServerTLS()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(listeners, httpListener("REST", cfg.RESTPort, mux, tlsConfig)), nil
+}
+
+// httpListener serves handler over TLS, or plaintext if tlsConfig is nil
+func httpListener(name, addr string, handler http.Handler, tlsConfig *tls.Config) *listener {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	return &listener{
+		name: name,
+		addr: addr,
+		serve: func(lis net.Listener) error {
+			if tlsConfig != nil {
+				lis = tls.NewListener(lis, tlsConfig)
+			}
+			return srv.Serve(lis)
+		},
+		shutdown: srv.Shutdown,
+		close: func() {
+			srv.Close()
+		},
+	}
+}
+
+// requireClientCert rejects gRPC requests on connections without a verified client certificate
+func requireClientCert(grpcHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			// Trailers-only gRPC response
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unauthenticated)))
+			w.Header().Set("Grpc-Message", "client certificate required")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		grpcHandler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"google.golang.org/grpc"
+)
+
+func TestRequireClientCert(t *testing.T) {
+	tests := []struct {
+		name       string
+		tls        *tls.ConnectionState
+		wantServed bool
+	}{
+		{name: "plaintext"},
+		{name: "no client certificate", tls: &tls.ConnectionState{}},
+		{
+			name:       "verified client certificate",
+			tls:        &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}},
+			wantServed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served := false
+			handler := requireClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/scheduler.ShowScheduler/GetSchedule", nil)
+			r.Header.Set("Content-Type", "application/grpc")
+			r.TLS = tt.tls
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if served != tt.wantServed {
+				t.Fatalf("served = %t, want %t", served, tt.wantServed)
+			}
+			if !tt.wantServed && w.Header().Get("Grpc-Status") != "16" {
+				t.Errorf("Grpc-Status = %q, want 16 (Unauthenticated)", w.Header().Get("Grpc-Status"))
+			}
+		})
+	}
+}
+
+func TestInsecureSharedPort(t *testing.T) {
+	server := startTestServer(t)
+
+	// gRPC over cleartext HTTP/2
+	conn, err := grpc.Dial(server.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("grpc.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	showSchedule, err := scheduler.NewShowSchedulerClient(conn).GetShowSchedule(ctx, &scheduler.GetShowScheduleRequest{
+		WeekDay: 1, Show: 1, Screen: "Screen 1",
+	})
+	if err != nil {
+		t.Fatalf("GetShowSchedule() over gRPC failed: %v", err)
+	}
+	if showSchedule.GetPlayTime() != "11am" {
+		t.Errorf("play time over gRPC = %q, want 11am", showSchedule.GetPlayTime())
+	}
+
+	// REST over plain HTTP/1.1, through the gateway to the gRPC server on the same port
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{path: "/healthz", wantBody: "ok"},
+		{path: "/api/scheduler/show?week_day=1&show=1&screen=Screen%201", wantBody: `"play_time":"11am"`},
+	}
+	for _, tt := range tests {
+		res, err := http.Get("http://" + server.addr + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tt.path, err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tt.path, err)
+		}
+		if res.StatusCode != http.StatusOK || res.ProtoMajor != 1 {
+			t.Errorf("GET %s = %s %s, want 200 over HTTP/1.1", tt.path, res.Proto, res.Status)
+		}
+		if !strings.Contains(string(body), tt.wantBody) {
+			t.Errorf("GET %s body = %s, want it to contain %s", tt.path, body, tt.wantBody)
+		}
+	}
+
+	err = server.stop(t)
+	if err != nil {
+		t.Fatalf("Serve() failed: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/config"
+)
+
+// testServer is the scheduling service served by Serve in insecure mode on a free local port
+type testServer struct {
+	addr         string
+	snapshotPath string
+	cancel       context.CancelFunc
+	done         chan error
+}
+
+// starts serving gRPC and REST on one port and waits until it accepts connections.
+// The movie and account services are not running, so only calls that do not reach them succeed.
+func startTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	cfg, err := config.Load([]string{
+		"-insecure",
+		"-grpc-port", addr,
+		"-snapshot-path", filepath.Join(dir, "snapshot"),
+		"-audit-log-path", filepath.Join(dir, "audit.log"),
+		"-trace-exporter", "none",
+		"-shutdown-timeout", "5s",
+	})
+	if err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &testServer{
+		addr:         addr,
+		snapshotPath: cfg.SnapshotPath,
+		cancel:       cancel,
+		done:         make(chan error, 1),
+	}
+	go func() {
+		server.done <- Serve(ctx, cfg)
+	}()
+	t.Cleanup(cancel)
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return server
+		}
+		select {
+		case err := <-server.done:
+			t.Fatalf("Serve() failed: %v", err)
+		default:
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("server not listening on %s: %v", addr, err)
+		}
+	}
+}
+
+// cancels the serve context and waits for Serve to return
+func (server *testServer) stop(t *testing.T) error {
+	t.Helper()
+	server.cancel()
+	select {
+	case err := <-server.done:
+		return err
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve() did not return after its context was cancelled")
+		return nil
+	}
+}
+
+func TestServeFlushesSnapshotOnShutdown(t *testing.T) {
+	server := startTestServer(t)
+
+	// Snapshots are otherwise written every few minutes
+	if _, err := os.Stat(server.snapshotPath); !os.IsNotExist(err) {
+		t.Fatalf("snapshot written before shutdown: %v", err)
+	}
+
+	err := server.stop(t)
+	if err != nil {
+		t.Fatalf("Serve() failed: %v", err)
+	}
+
+	bs, err := ioutil.ReadFile(server.snapshotPath)
+	if err != nil {
+		t.Fatalf("final snapshot not saved: %v", err)
+	}
+	if len(bs) == 0 {
+		t.Error("final snapshot is empty")
+	}
+
+	// Nothing listens once Serve has returned
+	if conn, err := net.Dial("tcp", server.addr); err == nil {
+		conn.Close()
+		t.Errorf("server still accepts connections on %s", server.addr)
+	}
+}
@@ -3,98 +3,210 @@ package protocol
 import (
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"strings"
+	"sync"
+)
+
+const (
+	// ClientAuthOptional verifies client certificates only when they are presented
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects clients that do not present a certificate signed by the client CA
+	ClientAuthRequire = "require"
 )
 
 var (
 	crt = "certs/cert.pem"
 	key = "certs/key.pem"
+	// CA bundle for client certificates, defaults to the service certificate
+	clientCA   = ""
+	clientAuth = ClientAuthOptional
+
+	mu             sync.Mutex
+	serverKeyPair  *keyPair
+	clientCertPool *certPool
 )
 
 // SetKeyAndCertPaths initializes path to private key and certificate
 func SetKeyAndCertPaths(keyPath, certPath string) {
-	if strings.Trim(keyPath, " ") != "" {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if strings.Trim(certPath, " ") != "" {
 		crt = certPath
 	}
-	if strings.Trim(certPath, " ") != "" {
+	if strings.Trim(keyPath, " ") != "" {
 		key = keyPath
 	}
+	serverKeyPair, clientCertPool = nil, nil
 }
 
-// GetCert returns a certificate pair, pool and an error
-func GetCert() (*tls.Certificate, *x509.CertPool, error) {
-	serverCrt, err := ioutil.ReadFile(crt)
-	if err != nil {
-		return nil, nil, fmt.Errorf("couldn't read file: %s", err)
+// SetClientAuth sets whether client certificates are required and the CA bundle they are verified with.
+// mode - one of optional or require
+// caPath - PEM encoded CA bundle; the service certificate is used when empty
+func SetClientAuth(mode, caPath string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	switch strings.ToLower(mode) {
+	case "", ClientAuthOptional:
+		clientAuth = ClientAuthOptional
+	case ClientAuthRequire:
+		clientAuth = ClientAuthRequire
+	default:
+		return fmt.Errorf("unknown client auth mode: %q", mode)
 	}
-	serverKey, err := ioutil.ReadFile(key)
-	if err != nil {
-		return nil, nil, fmt.Errorf("couldn't read file: %s", err)
+	clientCA = caPath
+	clientCertPool = nil
+
+	return nil
+}
+
+// returns the reloading key pair and client CA bundle for the service
+func serverCerts() (*keyPair, *certPool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if serverKeyPair == nil {
+		serverKeyPair = newKeyPair(crt, key)
 	}
+	if clientCertPool == nil {
+		caPath := clientCA
+		if strings.Trim(caPath, " ") == "" {
+			caPath = crt
+		}
+		clientCertPool = newCertPool(caPath)
+	}
+
+	return serverKeyPair, clientCertPool
+}
 
-	cert, err := tls.X509KeyPair(serverCrt, serverKey)
+// GetCert returns the current certificate pair, client CA pool and an error.
+// The files are read again whenever they change on disk.
+func GetCert() (*tls.Certificate, *x509.CertPool, error) {
+	kp, cp := serverCerts()
+
+	cert, err := kp.get()
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not load server key pair: %s", err)
+		return nil, nil, err
 	}
 
-	cp := x509.NewCertPool()
-	ok := cp.AppendCertsFromPEM(serverCrt)
-	if !ok {
-		return nil, nil, errors.New("bad certs")
+	pool, err := cp.get()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return &cert, cp, nil
+	return cert, pool, nil
 }
 
-// ClientTLS creates a tls config object for client
+// ClientTLS creates a tls config object for client connecting to this service.
+// The server certificate is verified against the service certificate instead of the host name,
+// and the service certificate is presented as the client certificate.
 func ClientTLS() (*tls.Config, error) {
-	cert, certPool, err := GetCert()
-	if err != nil {
+	kp, _ := serverCerts()
+	if _, err := kp.get(); err != nil {
 		return nil, err
 	}
 
 	tlsConfig := &tls.Config{
-		RootCAs:            certPool,
-		Certificates:       []tls.Certificate{*cert},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return kp.get()
+		},
+		// Verification is done by VerifyConnection so that rotated certificates are trusted
 		InsecureSkipVerify: true,
+		VerifyConnection:   verifyPeer(newCertPool(kp.certPath), ""),
 	}
 
 	return tlsConfig, nil
 }
 
-// GRPCServerTLS creates a tls config object for grpc server
-func GRPCServerTLS() (*tls.Config, error) {
-	cert, certPool, err := GetCert()
-	if err != nil {
+// UpstreamTLS creates a tls config object for connecting to an upstream service.
+// caPath - PEM encoded CA bundle that signs the upstream certificate
+// serverName - name the upstream certificate must be valid for
+func UpstreamTLS(caPath, serverName string) (*tls.Config, error) {
+	cp := newCertPool(caPath)
+	if _, err := cp.get(); err != nil {
 		return nil, err
 	}
 
+	kp, _ := serverCerts()
+
 	tlsConfig := &tls.Config{
-		ClientAuth:         tls.VerifyClientCertIfGiven,
-		Certificates:       []tls.Certificate{*cert},
-		ClientCAs:          certPool,
+		ServerName: serverName,
+		// Present the service certificate to upstreams that require client certificates
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return kp.get()
+		},
+		// Verification is done by VerifyConnection so that rotated CA bundles are trusted
 		InsecureSkipVerify: true,
+		VerifyConnection:   verifyPeer(cp, serverName),
 	}
 
 	return tlsConfig, nil
 }
 
-// HTTPServerTLS creates a tls config object for http server
-func HTTPServerTLS() (*tls.Config, error) {
-	cert, certPool, err := GetCert()
-	if err != nil {
+// ClientCertRequired reports whether SetClientAuth requires gRPC clients to present a certificate
+func ClientCertRequired() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return clientAuth == ClientAuthRequire
+}
+
+// returns the client certificate policy set by SetClientAuth
+func configuredClientAuth() tls.ClientAuthType {
+	if ClientCertRequired() {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.VerifyClientCertIfGiven
+}
+
+// serverTLS creates a tls config that serves the current certificate and verifies
+// client certificates against the current client CA bundle
+func serverTLS(clientAuthType tls.ClientAuthType, nextProtos ...string) (*tls.Config, error) {
+	if _, _, err := GetCert(); err != nil {
 		return nil, err
 	}
 
+	kp, cp := serverCerts()
+
 	tlsConfig := &tls.Config{
-		ClientAuth:   tls.VerifyClientCertIfGiven,
-		Certificates: []tls.Certificate{*cert},
-		ClientCAs:    certPool,
-		NextProtos:   []string{"h2"},
+		ClientAuth: clientAuthType,
+		NextProtos: nextProtos,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return kp.get()
+		},
+	}
+
+	// Pick up a rotated client CA bundle on every handshake
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := cp.get()
+		if err != nil {
+			return nil, err
+		}
+		config := tlsConfig.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = pool
+		return config, nil
 	}
 
 	return tlsConfig, nil
 }
+
+// GRPCServerTLS creates a tls config object for grpc server
+func GRPCServerTLS() (*tls.Config, error) {
+	return serverTLS(configuredClientAuth())
+}
+
+// HTTPServerTLS creates a tls config object for http server sharing its port with grpc server.
+// Client certificates are verified if given but never required, since REST clients share the port;
+// the handler must reject gRPC requests without a verified certificate when ClientCertRequired.
+func HTTPServerTLS() (*tls.Config, error) {
+	return serverTLS(tls.VerifyClientCertIfGiven, "h2")
+}
+
+// RESTServerTLS creates a tls config object for the REST gateway on its own port.
+// Client certificates are verified if given but never required.
+func RESTServerTLS() (*tls.Config, error) {
+	return serverTLS(tls.VerifyClientCertIfGiven, "h2", "http/1.1")
+}
@@ -0,0 +1,110 @@
+package protocol
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+)
+
+// resets the package settings changed by a test
+func resetSettings(t *testing.T) {
+	t.Cleanup(func() {
+		SetKeyAndCertPaths("certs/key.pem", "certs/cert.pem")
+		if err := SetClientAuth(ClientAuthOptional, ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestServerClientAuth(t *testing.T) {
+	resetSettings(t)
+	dir := tempDir(t)
+	certPath, keyPath := writeCert(t, dir, "scheduler")
+	caPath, _ := writeCert(t, dir, "clients")
+	SetKeyAndCertPaths(keyPath, certPath)
+
+	tests := []struct {
+		mode     string
+		wantGRPC tls.ClientAuthType
+	}{
+		{mode: "", wantGRPC: tls.VerifyClientCertIfGiven},
+		{mode: ClientAuthOptional, wantGRPC: tls.VerifyClientCertIfGiven},
+		{mode: "Require", wantGRPC: tls.RequireAndVerifyClientCert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if err := SetClientAuth(tt.mode, caPath); err != nil {
+				t.Fatalf("SetClientAuth() failed: %v", err)
+			}
+			if got := ClientCertRequired(); got != (tt.wantGRPC == tls.RequireAndVerifyClientCert) {
+				t.Errorf("ClientCertRequired() = %t", got)
+			}
+
+			grpcTLS, err := GRPCServerTLS()
+			if err != nil {
+				t.Fatalf("GRPCServerTLS() failed: %v", err)
+			}
+			if grpcTLS.ClientAuth != tt.wantGRPC {
+				t.Errorf("gRPC client auth = %v, want %v", grpcTLS.ClientAuth, tt.wantGRPC)
+			}
+
+			// REST clients are never required to present certificates, even when sharing the gRPC port
+			for name, serverTLS := range map[string]func() (*tls.Config, error){
+				"shared": HTTPServerTLS, "REST": RESTServerTLS,
+			} {
+				tlsConfig, err := serverTLS()
+				if err != nil {
+					t.Fatalf("%s server TLS failed: %v", name, err)
+				}
+				if tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven {
+					t.Errorf("%s client auth = %v, want %v", name, tlsConfig.ClientAuth, tls.VerifyClientCertIfGiven)
+				}
+			}
+
+			// Client certificates are verified against the client CA bundle
+			grpcTLS, err = grpcTLS.GetConfigForClient(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatalf("GetConfigForClient() failed: %v", err)
+			}
+			if grpcTLS.ClientCAs == nil || len(grpcTLS.ClientCAs.Subjects()) != 1 {
+				t.Errorf("client CA bundle was not loaded from %s", caPath)
+			}
+		})
+	}
+
+	if err := SetClientAuth("strict", ""); err == nil {
+		t.Error("SetClientAuth() accepted an unknown mode")
+	}
+}
+
+func TestUpstreamTLSClientCertificate(t *testing.T) {
+	resetSettings(t)
+	dir := tempDir(t)
+	caPath, _ := writeCert(t, dir, "rupa-account")
+	certPath, keyPath := writeCert(t, dir, "scheduler")
+
+	SetKeyAndCertPaths(keyPath, certPath)
+	tlsConfig, err := UpstreamTLS(caPath, "rupa-account")
+	if err != nil {
+		t.Fatalf("UpstreamTLS() failed: %v", err)
+	}
+	cert, err := tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil || len(cert.Certificate) == 0 {
+		t.Errorf("GetClientCertificate() = %v, %v, want the service certificate", cert, err)
+	}
+
+	// A key pair that cannot be loaded fails the handshake instead of presenting no certificate
+	SetKeyAndCertPaths(filepath.Join(dir, "missing-key.pem"), filepath.Join(dir, "missing.pem"))
+	tlsConfig, err = UpstreamTLS(caPath, "rupa-account")
+	if err != nil {
+		t.Fatalf("UpstreamTLS() failed: %v", err)
+	}
+	if _, err = tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{}); err == nil {
+		t.Error("GetClientCertificate() did not return the error loading the key pair")
+	}
+
+	if _, err = UpstreamTLS(filepath.Join(dir, "missing-ca.pem"), "rupa-account"); err == nil {
+		t.Error("UpstreamTLS() accepted a missing CA bundle")
+	}
+}
@@ -0,0 +1,172 @@
+package protocol
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// keyPair is a certificate and private key that is read again from disk whenever either file changes.
+// If a rotated pair cannot be loaded, the previous pair keeps being served.
+type keyPair struct {
+	certPath, keyPath string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func newKeyPair(certPath, keyPath string) *keyPair {
+	return &keyPair{certPath: certPath, keyPath: keyPath}
+}
+
+func (kp *keyPair) get() (*tls.Certificate, error) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+
+	certMod, err := modTime(kp.certPath)
+	if err != nil {
+		return kp.cached(err)
+	}
+	keyMod, err := modTime(kp.keyPath)
+	if err != nil {
+		return kp.cached(err)
+	}
+	if kp.cert != nil && certMod.Equal(kp.certMod) && keyMod.Equal(kp.keyMod) {
+		return kp.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(kp.certPath, kp.keyPath)
+	if err != nil {
+		if kp.cert != nil {
+			// Try again on the next change rather than on every handshake
+			kp.certMod, kp.keyMod = certMod, keyMod
+			logger.Log.Error("failed to reload TLS key pair, using the previous pair", zap.Error(err))
+			return kp.cert, nil
+		}
+		return nil, fmt.Errorf("could not load key pair: %s", err)
+	}
+
+	if kp.cert != nil {
+		logger.Log.Info("TLS key pair reloaded", zap.String("cert", kp.certPath))
+	}
+
+	kp.cert = &cert
+	kp.certMod, kp.keyMod = certMod, keyMod
+
+	return kp.cert, nil
+}
+
+// returns the loaded pair since files may be briefly missing while they are replaced
+func (kp *keyPair) cached(err error) (*tls.Certificate, error) {
+	if kp.cert != nil {
+		return kp.cert, nil
+	}
+	return nil, fmt.Errorf("couldn't read file: %s", err)
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// certPool is a bundle of PEM encoded CA certificates that is read again from disk whenever it changes
+type certPool struct {
+	path string
+
+	mu   sync.Mutex
+	pool *x509.CertPool
+	mod  time.Time
+}
+
+func newCertPool(path string) *certPool {
+	return &certPool{path: path}
+}
+
+func (cp *certPool) get() (*x509.CertPool, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	mod, err := modTime(cp.path)
+	if err != nil {
+		if cp.pool != nil {
+			return cp.pool, nil
+		}
+		return nil, fmt.Errorf("couldn't read file: %s", err)
+	}
+	if cp.pool != nil && mod.Equal(cp.mod) {
+		return cp.pool, nil
+	}
+
+	pool, err := readCertPool(cp.path)
+	if err != nil {
+		if cp.pool != nil {
+			cp.mod = mod
+			logger.Log.Error("failed to reload CA bundle, using the previous bundle", zap.Error(err))
+			return cp.pool, nil
+		}
+		return nil, err
+	}
+
+	if cp.pool != nil {
+		logger.Log.Info("CA bundle reloaded", zap.String("file", cp.path))
+	}
+
+	cp.pool = pool
+	cp.mod = mod
+
+	return cp.pool, nil
+}
+
+func readCertPool(path string) (*x509.CertPool, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file: %s", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, errors.New("bad certs")
+	}
+
+	return pool, nil
+}
+
+// verifies the peer certificate chain against the current CA bundle.
+// The host name is only checked when serverName is not empty.
+func verifyPeer(cp *certPool, serverName string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("peer did not present a certificate")
+		}
+
+		roots, err := cp.get()
+		if err != nil {
+			return err
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			DNSName:       serverName,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
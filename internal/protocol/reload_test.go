@@ -0,0 +1,179 @@
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// writes a self-signed certificate for name and its key, and returns their paths
+func writeCert(t *testing.T, dir, name string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath = filepath.Join(dir, name+".pem")
+	keyPath = filepath.Join(dir, name+"-key.pem")
+	writeFile(t, certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+
+	return certPath, keyPath
+}
+
+func writeFile(t *testing.T, path string, bs []byte) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, bs, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "protocol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// marks the file as changed, since rotations within the resolution of modification times are not seen
+func touch(t *testing.T, path string, age int) {
+	t.Helper()
+	mod := time.Now().Add(time.Duration(age) * time.Second)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyPairReload(t *testing.T) {
+	logger.Log = zap.NewNop()
+	dir := tempDir(t)
+	certPath, keyPath := writeCert(t, dir, "scheduler")
+	kp := newKeyPair(certPath, keyPath)
+
+	first, err := kp.get()
+	if err != nil {
+		t.Fatalf("get() failed: %v", err)
+	}
+
+	// Unchanged files are not read again
+	if cert, _ := kp.get(); cert != first {
+		t.Error("unchanged key pair was loaded again")
+	}
+
+	// A rotated pair is served from the next handshake
+	rotatedDir := tempDir(t)
+	rotatedCert, rotatedKey := writeCert(t, rotatedDir, "scheduler")
+	for src, dst := range map[string]string{rotatedCert: certPath, rotatedKey: keyPath} {
+		bs, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, dst, bs)
+		touch(t, dst, 1)
+	}
+	second, err := kp.get()
+	if err != nil {
+		t.Fatalf("get() after rotation failed: %v", err)
+	}
+	if second == first || string(second.Certificate[0]) == string(first.Certificate[0]) {
+		t.Fatal("rotated key pair was not loaded")
+	}
+
+	// A pair that cannot be loaded, such as a certificate written before its key, keeps the previous pair
+	writeFile(t, certPath, []byte("not a certificate"))
+	touch(t, certPath, 2)
+	if cert, err := kp.get(); err != nil || cert != second {
+		t.Errorf("get() with a broken pair = %v, %v, want the previous pair", cert, err)
+	}
+
+	// So do files that are briefly missing while they are replaced
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err := kp.get(); err != nil || cert != second {
+		t.Errorf("get() with a missing key = %v, %v, want the previous pair", cert, err)
+	}
+
+	// Without a previous pair the error is returned
+	if _, err := newKeyPair(certPath, keyPath).get(); err == nil {
+		t.Error("get() without a loadable pair succeeded")
+	}
+}
+
+func TestCertPoolReload(t *testing.T) {
+	logger.Log = zap.NewNop()
+	dir := tempDir(t)
+	caPath, _ := writeCert(t, dir, "clients")
+	cp := newCertPool(caPath)
+
+	first, err := cp.get()
+	if err != nil {
+		t.Fatalf("get() failed: %v", err)
+	}
+
+	// A bundle with another CA added is served from the next handshake
+	otherCA, _ := writeCert(t, dir, "other-clients")
+	bundle, err := ioutil.ReadFile(caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ioutil.ReadFile(otherCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, caPath, append(bundle, other...))
+	touch(t, caPath, 1)
+	second, err := cp.get()
+	if err != nil {
+		t.Fatalf("get() after rotation failed: %v", err)
+	}
+	if second == first || len(second.Subjects()) != 2 {
+		t.Fatal("rotated CA bundle was not loaded")
+	}
+
+	// A bundle that cannot be read keeps the previous bundle
+	writeFile(t, caPath, []byte("not a certificate"))
+	touch(t, caPath, 2)
+	if pool, err := cp.get(); err != nil || pool != second {
+		t.Errorf("get() with a broken bundle = %v, %v, want the previous bundle", pool, err)
+	}
+
+	if _, err := newCertPool(filepath.Join(dir, "missing.pem")).get(); err == nil {
+		t.Error("get() of a missing bundle succeeded")
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The Pseudocode:
+// 1. Validate the input fields of every operation
+// 2. Get the remote movies referenced by create and add voted operations, once for each movie
+// 3. Lock the mutex and defer unlock, using the draft if requested
+// 4. Keep a copy of the schedule and vote ledger
+// 5. Apply the operations in order, recording the error of any that fails
+// 6. Restore the copy if any operation failed or it is a dry run
+// 7. Return the operation errors
+func (scheduleAPI *scheduleAPIServer) BatchUpdateSchedule(
+	ctx context.Context, batchReq *scheduler.BatchUpdateScheduleRequest,
+) (*scheduler.BatchUpdateScheduleResponse, error) {
+	operations := batchReq.GetOperations()
+	if len(operations) == 0 {
+		return nil, errMissingCredential("Operations")
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err := editAllowed(ctx, "BatchUpdateSchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	opErrs := make([]error, len(operations))
+
+	// Validate the input fields of every operation
+	for i, operation := range operations {
+		switch op := operation.GetOperation().(type) {
+		case *scheduler.ScheduleOperation_Create:
+			opErrs[i] = validateCreateMovieDaySchedule(op.Create)
+		case *scheduler.ScheduleOperation_Delete:
+			opErrs[i] = validateDeleteMovieDaySchedule(op.Delete)
+		case *scheduler.ScheduleOperation_AddVoted:
+			opErrs[i] = validateAddVotedMovie(op.AddVoted)
+		case *scheduler.ScheduleOperation_Move:
+			opErrs[i] = validateMoveMovieDaySchedule(op.Move)
+		default:
+			opErrs[i] = errNilObject("operation")
+		}
+	}
+
+	// Get the movie resources, once for each movie
+	movies := make(map[string]*movie.Movie)
+	for i, operation := range operations {
+		if opErrs[i] != nil {
+			continue
+		}
+		var movieID string
+		switch op := operation.GetOperation().(type) {
+		case *scheduler.ScheduleOperation_Create:
+			movieID = op.Create.GetMovieId()
+		case *scheduler.ScheduleOperation_AddVoted:
+			movieID = op.AddVoted.GetMovieId()
+		default:
+			continue
+		}
+		if _, ok := movies[movieID]; !ok {
+			if cancelled(ctx) {
+				return nil, contextError(ctx, "BatchUpdateSchedule")
+			}
+			movieItem, err := scheduleAPI.movieAPIClient.GetMovie(ctx, &movie.GetMovieRequest{
+				MovieId: movieID,
+			})
+			if err != nil && status.Code(err) != codes.NotFound {
+				return nil, err
+			}
+			movies[movieID] = movieItem
+		}
+		if movies[movieID] == nil {
+			opErrs[i] = status.Errorf(codes.NotFound, "movie %q not found", movieID)
+		}
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history.
+	// Operations apply to the schedule of the batch whatever their draft field
+	if batchReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "BatchUpdateSchedule")()
+	}
+
+	weeklySchedule := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+	ledger := scheduleAPI.ledger.clone()
+
+	// Apply the operations, so that each one is checked against the changes of those before it
+	for i, operation := range operations {
+		if opErrs[i] != nil {
+			continue
+		}
+		switch op := operation.GetOperation().(type) {
+		case *scheduler.ScheduleOperation_Create:
+			movieItem := proto.Clone(movies[op.Create.GetMovieId()]).(*movie.Movie)
+			opErrs[i] = scheduleAPI.createMovieDaySchedule(op.Create, movieItem)
+		case *scheduler.ScheduleOperation_Delete:
+			opErrs[i] = scheduleAPI.deleteMovieDaySchedule(op.Delete)
+		case *scheduler.ScheduleOperation_AddVoted:
+			movieItem := proto.Clone(movies[op.AddVoted.GetMovieId()]).(*movie.Movie)
+			opErrs[i] = scheduleAPI.addVotedMovie(op.AddVoted, movieItem)
+		case *scheduler.ScheduleOperation_Move:
+			opErrs[i] = scheduleAPI.moveMovieDaySchedule(op.Move)
+		}
+	}
+
+	res := &scheduler.BatchUpdateScheduleResponse{
+		Errors: make([]*scheduler.BatchOperationError, 0),
+	}
+	for i, err := range opErrs {
+		if err != nil {
+			st := status.Convert(err)
+			res.Errors = append(res.Errors, &scheduler.BatchOperationError{
+				Operation: int32(i + 1),
+				Code:      int32(st.Code()),
+				Message:   st.Message(),
+			})
+		}
+	}
+
+	// All or nothing
+	if len(res.Errors) != 0 || batchReq.GetValidateOnly() {
+		scheduleAPI.weeklySchedule = *weeklySchedule
+		scheduleAPI.ledger = ledger
+		scheduleAPI.reindex()
+		return res, nil
+	}
+
+	res.Applied = true
+
+	return res, nil
+}
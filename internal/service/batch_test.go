@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+)
+
+func createOp(weekDay, show int32, screen, movieID string) *scheduler.ScheduleOperation {
+	return &scheduler.ScheduleOperation{
+		Operation: &scheduler.ScheduleOperation_Create{Create: &scheduler.CreateMovieDayScheduleRequest{
+			WeekDay: weekDay, Show: show, Screen: screen, MovieId: movieID,
+		}},
+	}
+}
+
+func addVotedOp(weekDay, show int32, screen, movieID string) *scheduler.ScheduleOperation {
+	return &scheduler.ScheduleOperation{
+		Operation: &scheduler.ScheduleOperation_AddVoted{AddVoted: &scheduler.AddVotedMovieRequest{
+			WeekDay: weekDay, Show: show, Screen: screen, MovieId: movieID,
+		}},
+	}
+}
+
+func TestBatchUpdateScheduleRollback(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name         string
+		operations   []*scheduler.ScheduleOperation
+		validateOnly bool
+		wantErrOps   []int32
+		wantMovie    string
+		wantVoted    int
+	}{
+		{
+			name:       "all operations succeed",
+			operations: []*scheduler.ScheduleOperation{createOp(1, 1, "A", "m1"), addVotedOp(1, 1, "A", "m2")},
+			wantMovie:  "m1",
+			wantVoted:  1,
+		},
+		{
+			name: "later operation sees earlier ones",
+			operations: []*scheduler.ScheduleOperation{
+				createOp(1, 1, "A", "m1"), addVotedOp(1, 1, "A", "m1"),
+			},
+			wantErrOps: []int32{2},
+		},
+		{
+			name: "failed operation rolls back the others",
+			operations: []*scheduler.ScheduleOperation{
+				createOp(1, 1, "A", "m1"), addVotedOp(1, 1, "A", "m2"), createOp(1, 1, "C", "m3"),
+			},
+			wantErrOps: []int32{3},
+		},
+		{
+			name: "missing movie rolls back the others",
+			operations: []*scheduler.ScheduleOperation{
+				createOp(1, 1, "A", "m1"), addVotedOp(1, 1, "A", "missing1"),
+			},
+			wantErrOps: []int32{2},
+		},
+		{
+			name:       "empty operation rolls back the others",
+			operations: []*scheduler.ScheduleOperation{createOp(1, 1, "A", "m1"), {}},
+			wantErrOps: []int32{2},
+		},
+		{
+			name:         "validate only",
+			operations:   []*scheduler.ScheduleOperation{createOp(1, 1, "A", "m1"), addVotedOp(1, 1, "A", "m2")},
+			validateOnly: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			before := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+
+			res, err := scheduleAPI.BatchUpdateSchedule(
+				userContext("u1", auth.RoleProgrammer),
+				&scheduler.BatchUpdateScheduleRequest{Operations: tt.operations, ValidateOnly: tt.validateOnly},
+			)
+			if err != nil {
+				t.Fatalf("BatchUpdateSchedule() failed: %v", err)
+			}
+
+			errOps := make([]int32, 0)
+			for _, opErr := range res.GetErrors() {
+				errOps = append(errOps, opErr.GetOperation())
+			}
+			if len(errOps) != len(tt.wantErrOps) {
+				t.Fatalf("operations %v failed, want %v", errOps, tt.wantErrOps)
+			}
+			for i := range errOps {
+				if errOps[i] != tt.wantErrOps[i] {
+					t.Fatalf("operations %v failed, want %v", errOps, tt.wantErrOps)
+				}
+			}
+
+			if tt.wantMovie == "" {
+				if !proto.Equal(before, &scheduleAPI.weeklySchedule) {
+					t.Errorf("schedule changed: %v", snapshot.Diff(before, &scheduleAPI.weeklySchedule))
+				}
+				if len(scheduleAPI.history.changes) != 0 {
+					t.Errorf("%d changes recorded, want none", len(scheduleAPI.history.changes))
+				}
+				if shows := scheduleAPI.index.shows["m1"]; len(shows) != 0 {
+					t.Errorf("index has movie m1 in %d shows after rollback", len(shows))
+				}
+				return
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if got := showSchedule.GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+			if got := len(showSchedule.GetVotedMovies()); got != tt.wantVoted {
+				t.Errorf("%d voted movies, want %d", got, tt.wantVoted)
+			}
+			if len(scheduleAPI.history.changes) != 1 {
+				t.Errorf("%d changes recorded, want 1", len(scheduleAPI.history.changes))
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.uber.org/zap"
+)
+
+// draftSchedule is a copy of the weekly schedule that programmers edit before publishing it.
+// Customers vote on the published schedule only, so the draft has no votes of its own in the ledger
+type draftSchedule struct {
+	weeklySchedule scheduler.DaysSchedule
+	index          *movieIndex
+	ledger         *voteLedger
+}
+
+// checks that the caller may read or edit the draft. Requests for the published schedule are always allowed.
+// Anonymous reads of the draft fail with the error that failed authenticating the caller, if any
+func draftAllowed(ctx context.Context, draft bool) error {
+	if !draft {
+		return nil
+	}
+	claims, ok := auth.FromContext(ctx)
+	if err := auth.ErrorFromContext(ctx); !ok && err != nil {
+		return err
+	}
+	if !claims.HasRole(auth.RoleProgrammer) {
+		return errDraftPermission()
+	}
+	return nil
+}
+
+// checks that the caller may edit the schedule. Only programmers edit the published schedule and the draft
+func editAllowed(ctx context.Context, operation string) error {
+	claims, _ := auth.FromContext(ctx)
+	if !claims.HasRole(auth.RoleProgrammer) {
+		return errPermissionDenied(operation)
+	}
+	return nil
+}
+
+// useDraft makes the draft the schedule that is read and changed until the returned function is called.
+// The draft is copied from the published schedule if there is none, and kept only if the copy is changed,
+// so that failed requests do not create a draft.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) useDraft() func() {
+	published := scheduleAPI.weeklySchedule
+	index, ledger := scheduleAPI.index, scheduleAPI.ledger
+	created := scheduleAPI.draft == nil
+
+	if created {
+		scheduleAPI.weeklySchedule = *proto.Clone(&published).(*scheduler.DaysSchedule)
+		scheduleAPI.ledger = newVoteLedger()
+		scheduleAPI.reindex()
+	} else {
+		scheduleAPI.weeklySchedule = scheduleAPI.draft.weeklySchedule
+		scheduleAPI.index, scheduleAPI.ledger = scheduleAPI.draft.index, scheduleAPI.draft.ledger
+	}
+
+	return func() {
+		if created && proto.Equal(&scheduleAPI.weeklySchedule, &published) {
+			scheduleAPI.weeklySchedule = published
+			scheduleAPI.index, scheduleAPI.ledger = index, ledger
+			return
+		}
+		scheduleAPI.draft = &draftSchedule{
+			weeklySchedule: scheduleAPI.weeklySchedule,
+			index:          scheduleAPI.index,
+			ledger:         scheduleAPI.ledger,
+		}
+		scheduleAPI.weeklySchedule = published
+		scheduleAPI.index, scheduleAPI.ledger = index, ledger
+	}
+}
+
+// readDraft makes the draft, with the votes cast since it was copied, the schedule that is read until
+// the returned function is called, so that reads see the draft as DiffDraft and PublishDraft do.
+// The published schedule is read when there is no draft.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) readDraft() func() {
+	if scheduleAPI.draft == nil {
+		return func() {}
+	}
+	published := scheduleAPI.weeklySchedule
+	index, ledger := scheduleAPI.index, scheduleAPI.ledger
+
+	scheduleAPI.weeklySchedule = *scheduleAPI.mergedDraft()
+	scheduleAPI.index, scheduleAPI.ledger = scheduleAPI.draft.index, scheduleAPI.draft.ledger
+
+	return func() {
+		scheduleAPI.weeklySchedule = published
+		scheduleAPI.index, scheduleAPI.ledger = index, ledger
+	}
+}
+
+// mergedDraft returns a copy of the draft with the votes cast on the published schedule since the draft
+// was copied, and the movie info refreshed since, for movies that are in the same show in both.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) mergedDraft() *scheduler.DaysSchedule {
+	merged := proto.Clone(&scheduleAPI.draft.weeklySchedule).(*scheduler.DaysSchedule)
+	for _, slot := range snapshot.Slots(merged) {
+		publishedShow := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+		if publishedShow == nil {
+			continue
+		}
+		publishedMovies := make(map[string]*movie.Movie)
+		for _, movieItem := range append(publishedShow.GetVotedMovies(), publishedShow.GetMovie()) {
+			publishedMovies[movieItem.GetId()] = movieItem
+		}
+		draftShow := snapshot.Lookup(merged, slot)
+		for _, movieItem := range append(draftShow.GetVotedMovies(), draftShow.GetMovie()) {
+			if publishedMovie, ok := publishedMovies[movieItem.GetId()]; ok && movieItem.GetId() != "" {
+				*movieItem = *proto.Clone(publishedMovie).(*movie.Movie)
+			}
+		}
+	}
+	return merged
+}
+
+// The Pseudocode:
+// 1. Check that the caller is a programmer
+// 2. Lock the mutex and defer unlock
+// 3. Return no differences if there is no draft
+// 4. Compare the draft, with the votes cast since it was copied, to the published schedule
+// 5. Return the differences
+func (scheduleAPI *scheduleAPIServer) DiffDraft(
+	ctx context.Context, diffReq *scheduler.DiffDraftRequest,
+) (*scheduler.DiffDraftResponse, error) {
+	err := draftAllowed(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	if scheduleAPI.draft == nil {
+		return &scheduler.DiffDraftResponse{
+			Differences: make([]*scheduler.ScheduleDifference, 0),
+		}, nil
+	}
+
+	return &scheduler.DiffDraftResponse{
+		Differences: scheduleDifferences(snapshot.Diff(&scheduleAPI.weeklySchedule, scheduleAPI.mergedDraft())),
+	}, nil
+}
+
+// The Pseudocode:
+// 1. Check that the caller is a programmer
+// 2. Lock the mutex and defer unlock
+// 3. Return err if there is no draft
+// 4. Carry the votes cast and movie info refreshed since the draft was copied over to the draft
+// 5. Check the draft against the configured screens, shows and voting cap
+// 6. Increase the versions of the shows and days that changed past their published versions
+// 7. Replace the published schedule with the draft and discard the draft
+// 8. Record the changes published in the change history
+// 9. Return the differences published
+func (scheduleAPI *scheduleAPIServer) PublishDraft(
+	ctx context.Context, publishReq *scheduler.PublishDraftRequest,
+) (*scheduler.PublishDraftResponse, error) {
+	err := draftAllowed(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Record the changes published in the change history
+	defer scheduleAPI.recordChanges(ctx, "PublishDraft")()
+
+	if scheduleAPI.draft == nil {
+		return nil, errNoDraft()
+	}
+
+	merged := scheduleAPI.mergedDraft()
+
+	violations := snapshot.Validate(merged, snapshot.Layout{
+		Screens:        scheduleAPI.opts.Screens,
+		Shows:          layoutShows(scheduleAPI.opts.Shows),
+		MaxMoviesVoted: scheduleAPI.opts.MaxMoviesVoted,
+	})
+	if len(violations) != 0 {
+		return nil, errInvalidDraft(violations)
+	}
+
+	diffs := snapshot.Diff(&scheduleAPI.weeklySchedule, merged)
+
+	publishVersions(&scheduleAPI.weeklySchedule, merged)
+
+	scheduleAPI.weeklySchedule = *merged
+	scheduleAPI.draft = nil
+	scheduleAPI.reindex()
+
+	logger.Log.Info("draft schedule published", zap.Int("differences", len(diffs)))
+
+	return &scheduler.PublishDraftResponse{
+		Differences: scheduleDifferences(diffs),
+	}, nil
+}
+
+// The Pseudocode:
+// 1. Check that the caller is a programmer
+// 2. Lock the mutex and defer unlock
+// 3. Discard the draft if there is one
+// 4. Return success
+func (scheduleAPI *scheduleAPIServer) DiscardDraft(
+	ctx context.Context, discardReq *empty.Empty,
+) (*empty.Empty, error) {
+	err := draftAllowed(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	scheduleAPI.draft = nil
+
+	return &empty.Empty{}, nil
+}
+
+// publishVersions gives the shows and days of the draft versions past those of the published schedule,
+// so that versions keep increasing. Unchanged shows keep their published version.
+// Votes are cast on the published schedule, so its votes versions are kept
+func publishVersions(published, draft *scheduler.DaysSchedule) {
+	for weekDay, daySchedule := range draft.GetDaysSchedule() {
+		publishedDay := published.GetDaysSchedule()[weekDay]
+		dayChanged := publishedDay == nil
+		daySchedule.VotesVersion = publishedDay.GetVotesVersion()
+		for screen, screenSchedule := range daySchedule.GetScreensSchedule() {
+			for show, showSchedule := range screenSchedule.GetShowsSchedule() {
+				publishedShow := snapshot.Lookup(published, snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: show})
+				showSchedule.VotesVersion = publishedShow.GetVotesVersion()
+				if sameShow(publishedShow, showSchedule) {
+					showSchedule.Version = publishedShow.Version
+					continue
+				}
+				showSchedule.Version = maxVersion(publishedShow.GetVersion(), showSchedule.Version) + 1
+				dayChanged = true
+			}
+		}
+		if dayChanged {
+			daySchedule.Version = maxVersion(publishedDay.GetVersion(), daySchedule.Version) + 1
+		} else {
+			daySchedule.Version = publishedDay.Version
+		}
+	}
+}
+
+// checks whether two shows are equal regardless of their versions
+func sameShow(showSchedule, other *scheduler.ShowSchedule) bool {
+	if showSchedule == nil || other == nil {
+		return false
+	}
+	showSchedule = proto.Clone(showSchedule).(*scheduler.ShowSchedule)
+	other = proto.Clone(other).(*scheduler.ShowSchedule)
+	showSchedule.Version, other.Version = 0, 0
+	showSchedule.VotesVersion, other.VotesVersion = 0, 0
+	return proto.Equal(showSchedule, other)
+}
+
+func maxVersion(version, other int64) int64 {
+	if version > other {
+		return version
+	}
+	return other
+}
+
+// converts shows of the options to the layout used to validate schedules
+func layoutShows(shows []Show) []snapshot.Show {
+	layoutShows := make([]snapshot.Show, 0, len(shows))
+	for _, show := range shows {
+		layoutShows = append(layoutShows, snapshot.Show{ID: show.ID, PlayTime: show.PlayTime})
+	}
+	return layoutShows
+}
+
+// converts differences between schedules to their API messages
+func scheduleDifferences(diffs []snapshot.Difference) []*scheduler.ScheduleDifference {
+	differences := make([]*scheduler.ScheduleDifference, 0, len(diffs))
+	for _, diff := range diffs {
+		differences = append(differences, &scheduler.ScheduleDifference{
+			WeekDay: diff.Slot.WeekDay,
+			Screen:  diff.Slot.Screen,
+			Show:    diff.Slot.Show,
+			Field:   diff.Field,
+			Before:  diff.Before,
+			After:   diff.After,
+		})
+	}
+	return differences
+}
+
+// restores the draft saved alongside the weekly schedule, adding slots that are missing from it
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) loadDraft() error {
+	if scheduleAPI.opts.DraftStore == nil {
+		return nil
+	}
+
+	bs, err := scheduleAPI.opts.DraftStore.Load()
+	if err != nil {
+		return err
+	}
+	if len(bs) == 0 {
+		return nil
+	}
+
+	weeklySchedule, err := snapshot.Unmarshal(bs)
+	if err != nil {
+		return errFromProtoUnMarshal(err, "draft schedule")
+	}
+
+	scheduleAPI.draft = &draftSchedule{
+		weeklySchedule: *weeklySchedule,
+		index:          newMovieIndex(),
+		ledger:         newVoteLedger(),
+	}
+	restore := scheduleAPI.useDraft()
+	scheduleAPI.syncSlots()
+	scheduleAPI.reindex()
+	restore()
+
+	logger.Log.Info("draft schedule restored", zap.Stringer("store", scheduleAPI.opts.DraftStore))
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// returns a weekly schedule of one day with the versions given, playing a show on screen A.
+// The day has no shows if showSchedule is nil
+func dayWithShow(dayVersion, dayVotesVersion int64, showSchedule *scheduler.ShowSchedule) *scheduler.DaysSchedule {
+	shows := make(map[int32]*scheduler.ShowSchedule)
+	if showSchedule != nil {
+		shows[1] = showSchedule
+	}
+	return &scheduler.DaysSchedule{
+		DaysSchedule: map[int32]*scheduler.ScreensSchedule{
+			1: {
+				ScreensSchedule: map[string]*scheduler.ShowsSchedule{"A": {ShowsSchedule: shows}},
+				Version:         dayVersion,
+				VotesVersion:    dayVotesVersion,
+			},
+		},
+	}
+}
+
+func versionedShow(movieID string, version, votesVersion int64) *scheduler.ShowSchedule {
+	return &scheduler.ShowSchedule{
+		PlayTime:     "10:00",
+		Movie:        &movie.Movie{Id: movieID},
+		Version:      version,
+		VotesVersion: votesVersion,
+	}
+}
+
+func TestPublishVersions(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name             string
+		published        *scheduler.DaysSchedule
+		draft            *scheduler.DaysSchedule
+		wantVersion      int64
+		wantVotesVersion int64
+		wantDayVersion   int64
+	}{
+		{
+			name:             "unchanged show keeps the published versions",
+			published:        dayWithShow(7, 9, versionedShow("m1", 5, 3)),
+			draft:            dayWithShow(2, 0, versionedShow("m1", 2, 0)),
+			wantVersion:      5,
+			wantVotesVersion: 3,
+			wantDayVersion:   7,
+		},
+		{
+			name:             "changed show goes past the published version",
+			published:        dayWithShow(7, 9, versionedShow("m1", 5, 3)),
+			draft:            dayWithShow(2, 0, versionedShow("m2", 2, 0)),
+			wantVersion:      6,
+			wantVotesVersion: 3,
+			wantDayVersion:   8,
+		},
+		{
+			name:             "changed show goes past the draft version",
+			published:        dayWithShow(7, 9, versionedShow("m1", 5, 3)),
+			draft:            dayWithShow(12, 0, versionedShow("m2", 9, 0)),
+			wantVersion:      10,
+			wantVotesVersion: 3,
+			wantDayVersion:   13,
+		},
+		{
+			name:             "versions alone do not change a show",
+			published:        dayWithShow(7, 9, versionedShow("m1", 5, 3)),
+			draft:            dayWithShow(20, 4, versionedShow("m1", 15, 4)),
+			wantVersion:      5,
+			wantVotesVersion: 3,
+			wantDayVersion:   7,
+		},
+		{
+			name:             "show that is not published",
+			published:        dayWithShow(7, 9, nil),
+			draft:            dayWithShow(2, 0, versionedShow("m1", 1, 0)),
+			wantVersion:      2,
+			wantVotesVersion: 0,
+			wantDayVersion:   8,
+		},
+		{
+			name:             "day that is not published",
+			published:        &scheduler.DaysSchedule{},
+			draft:            dayWithShow(2, 0, versionedShow("m1", 1, 0)),
+			wantVersion:      2,
+			wantVotesVersion: 0,
+			wantDayVersion:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publishVersions(tt.published, tt.draft)
+
+			showSchedule := snapshot.Lookup(tt.draft, slot)
+			if showSchedule.GetVersion() != tt.wantVersion {
+				t.Errorf("version = %d, want %d", showSchedule.GetVersion(), tt.wantVersion)
+			}
+			if showSchedule.GetVotesVersion() != tt.wantVotesVersion {
+				t.Errorf("votes version = %d, want %d", showSchedule.GetVotesVersion(), tt.wantVotesVersion)
+			}
+			daySchedule := tt.draft.GetDaysSchedule()[slot.WeekDay]
+			if daySchedule.GetVersion() != tt.wantDayVersion {
+				t.Errorf("day version = %d, want %d", daySchedule.GetVersion(), tt.wantDayVersion)
+			}
+			wantDayVotesVersion := tt.published.GetDaysSchedule()[slot.WeekDay].GetVotesVersion()
+			if daySchedule.GetVotesVersion() != wantDayVotesVersion {
+				t.Errorf("day votes version = %d, want %d", daySchedule.GetVotesVersion(), wantDayVotesVersion)
+			}
+		})
+	}
+}
+
+func TestDraftEdit(t *testing.T) {
+	tests := []struct {
+		name            string
+		movieID         string
+		expectedVersion int64
+		wantCode        codes.Code
+		wantDraft       bool
+	}{
+		{name: "edit creates the draft", movieID: "m1", wantDraft: true},
+		{name: "failed edit does not create the draft", movieID: "m1", expectedVersion: 5, wantCode: codes.Aborted},
+		{name: "missing movie does not create the draft", movieID: "missing1", wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			published := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+
+			_, err := scheduleAPI.CreateMovieDaySchedule(
+				userContext("p1", auth.RoleProgrammer),
+				&scheduler.CreateMovieDayScheduleRequest{
+					WeekDay: 1, Show: 1, Screen: "A", MovieId: tt.movieID, ExpectedVersion: tt.expectedVersion,
+					Draft: true,
+				},
+			)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("CreateMovieDaySchedule() error = %v, want code %s", err, tt.wantCode)
+			}
+			if got := scheduleAPI.draft != nil; got != tt.wantDraft {
+				t.Errorf("draft exists = %t, want %t", got, tt.wantDraft)
+			}
+			if !proto.Equal(published, &scheduleAPI.weeklySchedule) {
+				t.Errorf("published schedule changed: %v", snapshot.Diff(published, &scheduleAPI.weeklySchedule))
+			}
+		})
+	}
+}
+
+func TestAddVotedMoviePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		draft    bool
+		wantCode codes.Code
+	}{
+		{name: "programmer", roles: []string{auth.RoleProgrammer}},
+		{name: "programmer editing the draft", roles: []string{auth.RoleProgrammer}, draft: true},
+		{name: "customer", wantCode: codes.PermissionDenied},
+		{name: "customer editing the draft", draft: true, wantCode: codes.PermissionDenied},
+		{name: "admin", roles: []string{auth.RoleAdmin}, wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			setShow(&scheduleAPI.weeklySchedule, 1, "A", 1, versionedShow("m1", 1, 0))
+			scheduleAPI.reindex()
+			published := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+
+			_, err := scheduleAPI.AddVotedMovie(
+				userContext("u1", tt.roles...),
+				&scheduler.AddVotedMovieRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1", Draft: tt.draft},
+			)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("AddVotedMovie() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil && !proto.Equal(published, &scheduleAPI.weeklySchedule) {
+				t.Errorf("schedule changed: %v", snapshot.Diff(published, &scheduleAPI.weeklySchedule))
+			}
+		})
+	}
+}
+
+func TestDraftReads(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	programmerCtx := userContext("p1", auth.RoleProgrammer)
+	scheduleAPI := newTestServer(t)
+	seedVotedShow(t, scheduleAPI, slot)
+
+	// The draft is copied before a vote is cast on the published schedule
+	_, err := scheduleAPI.AddVotedMovie(programmerCtx, &scheduler.AddVotedMovieRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v3", Draft: true,
+	})
+	if err != nil {
+		t.Fatalf("AddVotedMovie() in the draft failed: %v", err)
+	}
+	_, err = scheduleAPI.VoteUpMovie(userContext("u3"), &scheduler.VoteUpMovieRequest{
+		WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "v2",
+	})
+	if err != nil {
+		t.Fatalf("VoteUpMovie() failed: %v", err)
+	}
+
+	wantVotes := map[string]int32{"m1": 5, "v1": 3, "v2": 1, "v3": 0}
+	checkVotes := func(read string, showSchedule *scheduler.ShowSchedule) {
+		t.Helper()
+		if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, []string{"v1", "v2", "v3"}) {
+			t.Errorf("%s: voted movies = %v, want the draft's", read, got)
+		}
+		for _, movieItem := range append(showSchedule.GetVotedMovies(), showSchedule.GetMovie()) {
+			if movieItem.GetCurrentVotes() != wantVotes[movieItem.GetId()] {
+				t.Errorf(
+					"%s: votes of %q = %d, want %d",
+					read, movieItem.GetId(), movieItem.GetCurrentVotes(), wantVotes[movieItem.GetId()],
+				)
+			}
+		}
+	}
+
+	showSchedule, err := scheduleAPI.GetShowSchedule(programmerCtx, &scheduler.GetShowScheduleRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, Draft: true,
+	})
+	if err != nil {
+		t.Fatalf("GetShowSchedule() failed: %v", err)
+	}
+	checkVotes("GetShowSchedule", showSchedule)
+
+	weekSchedule, err := scheduleAPI.GetWeekSchedule(programmerCtx, &scheduler.GetWeekScheduleRequest{
+		MovieId: "v3", Draft: true,
+	})
+	if err != nil {
+		t.Fatalf("GetWeekSchedule() failed: %v", err)
+	}
+	checkVotes("GetWeekSchedule", snapshot.Lookup(weekSchedule, slot))
+
+	// Reads leave the draft as it was copied
+	draftShow := snapshot.Lookup(&scheduleAPI.draft.weeklySchedule, slot)
+	if votes := draftShow.GetVotedMovies()[1].GetCurrentVotes(); votes != 0 {
+		t.Errorf("draft copy of v2 has %d votes, want 0", votes)
+	}
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/Sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"strings"
+	"time"
 )
 
 // checks whether a given context has been cancelled
@@ -46,6 +49,10 @@ func errPermissionDenied(op string) error {
 	return status.Errorf(codes.PermissionDenied, "not authorised to perform %s operation", op)
 }
 
+func errVoteForOtherUser() error {
+	return status.Error(codes.PermissionDenied, "cannot vote on behalf of another user")
+}
+
 func errFromJSONMarshal(err error, obj string) error {
 	return status.Errorf(codes.Internal, "failed to marshal %s: %v", obj, err)
 }
@@ -117,3 +124,69 @@ func errMovieScheduleExist(movieID string) error {
 func errNoVotedMovieRoom() error {
 	return status.Error(codes.ResourceExhausted, "no room to add voted movie")
 }
+
+func errInvalidFieldMask(path string) error {
+	return status.Errorf(codes.InvalidArgument, "invalid field mask path %q", path)
+}
+
+func errShowNotEmpty(weekDay, showNumber int32, screen string) error {
+	return status.Errorf(
+		codes.FailedPrecondition, "show %d on screen %q for week day %d has movies", showNumber, screen, weekDay,
+	)
+}
+
+func errNoVotedMovie(movieID string) error {
+	return status.Errorf(codes.NotFound, "movie with %q is not a voted movie", movieID)
+}
+
+func errVotedMovieOrder(want, got int) error {
+	return status.Errorf(codes.InvalidArgument, "expected %d voted movie ids, got %d", want, got)
+}
+
+func errDuplicateVotedMovie(movieID string) error {
+	return status.Errorf(codes.InvalidArgument, "voted movie %q listed more than once", movieID)
+}
+
+func errRefundFromDraft() error {
+	return status.Error(codes.InvalidArgument, "votes can only be refunded from the published schedule")
+}
+
+func errVersionMismatch(expectedVersion, version int64) error {
+	return status.Errorf(codes.Aborted, "show is at version %d, expected version %d", version, expectedVersion)
+}
+
+func errDraftPermission() error {
+	return status.Error(codes.PermissionDenied, "the programmer role is required to use the draft schedule")
+}
+
+func errNoDraft() error {
+	return status.Error(codes.FailedPrecondition, "there is no draft schedule")
+}
+
+func errInvalidDraft(violations []string) error {
+	return status.Errorf(codes.FailedPrecondition, "draft schedule is invalid: %s", strings.Join(violations, "; "))
+}
+
+func errHistoryNotKept(since time.Time) error {
+	return status.Errorf(
+		codes.OutOfRange, "changes made before %s are not kept in the change history", since.Format(time.RFC3339),
+	)
+}
+
+func errHistoryArchive(err error) error {
+	return status.Errorf(codes.Internal, "failed to read the change history archive: %v", err)
+}
+
+func errChangeNotFound(changeID int64) error {
+	return status.Errorf(codes.NotFound, "change %d is not in the change history", changeID)
+}
+
+func errChangeNotUndoable(changeID int64, reason string) error {
+	return status.Errorf(codes.FailedPrecondition, "change %d cannot be undone: %s", changeID, reason)
+}
+
+func errChangeConflict(changeID int64, slot fmt.Stringer) error {
+	return status.Errorf(
+		codes.FailedPrecondition, "change %d cannot be undone: %s has changed since", changeID, slot,
+	)
+}
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultChangesPageSize = 50
+	maxChangesPageSize     = 500
+)
+
+// changeHistory keeps the most recent changes made to the published weekly schedule, oldest first.
+// It is guarded by the mutex guarding weeklySchedule
+type changeHistory struct {
+	size    int
+	changes []*scheduler.ScheduleChange
+	lastID  int64
+	// the schedule cannot be rolled back past since, because changes made before it were dropped
+	since time.Time
+	// when archive is set, changes dropped from the history are kept in dropped until they are archived.
+	// droppedSince is the time the history was kept since before the oldest of them was dropped
+	archive      bool
+	dropped      []*scheduler.ScheduleChange
+	droppedSince time.Time
+}
+
+func newChangeHistory(size int) *changeHistory {
+	return &changeHistory{
+		size:    size,
+		changes: make([]*scheduler.ScheduleChange, 0),
+	}
+}
+
+// adds a change, dropping the oldest changes past the size of the history
+func (history *changeHistory) add(change *scheduler.ScheduleChange) {
+	history.lastID++
+	change.Id = history.lastID
+	history.changes = append(history.changes, change)
+	history.resize(history.size)
+}
+
+// changes how many changes are kept, dropping the oldest changes past size
+func (history *changeHistory) resize(size int) {
+	history.size = size
+	if !history.archive {
+		history.dropped = nil
+	}
+	if len(history.changes) <= size {
+		return
+	}
+	if history.archive {
+		if len(history.dropped) == 0 {
+			history.droppedSince = history.since
+		}
+		history.dropped = append(history.dropped, history.changes[:len(history.changes)-size]...)
+	}
+	dropped := history.changes[len(history.changes)-size-1]
+	history.since, _ = ptypes.Timestamp(dropped.GetChangeTime())
+	history.changes = append([]*scheduler.ScheduleChange(nil), history.changes[len(history.changes)-size:]...)
+}
+
+// appends changes dropped from a change history to the archive of that history, skipping those archived already.
+// since is the time the history was kept since before the first of them was dropped.
+// The archive starts again from the changes if they do not follow the changes in it
+func (archive *changeHistory) appendDropped(changes []*scheduler.ScheduleChange, since time.Time) {
+	for _, change := range changes {
+		archived := change.GetId() <= archive.lastID && change.GetId() > archive.lastID-int64(len(archive.changes))
+		if !archived {
+			if len(archive.changes) == 0 || change.GetId() != archive.lastID+1 {
+				archive.changes = make([]*scheduler.ScheduleChange, 0)
+				archive.since = since
+			}
+			archive.changes = append(archive.changes, change)
+			archive.lastID = change.GetId()
+		}
+		since, _ = ptypes.Timestamp(change.GetChangeTime())
+	}
+	archive.resize(archive.size)
+}
+
+// savedHistory is the form in which the history is saved alongside the weekly schedule
+type savedHistory struct {
+	Since   time.Time `json:"since"`
+	LastID  int64     `json:"last_id"`
+	Changes [][]byte  `json:"changes"` // scheduler.ScheduleChange
+}
+
+func (history *changeHistory) marshal() ([]byte, error) {
+	saved := savedHistory{
+		Since:   history.since,
+		LastID:  history.lastID,
+		Changes: make([][]byte, 0, len(history.changes)),
+	}
+	for _, change := range history.changes {
+		bs, err := proto.Marshal(change)
+		if err != nil {
+			return nil, err
+		}
+		saved.Changes = append(saved.Changes, bs)
+	}
+	return json.Marshal(saved)
+}
+
+func (history *changeHistory) unmarshal(bs []byte) error {
+	saved := savedHistory{}
+	err := json.Unmarshal(bs, &saved)
+	if err != nil {
+		return err
+	}
+	changes := make([]*scheduler.ScheduleChange, 0, len(saved.Changes))
+	for _, changeBs := range saved.Changes {
+		change := &scheduler.ScheduleChange{}
+		err = proto.Unmarshal(changeBs, change)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+	history.since, history.lastID, history.changes = saved.Since, saved.LastID, changes
+	history.resize(history.size)
+	return nil
+}
+
+// recordChanges returns a function that records the changes made to the published schedule
+// after recordChanges was called as a single change made by the caller.
+// Nothing is recorded if the schedule is unchanged.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) recordChanges(ctx context.Context, method string) func() {
+	if scheduleAPI.history.size == 0 {
+		return func() {}
+	}
+
+	before := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+
+	return func() {
+		scheduleAPI.addChange(ctx, method, showChanges(before, &scheduleAPI.weeklySchedule))
+	}
+}
+
+// recordShowChange records a change made by the caller to one show of the published schedule,
+// given a copy of the show taken before it was changed. Used where copying the week for every
+// request would be too costly, such as votes.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) recordShowChange(
+	ctx context.Context, method string, slot snapshot.Slot, before *scheduler.ShowSchedule,
+) {
+	if scheduleAPI.history.size == 0 {
+		return
+	}
+	after := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+	if proto.Equal(before, after) {
+		return
+	}
+	scheduleAPI.addChange(ctx, method, []*scheduler.ShowChange{{
+		WeekDay: slot.WeekDay,
+		Screen:  slot.Screen,
+		Show:    slot.Show,
+		Before:  before,
+		After:   proto.Clone(after).(*scheduler.ShowSchedule),
+	}})
+}
+
+// adds the changes to shows made by the caller to the change history as a single change
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) addChange(
+	ctx context.Context, method string, showChanges []*scheduler.ShowChange,
+) {
+	if len(showChanges) == 0 {
+		return
+	}
+	var actor string
+	if claims, ok := auth.FromContext(ctx); ok {
+		actor = claims.UserID
+	}
+	scheduleAPI.history.add(&scheduler.ScheduleChange{
+		ChangeTime: ptypes.TimestampNow(),
+		Actor:      actor,
+		Method:     method,
+		Shows:      showChanges,
+	})
+}
+
+// returns the shows that differ between two weekly schedules in slot order
+func showChanges(before, after *scheduler.DaysSchedule) []*scheduler.ShowChange {
+	seen := make(map[snapshot.Slot]bool)
+	slots := make([]snapshot.Slot, 0)
+	for _, slot := range append(snapshot.Slots(before), snapshot.Slots(after)...) {
+		if !seen[slot] {
+			seen[slot] = true
+			slots = append(slots, slot)
+		}
+	}
+	snapshot.SortSlots(slots)
+
+	showChanges := make([]*scheduler.ShowChange, 0)
+	for _, slot := range slots {
+		beforeShow, afterShow := snapshot.Lookup(before, slot), snapshot.Lookup(after, slot)
+		if proto.Equal(beforeShow, afterShow) {
+			continue
+		}
+		showChange := &scheduler.ShowChange{WeekDay: slot.WeekDay, Screen: slot.Screen, Show: slot.Show}
+		if beforeShow != nil {
+			showChange.Before = proto.Clone(beforeShow).(*scheduler.ShowSchedule)
+		}
+		if afterShow != nil {
+			showChange.After = proto.Clone(afterShow).(*scheduler.ShowSchedule)
+		}
+		showChanges = append(showChanges, showChange)
+	}
+	return showChanges
+}
+
+// scheduleAt returns a copy of the published schedule as it was at a point in time,
+// by rolling back the changes made after it. Changes older than the change history are read from its archive
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) scheduleAt(at time.Time) (*scheduler.DaysSchedule, error) {
+	changes := scheduleAPI.history.changes
+	if at.Before(scheduleAPI.history.since) {
+		if !scheduleAPI.history.archive {
+			return nil, errHistoryNotKept(scheduleAPI.history.since)
+		}
+		archive, err := scheduleAPI.archivedHistory()
+		if err != nil {
+			return nil, errHistoryArchive(err)
+		}
+		// The archive is of use only if it ends where the change history starts
+		firstID := scheduleAPI.history.lastID - int64(len(changes)) + 1
+		if len(archive.changes) == 0 || archive.lastID+1 != firstID {
+			return nil, errHistoryNotKept(scheduleAPI.history.since)
+		}
+		if at.Before(archive.since) {
+			return nil, errHistoryNotKept(archive.since)
+		}
+		changes = append(append([]*scheduler.ScheduleChange(nil), archive.changes...), changes...)
+	}
+
+	weeklySchedule := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		changeTime, _ := ptypes.Timestamp(change.GetChangeTime())
+		if !changeTime.After(at) {
+			break
+		}
+		for _, showChange := range change.GetShows() {
+			setShow(
+				weeklySchedule, showChange.GetWeekDay(), showChange.GetScreen(), showChange.GetShow(),
+				showChange.GetBefore(),
+			)
+		}
+	}
+	return weeklySchedule, nil
+}
+
+// sets a copy of the show in a slot of the weekly schedule, or removes the slot if the show is nil
+func setShow(
+	weeklySchedule *scheduler.DaysSchedule, weekDay int32, screen string, show int32, showSchedule *scheduler.ShowSchedule,
+) {
+	if showSchedule == nil {
+		delete(weeklySchedule.GetDaysSchedule()[weekDay].GetScreensSchedule()[screen].GetShowsSchedule(), show)
+		return
+	}
+	if weeklySchedule.DaysSchedule == nil {
+		weeklySchedule.DaysSchedule = make(map[int32]*scheduler.ScreensSchedule)
+	}
+	daySchedule, ok := weeklySchedule.DaysSchedule[weekDay]
+	if !ok {
+		daySchedule = &scheduler.ScreensSchedule{ScreensSchedule: make(map[string]*scheduler.ShowsSchedule)}
+		weeklySchedule.DaysSchedule[weekDay] = daySchedule
+	}
+	screenSchedule, ok := daySchedule.ScreensSchedule[screen]
+	if !ok {
+		screenSchedule = &scheduler.ShowsSchedule{ShowsSchedule: make(map[int32]*scheduler.ShowSchedule)}
+		daySchedule.ScreensSchedule[screen] = screenSchedule
+	}
+	screenSchedule.ShowsSchedule[show] = proto.Clone(showSchedule).(*scheduler.ShowSchedule)
+}
+
+// The Pseudocode:
+// 1. Validate the filters and the page token
+// 2. Lock the mutex and defer unlock
+// 3. Range over the changes from the newest, starting at the page token
+// 4. Add changes that match the filters until the page is full
+// 5. Return the page with the token of the next page if there are older changes
+func (scheduleAPI *scheduleAPIServer) ListScheduleChanges(
+	ctx context.Context, listReq *scheduler.ListScheduleChangesRequest,
+) (*scheduler.ListScheduleChangesResponse, error) {
+	// Validate the input
+	err := func() error {
+		var err error
+		switch {
+		case listReq.GetWeekDay() < 0 || listReq.GetWeekDay() > 7:
+			err = errIncorrectVal("Week Day")
+		case listReq.GetShow() < 0:
+			err = errIncorrectVal("Show number")
+		case listReq.GetPageSize() < 0:
+			err = errIncorrectVal("Page size")
+		}
+		return err
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	fromTime, toTime, err := timeRange(listReq.GetFromTime(), listReq.GetToTime())
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := int(listReq.GetPageSize())
+	switch {
+	case pageSize == 0:
+		pageSize = defaultChangesPageSize
+	case pageSize > maxChangesPageSize:
+		pageSize = maxChangesPageSize
+	}
+
+	// Changes with ids up to the page token are on the page
+	var pageToken int64
+	if listReq.GetPageToken() != "" {
+		pageToken, err = strconv.ParseInt(listReq.GetPageToken(), 10, 64)
+		if err != nil || pageToken <= 0 {
+			return nil, errIncorrectVal("Page token")
+		}
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	listRes := &scheduler.ListScheduleChangesResponse{
+		Changes: make([]*scheduler.ScheduleChange, 0, pageSize),
+	}
+
+	for i := len(scheduleAPI.history.changes) - 1; i >= 0; i-- {
+		change := scheduleAPI.history.changes[i]
+		if pageToken != 0 && change.GetId() > pageToken {
+			continue
+		}
+		changeTime, _ := ptypes.Timestamp(change.GetChangeTime())
+		if changeTime.Before(fromTime) {
+			break
+		}
+		if changeTime.After(toTime) || !changeMatches(change, listReq) {
+			continue
+		}
+		if len(listRes.Changes) == pageSize {
+			listRes.NextPageToken = strconv.FormatInt(change.GetId(), 10)
+			break
+		}
+		listRes.Changes = append(listRes.Changes, change)
+	}
+
+	return listRes, nil
+}
+
+// checks whether a change matches the filters of a request
+func changeMatches(change *scheduler.ScheduleChange, listReq *scheduler.ListScheduleChangesRequest) bool {
+	if listReq.GetActor() != "" && change.GetActor() != listReq.GetActor() {
+		return false
+	}
+	for _, showChange := range change.GetShows() {
+		switch {
+		case listReq.GetWeekDay() != 0 && showChange.GetWeekDay() != listReq.GetWeekDay():
+		case listReq.GetScreen() != "" && showChange.GetScreen() != listReq.GetScreen():
+		case listReq.GetShow() != 0 && showChange.GetShow() != listReq.GetShow():
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// The Pseudocode:
+// 1. Validate the input fields, to_time defaults to now
+// 2. Lock the mutex and defer unlock
+// 3. Roll back the published schedule to each point in time using the change history
+// 4. Return the differences between the two schedules, limited to the day if requested
+func (scheduleAPI *scheduleAPIServer) DiffSchedules(
+	ctx context.Context, diffReq *scheduler.DiffSchedulesRequest,
+) (*scheduler.DiffSchedulesResponse, error) {
+	// Validate the input
+	if diffReq.GetFromTime() == nil {
+		return nil, errMissingCredential("From time")
+	}
+	if diffReq.GetWeekDay() < 0 || diffReq.GetWeekDay() > 7 {
+		return nil, errIncorrectVal("Week Day")
+	}
+
+	fromTime, toTime, err := timeRange(diffReq.GetFromTime(), diffReq.GetToTime())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	before, err := scheduleAPI.scheduleAt(fromTime)
+	if err != nil {
+		return nil, err
+	}
+	after, err := scheduleAPI.scheduleAt(toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]snapshot.Difference, 0)
+	for _, diff := range snapshot.Diff(before, after) {
+		if diffReq.GetWeekDay() == 0 || diff.Slot.WeekDay == diffReq.GetWeekDay() {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return &scheduler.DiffSchedulesResponse{
+		Differences: scheduleDifferences(diffs),
+	}, nil
+}
+
+// converts the bounds of a time range. The range starts at the zero time and ends now unless they are set
+func timeRange(from, to *timestamp.Timestamp) (time.Time, time.Time, error) {
+	fromTime, toTime := time.Time{}, time.Now()
+	var err error
+	if from != nil {
+		fromTime, err = ptypes.Timestamp(from)
+		if err != nil {
+			return fromTime, toTime, errIncorrectVal("From time")
+		}
+	}
+	if to != nil {
+		toTime, err = ptypes.Timestamp(to)
+		if err != nil {
+			return fromTime, toTime, errIncorrectVal("To time")
+		}
+	}
+	if fromTime.After(toTime) {
+		return fromTime, toTime, errIncorrectVal("From time")
+	}
+	return fromTime, toTime, nil
+}
+
+// returns the archive of changes dropped from the change history, including those not archived yet.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) archivedHistory() (*changeHistory, error) {
+	archive := newChangeHistory(scheduleAPI.opts.HistoryArchiveSize)
+	bs, err := scheduleAPI.opts.HistoryArchive.Load()
+	if err != nil {
+		return nil, err
+	}
+	if bs != nil {
+		err = archive.unmarshal(bs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal change history archive: %v", err)
+		}
+	}
+	archive.appendDropped(scheduleAPI.history.dropped, scheduleAPI.history.droppedSince)
+	return archive, nil
+}
+
+// archives changes dropped from the change history, then forgets them.
+// Called with the mutex guarding the snapshot files locked, so that archives are not written concurrently
+func (scheduleAPI *scheduleAPIServer) archiveDropped(
+	archiveStore SnapshotStore, archiveSize int, dropped []*scheduler.ScheduleChange, since time.Time,
+) error {
+	archive := newChangeHistory(archiveSize)
+	bs, err := archiveStore.Load()
+	if err != nil {
+		return err
+	}
+	if bs != nil {
+		err = archive.unmarshal(bs)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal change history archive: %v", err)
+		}
+	}
+	archive.appendDropped(dropped, since)
+
+	bs, err = archive.marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal change history archive: %v", err)
+	}
+	err = archiveStore.Save(bs)
+	if err != nil {
+		return err
+	}
+
+	// Changes dropped while archiving are archived next time
+	scheduleAPI.muSchedule.Lock()
+	defer scheduleAPI.muSchedule.Unlock()
+	if len(scheduleAPI.history.dropped) >= len(dropped) {
+		scheduleAPI.history.dropped = scheduleAPI.history.dropped[len(dropped):]
+		scheduleAPI.history.droppedSince, _ = ptypes.Timestamp(dropped[len(dropped)-1].GetChangeTime())
+	}
+	return nil
+}
+
+// restores the change history saved alongside the weekly schedule
+func (scheduleAPI *scheduleAPIServer) loadHistory() error {
+	var bs []byte
+	var err error
+	if scheduleAPI.opts.HistoryStore != nil {
+		bs, err = scheduleAPI.opts.HistoryStore.Load()
+		if err != nil {
+			return err
+		}
+	}
+	if bs == nil {
+		// Changes made to the restored schedule before the history was kept are unknown
+		scheduleAPI.history.since = time.Now()
+		return nil
+	}
+
+	err = scheduleAPI.history.unmarshal(bs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal change history: %v", err)
+	}
+
+	logger.Log.Info(
+		"change history restored",
+		zap.Stringer("store", scheduleAPI.opts.HistoryStore),
+		zap.Int("changes", len(scheduleAPI.history.changes)),
+	)
+
+	return nil
+}
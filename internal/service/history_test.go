@@ -0,0 +1,208 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// returns a show on a slot of the test server playing a movie, nil if movieID is empty
+func showPlaying(movieID string) *scheduler.ShowSchedule {
+	if movieID == "" {
+		return nil
+	}
+	return &scheduler.ShowSchedule{
+		PlayTime:    "10:00",
+		Movie:       &movie.Movie{Id: movieID},
+		VotedMovies: make([]*movie.Movie, 0),
+		Version:     1,
+	}
+}
+
+func TestScheduleAt(t *testing.T) {
+	since := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	addedSlot := snapshot.Slot{WeekDay: 1, Screen: "C", Show: 1}
+
+	// The movie of the slot changes from m0 to m3 an hour apart, starting an hour after since.
+	// The added slot is added playing n1 with the last change
+	changes := []struct {
+		hours         time.Duration
+		slot          snapshot.Slot
+		before, after string
+	}{
+		{1, slot, "m0", "m1"},
+		{2, slot, "m1", "m2"},
+		{3, slot, "m2", "m3"},
+		{3, addedSlot, "", "n1"},
+	}
+
+	tests := []struct {
+		name           string
+		at             time.Time
+		wantMovie      string
+		wantAddedMovie string
+		wantCode       codes.Code
+	}{
+		{name: "before the history is kept", at: since.Add(-time.Second), wantCode: codes.OutOfRange},
+		{name: "before the changes", at: since, wantMovie: "m0"},
+		{name: "at a change", at: since.Add(time.Hour), wantMovie: "m1"},
+		{name: "between changes", at: since.Add(150 * time.Minute), wantMovie: "m2"},
+		{name: "after the changes", at: since.Add(4 * time.Hour), wantMovie: "m3", wantAddedMovie: "n1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			scheduleAPI.history.since = since
+			for _, change := range changes {
+				changeTime, _ := ptypes.TimestampProto(since.Add(change.hours * time.Hour))
+				setShow(
+					&scheduleAPI.weeklySchedule, change.slot.WeekDay, change.slot.Screen, change.slot.Show,
+					showPlaying(change.after),
+				)
+				scheduleAPI.history.add(&scheduler.ScheduleChange{
+					ChangeTime: changeTime,
+					Method:     "UpdateMovieDaySchedule",
+					Shows: []*scheduler.ShowChange{{
+						WeekDay: change.slot.WeekDay, Screen: change.slot.Screen, Show: change.slot.Show,
+						Before: showPlaying(change.before), After: showPlaying(change.after),
+					}},
+				})
+			}
+
+			weeklySchedule, err := scheduleAPI.scheduleAt(tt.at)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("scheduleAt() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := snapshot.Lookup(weeklySchedule, slot).GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+			addedShow := snapshot.Lookup(weeklySchedule, addedSlot)
+			if got := addedShow.GetMovie().GetId(); got != tt.wantAddedMovie {
+				t.Errorf("added slot movie = %q, want %q", got, tt.wantAddedMovie)
+			}
+			if tt.wantAddedMovie == "" && addedShow != nil {
+				t.Errorf("added slot exists before it was added")
+			}
+
+			// The published schedule is not changed
+			if got := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot).GetMovie().GetId(); got != "m3" {
+				t.Errorf("published movie = %q, want %q", got, "m3")
+			}
+		})
+	}
+}
+
+func TestRecordChanges(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	programmerCtx := userContext("p1", auth.RoleProgrammer)
+
+	tests := []struct {
+		name        string
+		edit        func(scheduleAPI *scheduleAPIServer) error
+		wantMethods []string
+	}{
+		{
+			name: "edit is recorded",
+			edit: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.AddVotedMovie(programmerCtx, &scheduler.AddVotedMovieRequest{
+					WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v2",
+				})
+				return err
+			},
+			wantMethods: []string{"CreateMovieDaySchedule", "AddVotedMovie"},
+		},
+		{
+			name: "failed edit is not recorded",
+			edit: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.AddVotedMovie(programmerCtx, &scheduler.AddVotedMovieRequest{
+					WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v1",
+				})
+				if err == nil {
+					t.Errorf("adding a voted movie twice succeeded")
+				}
+				return nil
+			},
+			wantMethods: []string{"CreateMovieDaySchedule"},
+		},
+		{
+			name: "vote that swaps the showing movie is recorded",
+			edit: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.VoteUpMovie(userContext("u1"), &scheduler.VoteUpMovieRequest{
+					WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "v1",
+				})
+				return err
+			},
+			wantMethods: []string{"CreateMovieDaySchedule", "VoteUpMovie"},
+		},
+		{
+			name: "vote for the showing movie is not recorded",
+			edit: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.VoteUpMovie(userContext("u1"), &scheduler.VoteUpMovieRequest{
+					WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "m1",
+				})
+				return err
+			},
+			wantMethods: []string{"CreateMovieDaySchedule"},
+		},
+		{
+			name: "vote that does not swap the showing movie is not recorded",
+			edit: func(scheduleAPI *scheduleAPIServer) error {
+				for _, movieID := range []string{"m1", "v1"} {
+					_, err := scheduleAPI.VoteUpMovie(userContext("u1"), &scheduler.VoteUpMovieRequest{
+						WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: movieID,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantMethods: []string{"CreateMovieDaySchedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			_, err := scheduleAPI.CreateMovieDaySchedule(programmerCtx, &scheduler.CreateMovieDayScheduleRequest{
+				WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "m1",
+			})
+			if err != nil {
+				t.Fatalf("CreateMovieDaySchedule() failed: %v", err)
+			}
+			scheduleAPI.weeklySchedule.DaysSchedule[slot.WeekDay].ScreensSchedule[slot.Screen].
+				ShowsSchedule[slot.Show].VotedMovies = []*movie.Movie{{Id: "v1"}}
+			scheduleAPI.reindex()
+
+			if err := tt.edit(scheduleAPI); err != nil {
+				t.Fatalf("edit failed: %v", err)
+			}
+
+			methods := make([]string, 0)
+			for _, change := range scheduleAPI.history.changes {
+				methods = append(methods, change.GetMethod())
+			}
+			if len(methods) != len(tt.wantMethods) {
+				t.Fatalf("recorded changes %v, want %v", methods, tt.wantMethods)
+			}
+			for i := range methods {
+				if methods[i] != tt.wantMethods[i] {
+					t.Errorf("recorded changes %v, want %v", methods, tt.wantMethods)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// columns of the CSV layout
+var csvHeader = []string{"week_day", "screen", "show", "movie_id", "voted_movie_ids"}
+
+// The Pseudocode:
+// 1. Parse the rows from the request data
+// 2. Validate every row against the configured screens, shows and voting cap
+// 3. Get the remote movies referenced by the rows
+// 4. Return the row errors if any, or if it is a dry run
+// 5. Lock the mutex and defer unlock
+// 6. Apply every row or none
+func (scheduleAPI *scheduleAPIServer) ImportSchedule(
+	ctx context.Context, importReq *scheduler.ImportScheduleRequest,
+) (*scheduler.ImportScheduleResponse, error) {
+	if strings.Trim(importReq.GetData(), " \r\n\t") == "" {
+		return nil, errMissingCredential("Data")
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err := editAllowed(ctx, "ImportSchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, rowErrs, err := parseRows(importReq.GetFormat(), importReq.GetData())
+	if err != nil {
+		return nil, err
+	}
+
+	firstRow := firstRowNumber(importReq.GetFormat())
+	rowErrs = append(rowErrs, validateRows(rows, firstRow, scheduleAPI.options())...)
+
+	// Get the movie resources, once for each movie
+	movies := make(map[string]*movie.Movie)
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		for _, movieID := range append([]string{row.GetMovieId()}, row.GetVotedMovieIds()...) {
+			if movieID == "" {
+				continue
+			}
+			if _, ok := movies[movieID]; !ok {
+				if cancelled(ctx) {
+					return nil, contextError(ctx, "ImportSchedule")
+				}
+				movieItem, err := scheduleAPI.movieAPIClient.GetMovie(ctx, &movie.GetMovieRequest{
+					MovieId: movieID,
+				})
+				if err != nil && status.Code(err) != codes.NotFound {
+					return nil, err
+				}
+				movies[movieID] = movieItem
+			}
+			if movies[movieID] == nil {
+				rowErrs = append(rowErrs, rowError(firstRow+i, "movie %q not found", movieID))
+			}
+		}
+	}
+
+	sortRowErrors(rowErrs)
+
+	res := &scheduler.ImportScheduleResponse{
+		Rows:   int32(len(rows)),
+		Errors: rowErrs,
+	}
+
+	if len(rowErrs) != 0 || importReq.GetDryRun() {
+		return res, nil
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if importReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "ImportSchedule")()
+	}
+
+	// Screens or shows may have been reconfigured since the rows were validated
+	showSchedules := make([]*scheduler.ShowSchedule, len(rows))
+	for i, row := range rows {
+		showSchedule, err := scheduleAPI.getShowSchedule(row.GetWeekDay(), row.GetShow(), row.GetScreen())
+		if err != nil {
+			return nil, status.Errorf(codes.Aborted, "row %d: %v", firstRow+i, status.Convert(err).Message())
+		}
+		showSchedules[i] = showSchedule
+	}
+
+	for i, row := range rows {
+		applyRow(showSchedules[i], row, movies)
+		scheduleAPI.showChanged(row.GetWeekDay(), row.GetShow(), row.GetScreen())
+	}
+
+	res.Applied = true
+
+	return res, nil
+}
+
+func (scheduleAPI *scheduleAPIServer) ExportSchedule(
+	ctx context.Context, exportReq *scheduler.ExportScheduleRequest,
+) (*scheduler.ExportScheduleResponse, error) {
+	// Only programmers may read the draft
+	err := draftAllowed(ctx, exportReq.GetDraft())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex
+	scheduleAPI.lockSchedule(ctx)
+	// Read the draft instead of the published schedule if requested
+	restore := func() {}
+	if exportReq.GetDraft() {
+		restore = scheduleAPI.readDraft()
+	}
+	rows := make([]*scheduler.ScheduleRow, 0)
+	for _, slot := range snapshot.Slots(&scheduleAPI.weeklySchedule) {
+		showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+		row := &scheduler.ScheduleRow{
+			WeekDay:       slot.WeekDay,
+			Screen:        slot.Screen,
+			Show:          slot.Show,
+			MovieId:       showSchedule.GetMovie().GetId(),
+			VotedMovieIds: make([]string, 0, len(showSchedule.GetVotedMovies())),
+		}
+		for _, votedMovie := range showSchedule.GetVotedMovies() {
+			row.VotedMovieIds = append(row.VotedMovieIds, votedMovie.GetId())
+		}
+		rows = append(rows, row)
+	}
+	restore()
+	// Unlock the mutex
+	scheduleAPI.muSchedule.Unlock()
+
+	data, err := formatRows(exportReq.GetFormat(), rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scheduler.ExportScheduleResponse{
+		Format: exportReq.GetFormat(),
+		Data:   data,
+	}, nil
+}
+
+// sets the movie and voted movies of a show. Votes are kept for movies already in the show.
+// Assumes that the mutex gurading weeklySchedule is locked
+func applyRow(showSchedule *scheduler.ShowSchedule, row *scheduler.ScheduleRow, movies map[string]*movie.Movie) {
+	currentVotes := make(map[string]int32)
+	for _, movieItem := range append(showSchedule.VotedMovies, showSchedule.Movie) {
+		if movieItem != nil && movieItem.Id != "" {
+			currentVotes[movieItem.Id] = movieItem.CurrentVotes
+		}
+	}
+
+	scheduled := func(movieID string) *movie.Movie {
+		if movieID == "" {
+			return &movie.Movie{}
+		}
+		movieItem := proto.Clone(movies[movieID]).(*movie.Movie)
+		movieItem.CurrentVotes = currentVotes[movieID]
+		return movieItem
+	}
+
+	showSchedule.Movie = scheduled(row.GetMovieId())
+	showSchedule.VotedMovies = make([]*movie.Movie, 0, len(row.GetVotedMovieIds()))
+	for _, movieID := range row.GetVotedMovieIds() {
+		showSchedule.VotedMovies = append(showSchedule.VotedMovies, scheduled(movieID))
+	}
+}
+
+// parses rows in the given format. Rows that cannot be parsed are nil and have a row error
+func parseRows(
+	format scheduler.ScheduleFormat, data string,
+) ([]*scheduler.ScheduleRow, []*scheduler.ImportRowError, error) {
+	switch format {
+	case scheduler.ScheduleFormat_CSV:
+		return parseCSVRows(data)
+	case scheduler.ScheduleFormat_JSON:
+		return parseJSONRows(data)
+	}
+	return nil, nil, errIncorrectVal("Format")
+}
+
+// CSV rows are numbered like the lines of a spreadsheet, where the header is
+// row 1, and JSON rows are numbered from 1 by their position in the array
+func firstRowNumber(format scheduler.ScheduleFormat) int {
+	if format == scheduler.ScheduleFormat_CSV {
+		return 2
+	}
+	return 1
+}
+
+func parseCSVRows(data string) ([]*scheduler.ScheduleRow, []*scheduler.ImportRowError, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "failed to read CSV header: %v", err)
+	}
+
+	// Columns may be in any order, voted_movie_ids may be left out
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.Trim(name, " "))] = i
+	}
+	for _, name := range csvHeader[:4] {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "CSV header is missing column %q", name)
+		}
+	}
+
+	rows := make([]*scheduler.ScheduleRow, 0)
+	rowErrs := make([]*scheduler.ImportRowError, 0)
+	firstRow := firstRowNumber(scheduler.ScheduleFormat_CSV)
+	for i := 0; ; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return nil, nil, status.Errorf(codes.InvalidArgument, "failed to read CSV: %v", err)
+			}
+			rows = append(rows, nil)
+			rowErrs = append(rowErrs, rowError(firstRow+i, "%v", err))
+			continue
+		}
+
+		field := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.Trim(record[index], " ")
+		}
+
+		weekDay, err1 := strconv.ParseInt(field("week_day"), 10, 32)
+		show, err2 := strconv.ParseInt(field("show"), 10, 32)
+		switch {
+		case err1 != nil:
+			rows = append(rows, nil)
+			rowErrs = append(rowErrs, rowError(firstRow+i, "week_day %q is not a number", field("week_day")))
+			continue
+		case err2 != nil:
+			rows = append(rows, nil)
+			rowErrs = append(rowErrs, rowError(firstRow+i, "show %q is not a number", field("show")))
+			continue
+		}
+
+		row := &scheduler.ScheduleRow{
+			WeekDay:       int32(weekDay),
+			Screen:        field("screen"),
+			Show:          int32(show),
+			MovieId:       field("movie_id"),
+			VotedMovieIds: make([]string, 0),
+		}
+		if votedMovieIDs := field("voted_movie_ids"); votedMovieIDs != "" {
+			for _, movieID := range strings.Split(votedMovieIDs, ";") {
+				row.VotedMovieIds = append(row.VotedMovieIds, strings.Trim(movieID, " "))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrs, nil
+}
+
+func parseJSONRows(data string) ([]*scheduler.ScheduleRow, []*scheduler.ImportRowError, error) {
+	rawRows := make([]json.RawMessage, 0)
+	err := json.Unmarshal([]byte(data), &rawRows)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "JSON data must be an array of rows: %v", err)
+	}
+
+	rows := make([]*scheduler.ScheduleRow, 0, len(rawRows))
+	rowErrs := make([]*scheduler.ImportRowError, 0)
+	firstRow := firstRowNumber(scheduler.ScheduleFormat_JSON)
+	for i, rawRow := range rawRows {
+		row := &scheduler.ScheduleRow{}
+		err := jsonpb.Unmarshal(bytes.NewReader(rawRow), row)
+		if err != nil {
+			rows = append(rows, nil)
+			rowErrs = append(rowErrs, rowError(firstRow+i, "%v", err))
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrs, nil
+}
+
+// checks rows against the options. Rows that failed to parse are skipped
+func validateRows(rows []*scheduler.ScheduleRow, firstRow int, opts Options) []*scheduler.ImportRowError {
+	screens := make(map[string]bool, len(opts.Screens))
+	for _, screen := range opts.Screens {
+		screens[screen] = true
+	}
+	shows := make(map[int32]bool, len(opts.Shows))
+	for _, show := range opts.Shows {
+		shows[show.ID] = true
+	}
+
+	rowErrs := make([]*scheduler.ImportRowError, 0)
+	slots := make(map[snapshot.Slot]int)
+
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+
+		slot := snapshot.Slot{WeekDay: row.GetWeekDay(), Screen: row.GetScreen(), Show: row.GetShow()}
+		switch {
+		case slot.WeekDay <= 0 || slot.WeekDay > 7:
+			rowErrs = append(rowErrs, rowError(firstRow+i, "week_day %d is not between 1 and 7", slot.WeekDay))
+			continue
+		case !screens[slot.Screen]:
+			rowErrs = append(rowErrs, rowError(firstRow+i, "screen %q is not configured", slot.Screen))
+			continue
+		case !shows[slot.Show]:
+			rowErrs = append(rowErrs, rowError(firstRow+i, "show %d is not configured", slot.Show))
+			continue
+		}
+
+		if first, ok := slots[slot]; ok {
+			rowErrs = append(rowErrs, rowError(firstRow+i, "%s is also in row %d", slot, first))
+			continue
+		}
+		slots[slot] = firstRow + i
+
+		if len(row.GetVotedMovieIds()) > opts.MaxMoviesVoted {
+			rowErrs = append(rowErrs, rowError(
+				firstRow+i, "%d voted movies, more than the maximum of %d", len(row.GetVotedMovieIds()), opts.MaxMoviesVoted,
+			))
+		}
+
+		if row.GetMovieId() == "" && len(row.GetVotedMovieIds()) != 0 {
+			rowErrs = append(rowErrs, rowError(firstRow+i, "voted movies require a scheduled movie"))
+		}
+
+		seen := map[string]bool{row.GetMovieId(): row.GetMovieId() != ""}
+		for _, movieID := range row.GetVotedMovieIds() {
+			switch {
+			case movieID == "":
+				rowErrs = append(rowErrs, rowError(firstRow+i, "voted movie id cannot be empty"))
+			case seen[movieID]:
+				rowErrs = append(rowErrs, rowError(firstRow+i, "movie %q appears more than once", movieID))
+			}
+			seen[movieID] = true
+		}
+	}
+
+	return rowErrs
+}
+
+// formats rows in the given format
+func formatRows(format scheduler.ScheduleFormat, rows []*scheduler.ScheduleRow) (string, error) {
+	buf := &bytes.Buffer{}
+
+	switch format {
+	case scheduler.ScheduleFormat_CSV:
+		writer := csv.NewWriter(buf)
+		writer.Write(csvHeader)
+		for _, row := range rows {
+			writer.Write([]string{
+				strconv.Itoa(int(row.WeekDay)),
+				row.Screen,
+				strconv.Itoa(int(row.Show)),
+				row.MovieId,
+				strings.Join(row.VotedMovieIds, ";"),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return "", status.Errorf(codes.Internal, "failed to write CSV: %v", err)
+		}
+	case scheduler.ScheduleFormat_JSON:
+		marshaler := &jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+		buf.WriteString("[")
+		for i, row := range rows {
+			if i != 0 {
+				buf.WriteString(",\n")
+			}
+			err := marshaler.Marshal(buf, row)
+			if err != nil {
+				return "", errFromJSONMarshal(err, "schedule row")
+			}
+		}
+		buf.WriteString("]\n")
+	default:
+		return "", errIncorrectVal("Format")
+	}
+
+	return buf.String(), nil
+}
+
+func rowError(row int, format string, args ...interface{}) *scheduler.ImportRowError {
+	return &scheduler.ImportRowError{
+		Row:     int32(row),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func sortRowErrors(rowErrs []*scheduler.ImportRowError) {
+	sort.SliceStable(rowErrs, func(i, j int) bool { return rowErrs[i].Row < rowErrs[j].Row })
+}
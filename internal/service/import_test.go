@@ -0,0 +1,266 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseRows(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      scheduler.ScheduleFormat
+		data        string
+		wantRows    []*scheduler.ScheduleRow
+		wantErrRows []int32
+		wantCode    codes.Code
+	}{
+		{
+			name:   "csv",
+			format: scheduler.ScheduleFormat_CSV,
+			data:   "week_day,screen,show,movie_id,voted_movie_ids\n1,A,2,m1,v1;v2\n3,B,1,,\n",
+			wantRows: []*scheduler.ScheduleRow{
+				{WeekDay: 1, Screen: "A", Show: 2, MovieId: "m1", VotedMovieIds: []string{"v1", "v2"}},
+				{WeekDay: 3, Screen: "B", Show: 1, VotedMovieIds: []string{}},
+			},
+		},
+		{
+			name:   "csv columns in any order without voted movies",
+			format: scheduler.ScheduleFormat_CSV,
+			data:   "movie_id, show, screen, week_day\nm1, 2, A, 1\n",
+			wantRows: []*scheduler.ScheduleRow{
+				{WeekDay: 1, Screen: "A", Show: 2, MovieId: "m1", VotedMovieIds: []string{}},
+			},
+		},
+		{
+			name:   "csv rows that are not numbers",
+			format: scheduler.ScheduleFormat_CSV,
+			data:   "week_day,screen,show,movie_id\nmonday,A,1,m1\n1,A,first,m1\n2,A,1,m2\n",
+			wantRows: []*scheduler.ScheduleRow{
+				nil, nil, {WeekDay: 2, Screen: "A", Show: 1, MovieId: "m2", VotedMovieIds: []string{}},
+			},
+			wantErrRows: []int32{2, 3},
+		},
+		{
+			name:     "csv header missing a column",
+			format:   scheduler.ScheduleFormat_CSV,
+			data:     "week_day,screen,movie_id\n1,A,m1\n",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "csv without header",
+			format:   scheduler.ScheduleFormat_CSV,
+			data:     "",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:   "json",
+			format: scheduler.ScheduleFormat_JSON,
+			data:   `[{"week_day": 1, "screen": "A", "show": 2, "movie_id": "m1", "voted_movie_ids": ["v1"]}]`,
+			wantRows: []*scheduler.ScheduleRow{
+				{WeekDay: 1, Screen: "A", Show: 2, MovieId: "m1", VotedMovieIds: []string{"v1"}},
+			},
+		},
+		{
+			name:        "json row with unknown field",
+			format:      scheduler.ScheduleFormat_JSON,
+			data:        `[{"week_day": 1, "screen": "A", "show": 1}, {"week_day": 1, "room": "A"}]`,
+			wantRows:    []*scheduler.ScheduleRow{{WeekDay: 1, Screen: "A", Show: 1}, nil},
+			wantErrRows: []int32{2},
+		},
+		{
+			name:     "json that is not an array",
+			format:   scheduler.ScheduleFormat_JSON,
+			data:     `{"week_day": 1}`,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown format",
+			format:   scheduler.ScheduleFormat(99),
+			data:     "",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrs, err := parseRows(tt.format, tt.data)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("parseRows() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("parseRows() returned %d rows, want %d", len(rows), len(tt.wantRows))
+			}
+			for i, row := range rows {
+				if !proto.Equal(row, tt.wantRows[i]) {
+					t.Errorf("row %d = %v, want %v", i+1, row, tt.wantRows[i])
+				}
+			}
+
+			errRows := make([]int32, 0)
+			for _, rowErr := range rowErrs {
+				errRows = append(errRows, rowErr.GetRow())
+			}
+			if len(errRows) != len(tt.wantErrRows) {
+				t.Fatalf("parseRows() row errors on rows %v, want %v", errRows, tt.wantErrRows)
+			}
+			for i := range errRows {
+				if errRows[i] != tt.wantErrRows[i] {
+					t.Errorf("parseRows() row errors on rows %v, want %v", errRows, tt.wantErrRows)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateRowsNameTheFirstRow(t *testing.T) {
+	scheduleAPI := newTestServer(t)
+	data := "week_day,screen,show,movie_id\n1,A,1,m1\n2,B,2,m2\n1,A,1,m3\n"
+
+	rows, _, err := parseRows(scheduler.ScheduleFormat_CSV, data)
+	if err != nil {
+		t.Fatalf("parseRows() error = %v", err)
+	}
+	rowErrs := validateRows(rows, firstRowNumber(scheduler.ScheduleFormat_CSV), scheduleAPI.options())
+	if len(rowErrs) != 1 {
+		t.Fatalf("row errors = %v, want 1", rowErrs)
+	}
+	if rowErrs[0].GetRow() != 4 || !strings.HasSuffix(rowErrs[0].GetMessage(), "is also in row 2") {
+		t.Errorf("row error = %v, want row 4 also in row 2", rowErrs[0])
+	}
+}
+
+func TestImportSchedule(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	other := snapshot.Slot{WeekDay: 2, Screen: "B", Show: 2}
+	data := "week_day,screen,show,movie_id,voted_movie_ids\n1,A,1,v1,m1;v3\n2,B,2,m4,\n"
+
+	tests := []struct {
+		name        string
+		roles       []string
+		req         *scheduler.ImportScheduleRequest
+		wantCode    codes.Code
+		wantErrRows []int32
+		wantApplied bool
+	}{
+		{
+			name:        "apply",
+			roles:       []string{auth.RoleProgrammer},
+			req:         &scheduler.ImportScheduleRequest{Format: scheduler.ScheduleFormat_CSV, Data: data},
+			wantApplied: true,
+		},
+		{
+			name:        "apply to the draft",
+			roles:       []string{auth.RoleProgrammer},
+			req:         &scheduler.ImportScheduleRequest{Format: scheduler.ScheduleFormat_CSV, Data: data, Draft: true},
+			wantApplied: true,
+		},
+		{
+			name:  "dry run",
+			roles: []string{auth.RoleProgrammer},
+			req:   &scheduler.ImportScheduleRequest{Format: scheduler.ScheduleFormat_CSV, Data: data, DryRun: true},
+		},
+		{
+			name:  "rows with errors apply nothing",
+			roles: []string{auth.RoleProgrammer},
+			req: &scheduler.ImportScheduleRequest{
+				Format: scheduler.ScheduleFormat_CSV, Data: data + "3,A,1,missing1,\n3,C,1,m1,\n",
+			},
+			wantErrRows: []int32{4, 5},
+		},
+		{
+			name:     "customer",
+			req:      &scheduler.ImportScheduleRequest{Format: scheduler.ScheduleFormat_CSV, Data: data},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedVotedShow(t, scheduleAPI, slot)
+			published := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+
+			res, err := scheduleAPI.ImportSchedule(userContext("p1", tt.roles...), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ImportSchedule() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if res.GetApplied() != tt.wantApplied {
+				t.Errorf("applied = %t, want %t", res.GetApplied(), tt.wantApplied)
+			}
+			errRows := make([]int32, 0, len(res.GetErrors()))
+			for _, rowErr := range res.GetErrors() {
+				errRows = append(errRows, rowErr.GetRow())
+			}
+			if len(errRows) != len(tt.wantErrRows) {
+				t.Fatalf("row errors = %v, want errors in rows %v", res.GetErrors(), tt.wantErrRows)
+			}
+			for i := range errRows {
+				if errRows[i] != tt.wantErrRows[i] {
+					t.Errorf("row errors = %v, want errors in rows %v", res.GetErrors(), tt.wantErrRows)
+				}
+			}
+
+			if !tt.wantApplied || tt.req.GetDraft() {
+				if !proto.Equal(published, &scheduleAPI.weeklySchedule) {
+					t.Errorf("published schedule changed: %v", snapshot.Diff(published, &scheduleAPI.weeklySchedule))
+				}
+			}
+			if !tt.wantApplied {
+				if scheduleAPI.draft != nil {
+					t.Error("draft was created")
+				}
+				return
+			}
+
+			weeklySchedule := &scheduleAPI.weeklySchedule
+			if tt.req.GetDraft() {
+				weeklySchedule = &scheduleAPI.draft.weeklySchedule
+			}
+
+			// Movies still in the show keep their votes, and the votes of the voted movie taken out are dropped
+			showSchedule := snapshot.Lookup(weeklySchedule, slot)
+			if showSchedule.GetMovie().GetId() != "v1" || showSchedule.GetMovie().GetCurrentVotes() != 3 {
+				t.Errorf("showing movie = %v, want v1 with 3 votes", showSchedule.GetMovie())
+			}
+			if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, []string{"m1", "v3"}) {
+				t.Errorf("voted movies = %v, want [m1 v3]", got)
+			}
+			if votes := showSchedule.GetVotedMovies()[0].GetCurrentVotes(); votes != 5 {
+				t.Errorf("m1 has %d votes, want 5", votes)
+			}
+			if showSchedule.GetVersion() != 2 {
+				t.Errorf("version = %d, want 2", showSchedule.GetVersion())
+			}
+			otherShow := snapshot.Lookup(weeklySchedule, other)
+			if otherShow.GetMovie().GetId() != "m4" || otherShow.GetMovie().GetTitle() != "Movie m4" {
+				t.Errorf("showing movie of %s = %v, want m4 from the movie service", other, otherShow.GetMovie())
+			}
+
+			if !tt.req.GetDraft() {
+				if got := scheduleAPI.ledger.count(slot, "v1"); got != 3 {
+					t.Errorf("ledger has %d votes for v1, want 3", got)
+				}
+				if got := len(scheduleAPI.index.lookup("m4")); got != 1 {
+					t.Errorf("index has m4 in %d shows, want 1", got)
+				}
+				if got := len(scheduleAPI.history.changes); got != 1 {
+					t.Errorf("change history has %d changes, want 1", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+// movieIndex records the shows that every movie is scheduled or nominated in,
+// so that a movie can be found without walking the weekly schedule
+type movieIndex struct {
+	shows  map[string]map[snapshot.Slot]bool // movie id to the shows it is in
+	movies map[snapshot.Slot][]string        // show to the movie ids indexed for it
+}
+
+func newMovieIndex() *movieIndex {
+	return &movieIndex{
+		shows:  make(map[string]map[snapshot.Slot]bool),
+		movies: make(map[snapshot.Slot][]string),
+	}
+}
+
+// update replaces the movies indexed for a show with the movies now in it
+func (idx *movieIndex) update(slot snapshot.Slot, showSchedule *scheduler.ShowSchedule) {
+	idx.remove(slot)
+
+	movieIDs := make([]string, 0, len(showSchedule.GetVotedMovies())+1)
+	for _, movieItem := range append(showSchedule.GetVotedMovies(), showSchedule.GetMovie()) {
+		if movieItem.GetId() == "" {
+			continue
+		}
+		if idx.shows[movieItem.Id] == nil {
+			idx.shows[movieItem.Id] = make(map[snapshot.Slot]bool)
+		}
+		idx.shows[movieItem.Id][slot] = true
+		movieIDs = append(movieIDs, movieItem.Id)
+	}
+
+	if len(movieIDs) != 0 {
+		idx.movies[slot] = movieIDs
+	}
+}
+
+// remove removes the movies indexed for a show
+func (idx *movieIndex) remove(slot snapshot.Slot) {
+	for _, movieID := range idx.movies[slot] {
+		delete(idx.shows[movieID], slot)
+		if len(idx.shows[movieID]) == 0 {
+			delete(idx.shows, movieID)
+		}
+	}
+	delete(idx.movies, slot)
+}
+
+// lookup returns the shows a movie is in, in day, screen and show order
+func (idx *movieIndex) lookup(movieID string) []snapshot.Slot {
+	slots := make([]snapshot.Slot, 0, len(idx.shows[movieID]))
+	for slot := range idx.shows[movieID] {
+		slots = append(slots, slot)
+	}
+	snapshot.SortSlots(slots)
+	return slots
+}
+
+// rebuilds the movie index from the weekly schedule and discards votes for movies no longer in their show.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) reindex() {
+	scheduleAPI.index = newMovieIndex()
+	for _, slot := range snapshot.Slots(&scheduleAPI.weeklySchedule) {
+		scheduleAPI.index.update(slot, snapshot.Lookup(&scheduleAPI.weeklySchedule, slot))
+	}
+	for slot := range scheduleAPI.ledger.votes {
+		scheduleAPI.ledger.prune(slot, snapshot.Lookup(&scheduleAPI.weeklySchedule, slot))
+	}
+	for slot := range scheduleAPI.ledger.credits {
+		scheduleAPI.ledger.prune(slot, snapshot.Lookup(&scheduleAPI.weeklySchedule, slot))
+	}
+}
+
+// updates the movie index after the movies of a show have changed and
+// discards votes for movies no longer in the show.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) reindexShow(weekDay, show int32, screen string) {
+	slot := snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: show}
+	showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+	scheduleAPI.ledger.prune(slot, showSchedule)
+	if showSchedule == nil {
+		scheduleAPI.index.remove(slot)
+		return
+	}
+	scheduleAPI.index.update(slot, showSchedule)
+}
+
+// FindMovieShows returns every show a movie is the showing movie or a voted movie in,
+// together with its votes and rank in the show
+func (scheduleAPI *scheduleAPIServer) FindMovieShows(
+	ctx context.Context, findReq *scheduler.FindMovieShowsRequest,
+) (*scheduler.FindMovieShowsResponse, error) {
+	movieID := findReq.GetMovieId()
+	if strings.Trim(movieID, " ") == "" {
+		return nil, errMissingCredential("Movie Id")
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	slots := scheduleAPI.index.lookup(movieID)
+
+	movieShows := make([]*scheduler.MovieShow, 0, len(slots))
+	for _, slot := range slots {
+		showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+		movieShow := movieInShow(showSchedule, movieID)
+		if movieShow == nil {
+			continue
+		}
+		movieShow.WeekDay = slot.WeekDay
+		movieShow.Screen = slot.Screen
+		movieShow.Show = slot.Show
+		movieShows = append(movieShows, movieShow)
+	}
+
+	return &scheduler.FindMovieShowsResponse{
+		Shows: movieShows,
+	}, nil
+}
+
+// returns the role, votes and rank of a movie in a show, or nil if it is not in the show
+func movieInShow(showSchedule *scheduler.ShowSchedule, movieID string) *scheduler.MovieShow {
+	var movieShow *scheduler.MovieShow
+	switch {
+	case showSchedule.GetMovie().GetId() == movieID:
+		movieShow = &scheduler.MovieShow{
+			Role:  scheduler.MovieShowRole_SHOWING,
+			Votes: showSchedule.GetMovie().GetCurrentVotes(),
+		}
+	default:
+		for _, votedMovie := range showSchedule.GetVotedMovies() {
+			if votedMovie.GetId() == movieID {
+				movieShow = &scheduler.MovieShow{
+					Role:  scheduler.MovieShowRole_NOMINATED,
+					Votes: votedMovie.GetCurrentVotes(),
+				}
+				break
+			}
+		}
+	}
+	if movieShow == nil {
+		return nil
+	}
+
+	// Rank by the number of movies in the show with more votes
+	movieShow.Rank = 1
+	for _, movieItem := range append(showSchedule.GetVotedMovies(), showSchedule.GetMovie()) {
+		if movieItem.GetId() != "" && movieItem.GetCurrentVotes() > movieShow.Votes {
+			movieShow.Rank++
+		}
+	}
+	movieShow.PlayTime = showSchedule.GetPlayTime()
+
+	return movieShow
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+func TestIndexAfterMutations(t *testing.T) {
+	from := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	to := snapshot.Slot{WeekDay: 2, Screen: "B", Show: 2}
+	ctx := userContext("p1", auth.RoleProgrammer)
+
+	tests := []struct {
+		name       string
+		mutate     func(scheduleAPI *scheduleAPIServer) error
+		wantShows  map[string][]snapshot.Slot // shows found for each movie
+		wantLedger map[snapshot.Slot]int32    // votes in the ledger for each show
+	}{
+		{
+			name: "move",
+			mutate: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.MoveMovieDaySchedule(ctx, &scheduler.MoveMovieDayScheduleRequest{
+					WeekDay: from.WeekDay, Show: from.Show, Screen: from.Screen, MovieId: "m1",
+					ToWeekDay: to.WeekDay, ToShow: to.Show, ToScreen: to.Screen,
+				})
+				return err
+			},
+			wantShows:  map[string][]snapshot.Slot{"m1": {to}, "v1": {to}},
+			wantLedger: map[snapshot.Slot]int32{to: 2},
+		},
+		{
+			name: "swap",
+			mutate: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.SwapMovieDaySchedules(ctx, &scheduler.SwapMovieDaySchedulesRequest{
+					WeekDay: from.WeekDay, Show: from.Show, Screen: from.Screen,
+					OtherWeekDay: to.WeekDay, OtherShow: to.Show, OtherScreen: to.Screen,
+				})
+				return err
+			},
+			wantShows:  map[string][]snapshot.Slot{"m1": {to}, "v1": {to}},
+			wantLedger: map[snapshot.Slot]int32{to: 2},
+		},
+		{
+			name: "remove voted movie",
+			mutate: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.RemoveVotedMovie(ctx, &scheduler.RemoveVotedMovieRequest{
+					WeekDay: from.WeekDay, Show: from.Show, Screen: from.Screen, MovieId: "v1",
+				})
+				return err
+			},
+			wantShows:  map[string][]snapshot.Slot{"m1": {from}, "v1": {}},
+			wantLedger: map[snapshot.Slot]int32{from: 1},
+		},
+		{
+			name: "publish moved draft",
+			mutate: func(scheduleAPI *scheduleAPIServer) error {
+				_, err := scheduleAPI.MoveMovieDaySchedule(ctx, &scheduler.MoveMovieDayScheduleRequest{
+					WeekDay: from.WeekDay, Show: from.Show, Screen: from.Screen, MovieId: "m1",
+					ToWeekDay: to.WeekDay, ToShow: to.Show, ToScreen: to.Screen, Draft: true,
+				})
+				if err != nil {
+					return err
+				}
+				_, err = scheduleAPI.PublishDraft(ctx, &scheduler.PublishDraftRequest{})
+				return err
+			},
+			wantShows: map[string][]snapshot.Slot{"m1": {to}, "v1": {to}},
+			// Customers did not vote in the draft, so the votes left in the published show are discarded
+			wantLedger: map[snapshot.Slot]int32{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedShow(scheduleAPI, from)
+
+			err := tt.mutate(scheduleAPI)
+			if err != nil {
+				t.Fatalf("mutation failed: %v", err)
+			}
+
+			for movieID, want := range tt.wantShows {
+				res, err := scheduleAPI.FindMovieShows(context.Background(), &scheduler.FindMovieShowsRequest{
+					MovieId: movieID,
+				})
+				if err != nil {
+					t.Fatalf("FindMovieShows(%q) failed: %v", movieID, err)
+				}
+				got := make([]snapshot.Slot, 0, len(res.GetShows()))
+				for _, movieShow := range res.GetShows() {
+					got = append(got, snapshot.Slot{
+						WeekDay: movieShow.GetWeekDay(), Screen: movieShow.GetScreen(), Show: movieShow.GetShow(),
+					})
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("shows of %q = %v, want %v", movieID, got, want)
+				}
+			}
+
+			if len(scheduleAPI.ledger.votes) != len(tt.wantLedger) {
+				t.Errorf("ledger = %v, want votes in %v", scheduleAPI.ledger.votes, tt.wantLedger)
+			}
+			for slot, want := range tt.wantLedger {
+				var got int32
+				for movieID := range scheduleAPI.ledger.votes[slot] {
+					got += scheduleAPI.ledger.count(slot, movieID)
+				}
+				if got != want {
+					t.Errorf("ledger votes in %v = %d, want %d", slot, got, want)
+				}
+			}
+
+			// The index kept up to date by every mutation matches one built from scratch
+			index := scheduleAPI.index
+			scheduleAPI.reindex()
+			if !reflect.DeepEqual(index, scheduleAPI.index) {
+				t.Errorf("index = %v, want %v", index, scheduleAPI.index)
+			}
+		})
+	}
+}
+
+func TestReindexPrunesLedger(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	removed := snapshot.Slot{WeekDay: 1, Screen: "C", Show: 1}
+
+	scheduleAPI := newTestServer(t)
+	setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+		PlayTime:    "10:00",
+		Movie:       &movie.Movie{Id: "m1", CurrentVotes: 1},
+		VotedMovies: []*movie.Movie{{Id: "v1", CurrentVotes: 1}},
+	})
+	scheduleAPI.ledger.record(slot, "m1", "u1", 1)
+	scheduleAPI.ledger.record(slot, "v1", "u1", 1)
+	scheduleAPI.ledger.record(slot, "gone", "u1", 2)
+	scheduleAPI.ledger.record(removed, "m1", "u2", 1)
+	scheduleAPI.ledger.credit(slot, map[string]int32{"u1": 1})
+	scheduleAPI.ledger.credit(removed, map[string]int32{"u2": 1})
+
+	scheduleAPI.reindex()
+
+	want := map[snapshot.Slot]map[string]map[string]int32{
+		slot: {"m1": {"u1": 1}, "v1": {"u1": 1}},
+	}
+	if !reflect.DeepEqual(scheduleAPI.ledger.votes, want) {
+		t.Errorf("ledger votes = %v, want %v", scheduleAPI.ledger.votes, want)
+	}
+	wantCredits := map[snapshot.Slot]map[string]int32{slot: {"u1": 1}}
+	if !reflect.DeepEqual(scheduleAPI.ledger.credits, wantCredits) {
+		t.Errorf("ledger credits = %v, want %v", scheduleAPI.ledger.credits, wantCredits)
+	}
+	if shows := scheduleAPI.index.lookup("m1"); !reflect.DeepEqual(shows, []snapshot.Slot{slot}) {
+		t.Errorf("shows of m1 = %v, want %v", shows, []snapshot.Slot{slot})
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+)
+
+// voteLedger records the votes every user has cast for the movies in each show,
+// so that votes for a movie taken out of a show can be refunded to the users that cast them.
+// Refunded votes are credited to the user in the show and cast again with the user's next vote in it.
+// It is saved alongside the weekly schedule; votes restored without it have no voters and cannot be refunded.
+type voteLedger struct {
+	votes   map[snapshot.Slot]map[string]map[string]int32 // show to movie id to user id to votes
+	credits map[snapshot.Slot]map[string]int32            // show to user id to refunded votes
+}
+
+func newVoteLedger() *voteLedger {
+	return &voteLedger{
+		votes:   make(map[snapshot.Slot]map[string]map[string]int32),
+		credits: make(map[snapshot.Slot]map[string]int32),
+	}
+}
+
+// record records votes cast by a user for a movie in a show
+func (ledger *voteLedger) record(slot snapshot.Slot, movieID, userID string, votes int32) {
+	if ledger.votes[slot] == nil {
+		ledger.votes[slot] = make(map[string]map[string]int32)
+	}
+	if ledger.votes[slot][movieID] == nil {
+		ledger.votes[slot][movieID] = make(map[string]int32)
+	}
+	ledger.votes[slot][movieID][userID] += votes
+}
+
+// credit credits the votes refunded to users in a show
+func (ledger *voteLedger) credit(slot snapshot.Slot, userVotes map[string]int32) {
+	if len(userVotes) == 0 {
+		return
+	}
+	if ledger.credits[slot] == nil {
+		ledger.credits[slot] = make(map[string]int32)
+	}
+	for userID, votes := range userVotes {
+		ledger.credits[slot][userID] += votes
+	}
+}
+
+// redeem removes the votes credited to a user in a show, returning them
+func (ledger *voteLedger) redeem(slot snapshot.Slot, userID string) int32 {
+	votes := ledger.credits[slot][userID]
+	delete(ledger.credits[slot], userID)
+	if len(ledger.credits[slot]) == 0 {
+		delete(ledger.credits, slot)
+	}
+	return votes
+}
+
+// count returns the number of votes for a movie in a show whose voters are known
+func (ledger *voteLedger) count(slot snapshot.Slot, movieID string) int32 {
+	var votes int32
+	for _, userVotes := range ledger.votes[slot][movieID] {
+		votes += userVotes
+	}
+	return votes
+}
+
+// take removes the votes for a movie in a show, returning the votes of every user
+func (ledger *voteLedger) take(slot snapshot.Slot, movieID string) map[string]int32 {
+	userVotes := ledger.votes[slot][movieID]
+	delete(ledger.votes[slot], movieID)
+	if len(ledger.votes[slot]) == 0 {
+		delete(ledger.votes, slot)
+	}
+	return userVotes
+}
+
+// refunds returns the votes taken out of the ledger as refunds to the users that cast them,
+// in user id order
+func refunds(userVotes map[string]int32) []*scheduler.VoteRefund {
+	refunds := make([]*scheduler.VoteRefund, 0, len(userVotes))
+	for userID, votes := range userVotes {
+		refunds = append(refunds, &scheduler.VoteRefund{
+			UserId: userID,
+			Votes:  votes,
+		})
+	}
+	sort.Slice(refunds, func(i, j int) bool { return refunds[i].UserId < refunds[j].UserId })
+	return refunds
+}
+
+// prune discards the votes for movies no longer in a show, and the credits in a show that no longer exists
+func (ledger *voteLedger) prune(slot snapshot.Slot, showSchedule *scheduler.ShowSchedule) {
+	for movieID := range ledger.votes[slot] {
+		if !inShow(showSchedule, movieID) {
+			ledger.take(slot, movieID)
+		}
+	}
+	if showSchedule == nil {
+		delete(ledger.credits, slot)
+	}
+}
+
+// move moves the votes for the movies in a show, and the credits in it, to another show
+func (ledger *voteLedger) move(from, to snapshot.Slot) {
+	if votes, ok := ledger.votes[from]; ok {
+		ledger.votes[to] = votes
+		delete(ledger.votes, from)
+	}
+	if credits, ok := ledger.credits[from]; ok {
+		ledger.credits[to] = credits
+		delete(ledger.credits, from)
+	}
+}
+
+// swap swaps the votes for the movies in two shows, and the credits in them
+func (ledger *voteLedger) swap(slot, other snapshot.Slot) {
+	votes, ok := ledger.votes[slot]
+	otherVotes, otherOk := ledger.votes[other]
+	delete(ledger.votes, slot)
+	delete(ledger.votes, other)
+	if ok {
+		ledger.votes[other] = votes
+	}
+	if otherOk {
+		ledger.votes[slot] = otherVotes
+	}
+
+	credits, ok := ledger.credits[slot]
+	otherCredits, otherOk := ledger.credits[other]
+	delete(ledger.credits, slot)
+	delete(ledger.credits, other)
+	if ok {
+		ledger.credits[other] = credits
+	}
+	if otherOk {
+		ledger.credits[slot] = otherCredits
+	}
+}
+
+// clone returns a copy of the ledger
+func (ledger *voteLedger) clone() *voteLedger {
+	cloned := newVoteLedger()
+	for slot, movieVotes := range ledger.votes {
+		cloned.votes[slot] = make(map[string]map[string]int32, len(movieVotes))
+		for movieID, userVotes := range movieVotes {
+			cloned.votes[slot][movieID] = make(map[string]int32, len(userVotes))
+			for userID, votes := range userVotes {
+				cloned.votes[slot][movieID][userID] = votes
+			}
+		}
+	}
+	for slot, userCredits := range ledger.credits {
+		cloned.credits[slot] = make(map[string]int32, len(userCredits))
+		for userID, votes := range userCredits {
+			cloned.credits[slot][userID] = votes
+		}
+	}
+	return cloned
+}
+
+// savedVotes are the votes a user has cast for a movie in a show, in the form they are saved
+type savedVotes struct {
+	WeekDay int32  `json:"week_day"`
+	Screen  string `json:"screen"`
+	Show    int32  `json:"show"`
+	MovieID string `json:"movie_id"`
+	UserID  string `json:"user_id"`
+	Votes   int32  `json:"votes"`
+}
+
+// savedCredit are the votes refunded to a user in a show, in the form they are saved
+type savedCredit struct {
+	WeekDay int32  `json:"week_day"`
+	Screen  string `json:"screen"`
+	Show    int32  `json:"show"`
+	UserID  string `json:"user_id"`
+	Votes   int32  `json:"votes"`
+}
+
+// savedLedger is the form in which the ledger is saved alongside the weekly schedule
+type savedLedger struct {
+	Votes   []*savedVotes  `json:"votes"`
+	Credits []*savedCredit `json:"credits"`
+}
+
+func (ledger *voteLedger) marshal() ([]byte, error) {
+	saved := savedLedger{
+		Votes:   make([]*savedVotes, 0),
+		Credits: make([]*savedCredit, 0),
+	}
+	for slot, movieVotes := range ledger.votes {
+		for movieID, userVotes := range movieVotes {
+			for userID, votes := range userVotes {
+				saved.Votes = append(saved.Votes, &savedVotes{
+					WeekDay: slot.WeekDay,
+					Screen:  slot.Screen,
+					Show:    slot.Show,
+					MovieID: movieID,
+					UserID:  userID,
+					Votes:   votes,
+				})
+			}
+		}
+	}
+	for slot, userCredits := range ledger.credits {
+		for userID, votes := range userCredits {
+			saved.Credits = append(saved.Credits, &savedCredit{
+				WeekDay: slot.WeekDay,
+				Screen:  slot.Screen,
+				Show:    slot.Show,
+				UserID:  userID,
+				Votes:   votes,
+			})
+		}
+	}
+	return json.Marshal(saved)
+}
+
+func (ledger *voteLedger) unmarshal(bs []byte) error {
+	saved := savedLedger{}
+	err := json.Unmarshal(bs, &saved)
+	if err != nil {
+		return err
+	}
+	unmarshaled := newVoteLedger()
+	for _, votes := range saved.Votes {
+		slot := snapshot.Slot{WeekDay: votes.WeekDay, Screen: votes.Screen, Show: votes.Show}
+		if unmarshaled.votes[slot] == nil {
+			unmarshaled.votes[slot] = make(map[string]map[string]int32)
+		}
+		if unmarshaled.votes[slot][votes.MovieID] == nil {
+			unmarshaled.votes[slot][votes.MovieID] = make(map[string]int32)
+		}
+		unmarshaled.votes[slot][votes.MovieID][votes.UserID] += votes.Votes
+	}
+	for _, credit := range saved.Credits {
+		slot := snapshot.Slot{WeekDay: credit.WeekDay, Screen: credit.Screen, Show: credit.Show}
+		unmarshaled.credit(slot, map[string]int32{credit.UserID: credit.Votes})
+	}
+	*ledger = *unmarshaled
+	return nil
+}
+
+// checks whether a movie is the showing movie or a voted movie of a show
+func inShow(showSchedule *scheduler.ShowSchedule, movieID string) bool {
+	if showSchedule.GetMovie().GetId() == movieID {
+		return true
+	}
+	for _, votedMovie := range showSchedule.GetVotedMovies() {
+		if votedMovie.GetId() == movieID {
+			return true
+		}
+	}
+	return false
+}
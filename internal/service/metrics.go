@@ -0,0 +1,66 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels are limited to screens and shows that exist in the schedule so that cardinality stays bounded
+var (
+	votesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rupacinema",
+			Subsystem: "scheduling",
+			Name:      "votes_total",
+			Help:      "Number of votes cast per screen and show.",
+		},
+		[]string{"screen", "show"},
+	)
+
+	swapsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rupacinema",
+			Subsystem: "scheduling",
+			Name:      "movie_swaps_total",
+			Help:      "Number of times a voted movie replaced the showing movie per screen and show.",
+		},
+		[]string{"screen", "show"},
+	)
+
+	snapshotDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "rupacinema",
+			Subsystem: "scheduling",
+			Name:      "snapshot_duration_seconds",
+			Help:      "Time taken to write a snapshot of the weekly schedule.",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+
+	snapshotFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "rupacinema",
+			Subsystem: "scheduling",
+			Name:      "snapshot_failures_total",
+			Help:      "Number of snapshots of the weekly schedule that failed to be written.",
+		},
+	)
+
+	movieRefreshFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "rupacinema",
+			Subsystem: "scheduling",
+			Name:      "movie_refresh_failures_total",
+			Help:      "Number of GetMovie calls that failed while refreshing movies in the schedule.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		votesCounter,
+		swapsCounter,
+		snapshotDuration,
+		snapshotFailures,
+		movieRefreshFailures,
+	)
+}
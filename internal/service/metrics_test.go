@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// failingStore fails every save
+type failingStore struct {
+	SnapshotStore
+}
+
+func (failingStore) Save([]byte) error {
+	return errors.New("disk full")
+}
+
+// returns the number of snapshots whose duration was observed
+func snapshotsObserved(t *testing.T) uint64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == "rupacinema_scheduling_snapshot_duration_seconds" {
+			return family.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	t.Fatal("snapshot duration is not registered")
+	return 0
+}
+
+func TestVoteMetrics(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	scheduleAPI := newTestServer(t)
+	setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+		PlayTime:    "10:00",
+		Movie:       &movie.Movie{Id: "m1", CurrentVotes: 1},
+		VotedMovies: []*movie.Movie{{Id: "v1"}},
+	})
+	scheduleAPI.reindex()
+
+	votes := testutil.ToFloat64(votesCounter.WithLabelValues("A", "1"))
+	swaps := testutil.ToFloat64(swapsCounter.WithLabelValues("A", "1"))
+	voteSeries, swapSeries := testutil.CollectAndCount(votesCounter), testutil.CollectAndCount(swapsCounter)
+
+	tests := []struct {
+		screen  string
+		movieID string
+		wantErr bool
+	}{
+		{screen: "A", movieID: "v1"},
+		{screen: "A", movieID: "v1"}, // v1 overtakes m1
+		{screen: "A", movieID: "m1"},
+		{screen: "Z", movieID: "m1", wantErr: true},
+		{screen: "A", movieID: "v9", wantErr: true},
+	}
+	for i, tt := range tests {
+		_, err := scheduleAPI.VoteUpMovie(userContext("u1"), &scheduler.VoteUpMovieRequest{
+			WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: tt.screen, MovieId: tt.movieID,
+		})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("vote %d error = %v, want error %t", i, err, tt.wantErr)
+		}
+	}
+
+	if got := testutil.ToFloat64(votesCounter.WithLabelValues("A", "1")) - votes; got != 3 {
+		t.Errorf("votes increased by %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(swapsCounter.WithLabelValues("A", "1")) - swaps; got != 1 {
+		t.Errorf("swaps increased by %v, want 1", got)
+	}
+	// Failed votes add no series, so labels are limited to shows that exist
+	if got := testutil.CollectAndCount(votesCounter); got != voteSeries {
+		t.Errorf("%d vote series, want %d", got, voteSeries)
+	}
+	if got := testutil.CollectAndCount(swapsCounter); got != swapSeries {
+		t.Errorf("%d swap series, want %d", got, swapSeries)
+	}
+}
+
+func TestSnapshotMetrics(t *testing.T) {
+	scheduleAPI := newTestServer(t)
+	failures := testutil.ToFloat64(snapshotFailures)
+	observed := snapshotsObserved(t)
+
+	scheduleAPI.opts.Store = NewMemoryStore()
+	err := scheduleAPI.saveSnapshot()
+	if err != nil {
+		t.Fatalf("saveSnapshot() failed: %v", err)
+	}
+	scheduleAPI.opts.Store = failingStore{}
+	err = scheduleAPI.saveSnapshot()
+	if err == nil {
+		t.Fatal("saveSnapshot() to a failing store succeeded")
+	}
+
+	if got := testutil.ToFloat64(snapshotFailures) - failures; got != 1 {
+		t.Errorf("snapshot failures increased by %v, want 1", got)
+	}
+	if got := snapshotsObserved(t) - observed; got != 2 {
+		t.Errorf("snapshot durations observed %d times, want 2", got)
+	}
+}
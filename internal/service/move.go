@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The Pseudocode:
+// 1. Validate the input fields from the request
+// 2. Get the remote movie
+// 3. Lock the mutex and defer unlock
+// 4. Check that the show is at the expected version, if the request has one
+// 5. Check that the movie is not already showing in the show
+// 6. Take the movie out of the voted movies if it was voted for, keeping its votes
+// 7. Replace the showing movie; the replaced movie is removed from the show
+// 8. Discard the votes for the replaced movie from the vote ledger
+// 9. Return success
+func (scheduleAPI *scheduleAPIServer) UpdateMovieDaySchedule(
+	ctx context.Context, updateReq *scheduler.UpdateMovieDayScheduleRequest,
+) (*empty.Empty, error) {
+	weekDay := updateReq.GetWeekDay()
+	screen := updateReq.GetScreen()
+	showNumber := updateReq.GetShow()
+	movieID := updateReq.GetMovieId()
+
+	// Validate the input fields from request; they are those of a request creating the show
+	err := validateCreateMovieDaySchedule(&scheduler.CreateMovieDayScheduleRequest{
+		WeekDay: weekDay,
+		Show:    showNumber,
+		Screen:  screen,
+		MovieId: movieID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "UpdateMovieDaySchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	// The If-Match header is the expected version when the request has none
+	updateReq.ExpectedVersion, err = ifMatchVersion(ctx, updateReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the movie resource
+	movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
+		ctx,
+		&movie.GetMovieRequest{
+			MovieId: movieID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if updateReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "UpdateMovieDaySchedule")()
+	}
+
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return err if the show changed since the version the request expects
+	err = checkVersion(showSchedule, updateReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// Return err if it is already showing. Another showing movie is the one being replaced
+	if ok, _ := scheduleAPI.existInSchedule(weekDay, showNumber, screen, movieItem.Id); ok {
+		return nil, errMovieScheduleExist(movieItem.Id)
+	}
+
+	// A voted movie keeps the votes it already has
+	for index, votedMovie := range showSchedule.VotedMovies {
+		if votedMovie.Id == movieItem.Id {
+			movieItem.CurrentVotes = votedMovie.CurrentVotes
+			showSchedule.VotedMovies = append(
+				showSchedule.VotedMovies[:index], showSchedule.VotedMovies[index+1:]...,
+			)
+			break
+		}
+	}
+
+	showSchedule.Movie = movieItem
+
+	// Votes for the replaced movie are discarded with it
+	scheduleAPI.ledger.prune(snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: showNumber}, showSchedule)
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
+
+	return &empty.Empty{}, nil
+}
+
+// The Pseudocode:
+// 1. Validate the input fields from the request
+// 2. Lock the mutex and defer unlock
+// 3. Check that both shows are at the expected version, if the request has one
+// 4. Ensure that the movie is showing in the show being moved
+// 5. Ensure that the show it is moved to has no movie or voted movies
+// 6. Move the movie and voted movies together with their votes
+// 7. Return success
+func (scheduleAPI *scheduleAPIServer) MoveMovieDaySchedule(
+	ctx context.Context, moveReq *scheduler.MoveMovieDayScheduleRequest,
+) (*empty.Empty, error) {
+	// Validate the input fields from request
+	err := validateMoveMovieDaySchedule(moveReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "MoveMovieDaySchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	// The If-Match header is the expected version when the request has none
+	moveReq.ExpectedVersion, err = ifMatchVersion(ctx, moveReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if moveReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "MoveMovieDaySchedule")()
+	}
+
+	err = scheduleAPI.moveMovieDaySchedule(moveReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func validateMoveMovieDaySchedule(moveReq *scheduler.MoveMovieDayScheduleRequest) error {
+	var err error
+	switch {
+	case moveReq.GetWeekDay() <= 0 || moveReq.GetWeekDay() > 7:
+		err = errIncorrectVal("Week day")
+	case strings.Trim(moveReq.GetScreen(), " ") == "":
+		err = errMissingCredential("Screen")
+	case moveReq.GetShow() <= 0:
+		err = errIncorrectVal("Show number")
+	case strings.Trim(moveReq.GetMovieId(), " ") == "":
+		err = errMissingCredential("Movie ID")
+	case moveReq.GetToWeekDay() <= 0 || moveReq.GetToWeekDay() > 7:
+		err = errIncorrectVal("To week day")
+	case strings.Trim(moveReq.GetToScreen(), " ") == "":
+		err = errMissingCredential("To screen")
+	case moveReq.GetToShow() <= 0:
+		err = errIncorrectVal("To show number")
+	case moveReq.GetWeekDay() == moveReq.GetToWeekDay() &&
+		moveReq.GetScreen() == moveReq.GetToScreen() &&
+		moveReq.GetShow() == moveReq.GetToShow():
+		err = status.Error(codes.InvalidArgument, "cannot move a show to itself")
+	}
+	return err
+}
+
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) moveMovieDaySchedule(moveReq *scheduler.MoveMovieDayScheduleRequest) error {
+	weekDay := moveReq.GetWeekDay()
+	screen := moveReq.GetScreen()
+	showNumber := moveReq.GetShow()
+	movieID := moveReq.GetMovieId()
+	toWeekDay := moveReq.GetToWeekDay()
+	toScreen := moveReq.GetToScreen()
+	toShowNumber := moveReq.GetToShow()
+
+	// Return err if either show changed since the version the request expects
+	err := scheduleAPI.checkShowVersion(weekDay, showNumber, screen, moveReq.GetExpectedVersion())
+	if err != nil {
+		return err
+	}
+	err = scheduleAPI.checkShowVersion(toWeekDay, toShowNumber, toScreen, moveReq.GetToExpectedVersion())
+	if err != nil {
+		return err
+	}
+
+	// Ensure the movie exists in schedule
+	ok, err := scheduleAPI.existInSchedule(weekDay, showNumber, screen, movieID)
+	if err != nil {
+		return err
+	}
+
+	// Return err if it doesn't exist in schedule
+	if !ok {
+		return errNoMovieScheduleExist(movieID)
+	}
+
+	toShowSchedule, err := scheduleAPI.getShowSchedule(toWeekDay, toShowNumber, toScreen)
+	if err != nil {
+		return err
+	}
+
+	// Return err if the show has movies that would be overwritten
+	if hasMovies(toShowSchedule) {
+		return errShowNotEmpty(toWeekDay, toShowNumber, toScreen)
+	}
+
+	showSchedule, _ := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	toShowSchedule.Movie, showSchedule.Movie = showSchedule.Movie, &movie.Movie{}
+	toShowSchedule.VotedMovies, showSchedule.VotedMovies = showSchedule.VotedMovies, make([]*movie.Movie, 0)
+	scheduleAPI.ledger.move(
+		snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: showNumber},
+		snapshot.Slot{WeekDay: toWeekDay, Screen: toScreen, Show: toShowNumber},
+	)
+
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
+	scheduleAPI.showChanged(toWeekDay, toShowNumber, toScreen)
+
+	return nil
+}
+
+// The Pseudocode:
+// 1. Validate the input fields from the request
+// 2. Lock the mutex and defer unlock
+// 3. Check that both shows are at the expected version, if the request has one
+// 4. Get both shows
+// 5. Swap their movies and voted movies together with their votes; play times stay with the shows
+// 6. Return success
+func (scheduleAPI *scheduleAPIServer) SwapMovieDaySchedules(
+	ctx context.Context, swapReq *scheduler.SwapMovieDaySchedulesRequest,
+) (*empty.Empty, error) {
+	weekDay := swapReq.GetWeekDay()
+	screen := swapReq.GetScreen()
+	showNumber := swapReq.GetShow()
+	otherWeekDay := swapReq.GetOtherWeekDay()
+	otherScreen := swapReq.GetOtherScreen()
+	otherShowNumber := swapReq.GetOtherShow()
+
+	// Validate the input fields from request
+	err := func() error {
+		var err error
+		switch {
+		case weekDay <= 0 || weekDay > 7:
+			err = errIncorrectVal("Week day")
+		case strings.Trim(screen, " ") == "":
+			err = errMissingCredential("Screen")
+		case showNumber <= 0:
+			err = errIncorrectVal("Show number")
+		case otherWeekDay <= 0 || otherWeekDay > 7:
+			err = errIncorrectVal("Other week day")
+		case strings.Trim(otherScreen, " ") == "":
+			err = errMissingCredential("Other screen")
+		case otherShowNumber <= 0:
+			err = errIncorrectVal("Other show number")
+		case weekDay == otherWeekDay && screen == otherScreen && showNumber == otherShowNumber:
+			err = status.Error(codes.InvalidArgument, "cannot swap a show with itself")
+		}
+		return err
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "SwapMovieDaySchedules")
+	if err != nil {
+		return nil, err
+	}
+
+	// The If-Match header is the expected version when the request has none
+	swapReq.ExpectedVersion, err = ifMatchVersion(ctx, swapReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if swapReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "SwapMovieDaySchedules")()
+	}
+
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	if err != nil {
+		return nil, err
+	}
+
+	otherShowSchedule, err := scheduleAPI.getShowSchedule(otherWeekDay, otherShowNumber, otherScreen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return err if either show changed since the version the request expects
+	err = checkVersion(showSchedule, swapReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+	err = checkVersion(otherShowSchedule, swapReq.GetOtherExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	showSchedule.Movie, otherShowSchedule.Movie = otherShowSchedule.Movie, showSchedule.Movie
+	showSchedule.VotedMovies, otherShowSchedule.VotedMovies = otherShowSchedule.VotedMovies, showSchedule.VotedMovies
+	scheduleAPI.ledger.swap(
+		snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: showNumber},
+		snapshot.Slot{WeekDay: otherWeekDay, Screen: otherScreen, Show: otherShowNumber},
+	)
+
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
+	scheduleAPI.showChanged(otherWeekDay, otherShowNumber, otherScreen)
+
+	return &empty.Empty{}, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// plays m1 with v1 voted in the slot of a test server, with a vote by u1 for each movie
+func seedShow(scheduleAPI *scheduleAPIServer, slot snapshot.Slot) {
+	setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+		PlayTime:    "10:00",
+		Movie:       &movie.Movie{Id: "m1", CurrentVotes: 1},
+		VotedMovies: []*movie.Movie{{Id: "v1", CurrentVotes: 1}},
+		Version:     1,
+	})
+	scheduleAPI.ledger.record(slot, "m1", "u1", 1)
+	scheduleAPI.ledger.record(slot, "v1", "u1", 1)
+	scheduleAPI.reindex()
+}
+
+func TestUpdateMovieDaySchedule(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name       string
+		req        *scheduler.UpdateMovieDayScheduleRequest
+		wantCode   codes.Code
+		wantMovie  string
+		wantVotes  int32
+		wantVoted  []string
+		wantLedger map[string]int32
+	}{
+		{
+			name:       "new movie replaces the showing movie",
+			req:        &scheduler.UpdateMovieDayScheduleRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "m2"},
+			wantMovie:  "m2",
+			wantVoted:  []string{"v1"},
+			wantLedger: map[string]int32{"m1": 0, "v1": 1, "m2": 0},
+		},
+		{
+			name:       "voted movie replaces the showing movie with its votes",
+			req:        &scheduler.UpdateMovieDayScheduleRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1"},
+			wantMovie:  "v1",
+			wantVotes:  1,
+			wantVoted:  []string{},
+			wantLedger: map[string]int32{"m1": 0, "v1": 1},
+		},
+		{
+			name:       "movie already showing",
+			req:        &scheduler.UpdateMovieDayScheduleRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "m1"},
+			wantCode:   codes.Unknown,
+			wantMovie:  "m1",
+			wantVotes:  1,
+			wantVoted:  []string{"v1"},
+			wantLedger: map[string]int32{"m1": 1, "v1": 1},
+		},
+		{
+			name:       "bad week day",
+			req:        &scheduler.UpdateMovieDayScheduleRequest{WeekDay: 8, Show: 1, Screen: "A", MovieId: "m2"},
+			wantCode:   codes.InvalidArgument,
+			wantMovie:  "m1",
+			wantVotes:  1,
+			wantVoted:  []string{"v1"},
+			wantLedger: map[string]int32{"m1": 1, "v1": 1},
+		},
+		{
+			name:       "missing movie",
+			req:        &scheduler.UpdateMovieDayScheduleRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "missing1"},
+			wantCode:   codes.NotFound,
+			wantMovie:  "m1",
+			wantVotes:  1,
+			wantVoted:  []string{"v1"},
+			wantLedger: map[string]int32{"m1": 1, "v1": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedShow(scheduleAPI, slot)
+
+			_, err := scheduleAPI.UpdateMovieDaySchedule(userContext("p1", auth.RoleProgrammer), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("UpdateMovieDaySchedule() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if got := showSchedule.GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+			if got := showSchedule.GetMovie().GetCurrentVotes(); got != tt.wantVotes {
+				t.Errorf("movie votes = %d, want %d", got, tt.wantVotes)
+			}
+			if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, tt.wantVoted) {
+				t.Errorf("voted movies = %v, want %v", got, tt.wantVoted)
+			}
+			for movieID, want := range tt.wantLedger {
+				if got := scheduleAPI.ledger.count(slot, movieID); got != want {
+					t.Errorf("ledger votes for %q = %d, want %d", movieID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveMovieDaySchedule(t *testing.T) {
+	from := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	to := snapshot.Slot{WeekDay: 2, Screen: "B", Show: 2}
+
+	tests := []struct {
+		name     string
+		req      *scheduler.MoveMovieDayScheduleRequest
+		occupied bool // whether the show moved to has a movie
+		wantCode codes.Code
+		wantSlot snapshot.Slot // slot playing m1 afterwards
+	}{
+		{
+			name: "moved with votes",
+			req: &scheduler.MoveMovieDayScheduleRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "m1", ToWeekDay: 2, ToShow: 2, ToScreen: "B",
+			},
+			wantSlot: to,
+		},
+		{
+			name: "show moved to is not empty",
+			req: &scheduler.MoveMovieDayScheduleRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "m1", ToWeekDay: 2, ToShow: 2, ToScreen: "B",
+			},
+			occupied: true,
+			wantCode: codes.FailedPrecondition,
+			wantSlot: from,
+		},
+		{
+			name: "movie is not showing",
+			req: &scheduler.MoveMovieDayScheduleRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "m2", ToWeekDay: 2, ToShow: 2, ToScreen: "B",
+			},
+			wantCode: codes.Unknown,
+			wantSlot: from,
+		},
+		{
+			name: "moved to itself",
+			req: &scheduler.MoveMovieDayScheduleRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "m1", ToWeekDay: 1, ToShow: 1, ToScreen: "A",
+			},
+			wantCode: codes.InvalidArgument,
+			wantSlot: from,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedShow(scheduleAPI, from)
+			if tt.occupied {
+				setShow(&scheduleAPI.weeklySchedule, to.WeekDay, to.Screen, to.Show, &scheduler.ShowSchedule{
+					PlayTime:    "14:00",
+					Movie:       &movie.Movie{Id: "m2"},
+					VotedMovies: make([]*movie.Movie, 0),
+					Version:     1,
+				})
+				scheduleAPI.reindex()
+			}
+
+			_, err := scheduleAPI.MoveMovieDaySchedule(userContext("p1", auth.RoleProgrammer), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("MoveMovieDaySchedule() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, tt.wantSlot)
+			if got := showSchedule.GetMovie().GetId(); got != "m1" {
+				t.Fatalf("movie in %s = %q, want %q", tt.wantSlot, got, "m1")
+			}
+			if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, []string{"v1"}) {
+				t.Errorf("voted movies in %s = %v, want [v1]", tt.wantSlot, got)
+			}
+			if got := showSchedule.GetPlayTime(); got != scheduleAPI.opts.Shows[tt.wantSlot.Show-1].PlayTime {
+				t.Errorf("play time in %s = %q", tt.wantSlot, got)
+			}
+			for _, movieID := range []string{"m1", "v1"} {
+				if got := scheduleAPI.ledger.count(tt.wantSlot, movieID); got != 1 {
+					t.Errorf("ledger votes for %q in %s = %d, want 1", movieID, tt.wantSlot, got)
+				}
+			}
+			if got := scheduleAPI.index.lookup("m1"); len(got) != 1 || got[0] != tt.wantSlot {
+				t.Errorf("m1 is indexed in %v, want %s", got, tt.wantSlot)
+			}
+		})
+	}
+}
+
+func TestSwapMovieDaySchedules(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	other := snapshot.Slot{WeekDay: 1, Screen: "B", Show: 2}
+
+	tests := []struct {
+		name           string
+		req            *scheduler.SwapMovieDaySchedulesRequest
+		wantCode       codes.Code
+		wantMovie      string
+		wantOtherMovie string
+	}{
+		{
+			name: "swapped with votes",
+			req: &scheduler.SwapMovieDaySchedulesRequest{
+				WeekDay: 1, Show: 1, Screen: "A", OtherWeekDay: 1, OtherShow: 2, OtherScreen: "B",
+			},
+			wantMovie:      "",
+			wantOtherMovie: "m1",
+		},
+		{
+			name: "other show changed since",
+			req: &scheduler.SwapMovieDaySchedulesRequest{
+				WeekDay: 1, Show: 1, Screen: "A", OtherWeekDay: 1, OtherShow: 2, OtherScreen: "B",
+				OtherExpectedVersion: 7,
+			},
+			wantCode:       codes.Aborted,
+			wantMovie:      "m1",
+			wantOtherMovie: "",
+		},
+		{
+			name: "swapped with itself",
+			req: &scheduler.SwapMovieDaySchedulesRequest{
+				WeekDay: 1, Show: 1, Screen: "A", OtherWeekDay: 1, OtherShow: 1, OtherScreen: "A",
+			},
+			wantCode:       codes.InvalidArgument,
+			wantMovie:      "m1",
+			wantOtherMovie: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedShow(scheduleAPI, slot)
+
+			_, err := scheduleAPI.SwapMovieDaySchedules(userContext("p1", auth.RoleProgrammer), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("SwapMovieDaySchedules() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			for s, want := range map[snapshot.Slot]string{slot: tt.wantMovie, other: tt.wantOtherMovie} {
+				showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, s)
+				if got := showSchedule.GetMovie().GetId(); got != want {
+					t.Errorf("movie in %s = %q, want %q", s, got, want)
+				}
+				if got := showSchedule.GetPlayTime(); got != scheduleAPI.opts.Shows[s.Show-1].PlayTime {
+					t.Errorf("play time in %s = %q", s, got)
+				}
+				wantVotes := int32(0)
+				if want == "m1" {
+					wantVotes = 1
+				}
+				if got := scheduleAPI.ledger.count(s, "v1"); got != wantVotes {
+					t.Errorf("ledger votes for v1 in %s = %d, want %d", s, got, wantVotes)
+				}
+			}
+		})
+	}
+}
+
+func movieIDs(movies []*movie.Movie) []string {
+	ids := make([]string, 0, len(movies))
+	for _, movieItem := range movies {
+		ids = append(ids, movieItem.GetId())
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
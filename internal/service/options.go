@@ -0,0 +1,207 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gidyon/rupacinema/scheduling/internal/idempotency"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"strings"
+	"time"
+)
+
+// Show is a show played on every screen each day
+type Show struct {
+	ID       int32
+	PlayTime string
+}
+
+// Options configures the show scheduler
+type Options struct {
+	// Screens available at the cinema
+	Screens []string
+	// Shows played on every screen each day
+	Shows []Show
+	// MaxMoviesVoted is how many movies can be nominated for a show
+	MaxMoviesVoted int
+	// SnapshotInterval is how often the weekly schedule is saved
+	SnapshotInterval time.Duration
+	// MovieRefreshInterval is how often movies are refreshed from the movie service
+	MovieRefreshInterval time.Duration
+	// Store keeps snapshots of the weekly schedule
+	Store SnapshotStore
+	// Idempotency keeps results of requests made with an idempotency key. Optional
+	Idempotency *idempotency.Cache
+	// IdempotencyStore keeps the results of Idempotency, saved alongside the weekly schedule
+	IdempotencyStore SnapshotStore
+	// DraftStore keeps the draft schedule, saved alongside the weekly schedule. Optional
+	DraftStore SnapshotStore
+	// HistorySize is how many changes to the weekly schedule are kept in the change history
+	HistorySize int
+	// HistoryStore keeps the change history, saved alongside the weekly schedule. Optional
+	HistoryStore SnapshotStore
+	// LedgerStore keeps the vote ledger, saved alongside the weekly schedule. Optional
+	LedgerStore SnapshotStore
+	// HistoryArchiveSize is how many changes dropped from the change history are kept in HistoryArchive,
+	// so that the schedule can be restored to times older than the change history
+	HistoryArchiveSize int
+	// HistoryArchive keeps the changes dropped from the change history, saved alongside the weekly schedule.
+	// Optional
+	HistoryArchive SnapshotStore
+}
+
+func (opts *Options) validate() error {
+	switch {
+	case len(opts.Screens) == 0:
+		return errors.New("at least one screen is required")
+	case len(opts.Shows) == 0:
+		return errors.New("at least one show is required")
+	case opts.MaxMoviesVoted <= 0:
+		return errors.New("maximum voted movies must be positive")
+	case opts.SnapshotInterval <= 0:
+		return errors.New("snapshot interval must be positive")
+	case opts.MovieRefreshInterval <= 0:
+		return errors.New("movie refresh interval must be positive")
+	case opts.HistorySize < 0:
+		return errors.New("history size must not be negative")
+	case opts.HistoryArchiveSize < 0:
+		return errors.New("history archive size must not be negative")
+	case opts.Store == nil:
+		return errors.New("snapshot store is required")
+	case opts.Idempotency != nil && opts.IdempotencyStore == nil:
+		return errors.New("idempotency store is required")
+	}
+	return nil
+}
+
+// checks whether changes dropped from the change history are archived
+func (opts *Options) archiving() bool {
+	return opts.HistoryArchive != nil && opts.HistoryArchiveSize > 0
+}
+
+// returns the options in use. Safe for concurrent use
+func (scheduleAPI *scheduleAPIServer) options() Options {
+	scheduleAPI.muSchedule.Lock()
+	defer scheduleAPI.muSchedule.Unlock()
+	return scheduleAPI.opts
+}
+
+// Reconfigure applies new options to the running scheduler atomically.
+// Screens and shows that are removed must not have a scheduled or voted movie,
+// and no show may have more voted movies than the new maximum; otherwise the
+// options are rejected with an error explaining why and nothing is changed.
+// The draft, if any, is changed in the same way, and the changes to the weekly schedule are recorded.
+// The snapshot, idempotency, draft, history, ledger and history archive stores cannot be changed
+// while the scheduler is running.
+func (scheduleAPI *scheduleAPIServer) Reconfigure(opts Options) error {
+	scheduleAPI.muSchedule.Lock()
+	defer scheduleAPI.muSchedule.Unlock()
+
+	opts.Store = scheduleAPI.opts.Store
+	opts.Idempotency = scheduleAPI.opts.Idempotency
+	opts.IdempotencyStore = scheduleAPI.opts.IdempotencyStore
+	opts.DraftStore = scheduleAPI.opts.DraftStore
+	opts.HistoryStore = scheduleAPI.opts.HistoryStore
+	opts.LedgerStore = scheduleAPI.opts.LedgerStore
+	opts.HistoryArchive = scheduleAPI.opts.HistoryArchive
+	err := opts.validate()
+	if err != nil {
+		return err
+	}
+
+	screens := make(map[string]bool, len(opts.Screens))
+	for _, screen := range opts.Screens {
+		screens[screen] = true
+	}
+	shows := make(map[int32]bool, len(opts.Shows))
+	for _, show := range opts.Shows {
+		shows[show.ID] = true
+	}
+
+	// Find schedules that would be orphaned by the new options
+	orphans := scheduleAPI.orphans(opts, screens, shows)
+	if scheduleAPI.draft != nil {
+		restore := scheduleAPI.useDraft()
+		for _, orphan := range scheduleAPI.orphans(opts, screens, shows) {
+			orphans = append(orphans, "draft: "+orphan)
+		}
+		restore()
+	}
+	if len(orphans) != 0 {
+		return fmt.Errorf("options would orphan existing schedules: %s", strings.Join(orphans, "; "))
+	}
+
+	scheduleAPI.opts = opts
+	scheduleAPI.history.archive = opts.archiving()
+	scheduleAPI.history.resize(opts.HistorySize)
+	record := scheduleAPI.recordChanges(scheduleAPI.ctx, "Reconfigure")
+	scheduleAPI.resize(screens, shows)
+	record()
+	if scheduleAPI.draft != nil {
+		restore := scheduleAPI.useDraft()
+		scheduleAPI.resize(screens, shows)
+		restore()
+	}
+
+	return nil
+}
+
+// returns the schedules that would be orphaned by the options
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) orphans(opts Options, screens map[string]bool, shows map[int32]bool) []string {
+	orphans := make([]string, 0)
+	for _, weekDay := range weekDays {
+		daySchedule, ok := scheduleAPI.weeklySchedule.DaysSchedule[weekDay]
+		if !ok {
+			continue
+		}
+		for screen, screenSchedule := range daySchedule.ScreensSchedule {
+			for showID, showSchedule := range screenSchedule.ShowsSchedule {
+				switch {
+				case !screens[screen] && hasMovies(showSchedule):
+					orphans = append(orphans, fmt.Sprintf(
+						"screen %q removed but has movies on day %d show %d", screen, weekDay, showID,
+					))
+				case !shows[showID] && hasMovies(showSchedule):
+					orphans = append(orphans, fmt.Sprintf(
+						"show %d removed but has movies on day %d screen %q", showID, weekDay, screen,
+					))
+				case len(showSchedule.VotedMovies) > opts.MaxMoviesVoted:
+					orphans = append(orphans, fmt.Sprintf(
+						"day %d screen %q show %d has %d voted movies, more than the new maximum of %d",
+						weekDay, screen, showID, len(showSchedule.VotedMovies), opts.MaxMoviesVoted,
+					))
+				}
+			}
+		}
+	}
+	return orphans
+}
+
+// removes the empty slots of screens and shows no longer in use and adds the slots of new ones
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) resize(screens map[string]bool, shows map[int32]bool) {
+	for _, daySchedule := range scheduleAPI.weeklySchedule.DaysSchedule {
+		for screen, screenSchedule := range daySchedule.ScreensSchedule {
+			if !screens[screen] {
+				delete(daySchedule.ScreensSchedule, screen)
+				continue
+			}
+			for showID := range screenSchedule.ShowsSchedule {
+				if !shows[showID] {
+					delete(screenSchedule.ShowsSchedule, showID)
+				}
+			}
+		}
+	}
+
+	scheduleAPI.syncSlots()
+	scheduleAPI.reindex()
+}
+
+// checks whether a show has a scheduled or voted movie
+func hasMovies(showSchedule *scheduler.ShowSchedule) bool {
+	if showSchedule.Movie != nil && showSchedule.Movie.Id != "" {
+		return true
+	}
+	return len(showSchedule.VotedMovies) != 0
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+)
+
+func TestReconfigure(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(opts *Options)
+		wantErr   bool
+		wantSlots map[string][]int32 // shows of each screen on every day
+	}{
+		{
+			name: "add a screen and a show",
+			change: func(opts *Options) {
+				opts.Screens = append(opts.Screens, "C")
+				opts.Shows = append(opts.Shows, Show{ID: 3, PlayTime: "18:00"})
+			},
+			wantSlots: map[string][]int32{"A": {1, 2, 3}, "B": {1, 2, 3}, "C": {1, 2, 3}},
+		},
+		{
+			name:      "remove an empty screen",
+			change:    func(opts *Options) { opts.Screens = []string{"A"} },
+			wantSlots: map[string][]int32{"A": {1, 2}},
+		},
+		{
+			name:    "remove a screen with a movie",
+			change:  func(opts *Options) { opts.Screens = []string{"B"} },
+			wantErr: true,
+		},
+		{
+			name:    "remove a show with voted movies",
+			change:  func(opts *Options) { opts.Shows = opts.Shows[:1] },
+			wantErr: true,
+		},
+		{
+			name:    "maximum below the voted movies of a show",
+			change:  func(opts *Options) { opts.MaxMoviesVoted = 1 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid options",
+			change:  func(opts *Options) { opts.Screens = nil },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			scheduleAPI.opts.SnapshotInterval = time.Minute
+			scheduleAPI.opts.MovieRefreshInterval = time.Minute
+			scheduleAPI.opts.Store = NewMemoryStore()
+			daySchedule := scheduleAPI.weeklySchedule.DaysSchedule[1]
+			daySchedule.ScreensSchedule["A"].ShowsSchedule[1].Movie = &movie.Movie{Id: "m1"}
+			daySchedule.ScreensSchedule["A"].ShowsSchedule[2].VotedMovies = []*movie.Movie{{Id: "v1"}, {Id: "v2"}}
+
+			opts := scheduleAPI.opts
+			opts.Screens = append([]string{}, opts.Screens...)
+			opts.Shows = append([]Show{}, opts.Shows...)
+			tt.change(&opts)
+
+			err := scheduleAPI.Reconfigure(opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconfigure() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			wantSlots := tt.wantSlots
+			if tt.wantErr {
+				// Nothing is changed
+				wantSlots = map[string][]int32{"A": {1, 2}, "B": {1, 2}}
+				if len(scheduleAPI.opts.Screens) != 2 || len(scheduleAPI.opts.Shows) != 2 {
+					t.Errorf("options changed to %+v", scheduleAPI.opts)
+				}
+			}
+			for _, weekDay := range weekDays {
+				screens := scheduleAPI.weeklySchedule.DaysSchedule[weekDay].ScreensSchedule
+				if len(screens) != len(wantSlots) {
+					t.Fatalf("day %d has %d screens, want %d", weekDay, len(screens), len(wantSlots))
+				}
+				for screen, shows := range wantSlots {
+					screenSchedule, ok := screens[screen]
+					if !ok || len(screenSchedule.ShowsSchedule) != len(shows) {
+						t.Fatalf("day %d screen %s does not have shows %v", weekDay, screen, shows)
+					}
+					for _, show := range shows {
+						if _, ok := screenSchedule.ShowsSchedule[show]; !ok {
+							t.Errorf("day %d screen %s has no show %d", weekDay, screen, show)
+						}
+					}
+				}
+			}
+			if daySchedule.ScreensSchedule["A"].ShowsSchedule[1].GetMovie().GetId() != "m1" {
+				t.Error("scheduled movie was removed")
+			}
+		})
+	}
+}
@@ -1,48 +1,226 @@
 package service
 
 import (
+	"fmt"
 	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
-	"os"
 	"time"
 )
 
-// saves the current weekly schedule periodically in a file
+// saves the current weekly schedule periodically in the snapshot store
 func (scheduleAPI *scheduleAPIServer) saveScheduleWorker() {
 	for {
 		select {
 		case <-scheduleAPI.ctx.Done():
 			return
-		case <-time.After(time.Duration(5 * time.Minute)):
-			func() {
-				f, err := os.OpenFile("snapshot", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 066)
-				if err != nil {
-					logger.Log.Warn("error while saving file", zap.Error(err))
-					return
-				}
-
-				// Lock the mutex
-				scheduleAPI.muSchedule.Lock()
-
-				bs, err := proto.Marshal(&scheduleAPI.weeklySchedule)
-				if err != nil {
-					logger.Log.Error("error while marshaling file", zap.Error(err))
-					return
-				}
-
-				// Unlock the mutex
-				scheduleAPI.muSchedule.Unlock()
-
-				_, err = f.Write(bs)
-				if err != nil {
-					if err != nil {
-						logger.Log.Error("error while writing to file", zap.Error(err))
-						return
-					}
-				}
-
-			}()
+		case <-time.After(scheduleAPI.options().SnapshotInterval):
+			err := scheduleAPI.saveSnapshot()
+			if err != nil {
+				logger.Log.Error("error while saving snapshot", zap.Error(err))
+			}
 		}
 	}
 }
+
+// Close writes a final snapshot of the weekly schedule.
+// It should be called once in-flight requests have finished.
+func (scheduleAPI *scheduleAPIServer) Close() error {
+	err := scheduleAPI.saveSnapshot()
+	if err != nil {
+		return err
+	}
+	logger.Log.Info("final snapshot of weekly schedule saved", zap.Stringer("store", scheduleAPI.options().Store))
+	return nil
+}
+
+// writes the current weekly schedule to the snapshot store
+func (scheduleAPI *scheduleAPIServer) saveSnapshot() (err error) {
+	start := time.Now()
+	defer func() {
+		snapshotDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			snapshotFailures.Inc()
+		}
+	}()
+
+	scheduleAPI.muSnapshot.Lock()
+	defer scheduleAPI.muSnapshot.Unlock()
+
+	// Lock the mutex
+	scheduleAPI.muSchedule.Lock()
+	bs, err := proto.Marshal(&scheduleAPI.weeklySchedule)
+	store := scheduleAPI.opts.Store
+	cache, cacheStore := scheduleAPI.opts.Idempotency, scheduleAPI.opts.IdempotencyStore
+	// An empty draft snapshot means there is no draft
+	var draft []byte
+	draftStore := scheduleAPI.opts.DraftStore
+	if err == nil && scheduleAPI.draft != nil {
+		draft, err = proto.Marshal(&scheduleAPI.draft.weeklySchedule)
+	}
+	var history []byte
+	historyStore := scheduleAPI.opts.HistoryStore
+	if err == nil && historyStore != nil {
+		history, err = scheduleAPI.history.marshal()
+	}
+	// Votes are saved with their voters so that they can be refunded after a restart
+	var ledger []byte
+	ledgerStore := scheduleAPI.opts.LedgerStore
+	if err == nil && ledgerStore != nil {
+		ledger, err = scheduleAPI.ledger.marshal()
+	}
+	// Changes dropped from the history stay in it until they are archived
+	archiveStore, archiveSize := scheduleAPI.opts.HistoryArchive, scheduleAPI.opts.HistoryArchiveSize
+	dropped, droppedSince := scheduleAPI.history.dropped, scheduleAPI.history.droppedSince
+	// Unlock the mutex
+	scheduleAPI.muSchedule.Unlock()
+	if err != nil {
+		return errFromProtoMarshal(err, "weekly schedule")
+	}
+
+	err = store.Save(bs)
+	if err != nil {
+		return err
+	}
+
+	if draftStore != nil {
+		err = draftStore.Save(draft)
+		if err != nil {
+			return err
+		}
+	}
+
+	if historyStore != nil {
+		err = historyStore.Save(history)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ledgerStore != nil {
+		err = ledgerStore.Save(ledger)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(dropped) != 0 {
+		err = scheduleAPI.archiveDropped(archiveStore, archiveSize, dropped, droppedSince)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Results of idempotent requests are saved with the schedule they were applied to
+	if cache != nil {
+		bs, err = cache.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to marshal idempotency results: %v", err)
+		}
+		return cacheStore.Save(bs)
+	}
+
+	return nil
+}
+
+// restores the weekly schedule and the stores saved alongside it.
+// Each store is restored on its own, so a missing schedule snapshot does not discard the others
+func (scheduleAPI *scheduleAPIServer) loadSnapshot() error {
+	err := scheduleAPI.loadSchedule()
+	if err != nil {
+		return err
+	}
+
+	err = scheduleAPI.loadIdempotency()
+	if err != nil {
+		return err
+	}
+
+	err = scheduleAPI.loadLedger()
+	if err != nil {
+		return err
+	}
+
+	return scheduleAPI.loadHistory()
+}
+
+// restores the weekly schedule from the snapshot store if it has one
+func (scheduleAPI *scheduleAPIServer) loadSchedule() error {
+	bs, err := scheduleAPI.opts.Store.Load()
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		return nil
+	}
+
+	// Snapshots repaired by hand may be in JSON form
+	weeklySchedule, err := snapshot.Unmarshal(bs)
+	if err != nil {
+		return errFromProtoUnMarshal(err, "weekly schedule")
+	}
+
+	scheduleAPI.weeklySchedule = *weeklySchedule
+
+	logger.Log.Info("weekly schedule restored from snapshot", zap.Stringer("store", scheduleAPI.opts.Store))
+
+	return nil
+}
+
+// restores the results of idempotent requests saved alongside the weekly schedule
+func (scheduleAPI *scheduleAPIServer) loadIdempotency() error {
+	if scheduleAPI.opts.Idempotency == nil {
+		return nil
+	}
+
+	bs, err := scheduleAPI.opts.IdempotencyStore.Load()
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		return nil
+	}
+
+	err = scheduleAPI.opts.Idempotency.Unmarshal(bs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal idempotency results: %v", err)
+	}
+
+	logger.Log.Info(
+		"idempotency results restored",
+		zap.Stringer("store", scheduleAPI.opts.IdempotencyStore),
+		zap.Int("results", scheduleAPI.opts.Idempotency.Len()),
+	)
+
+	return nil
+}
+
+// restores the vote ledger saved alongside the weekly schedule.
+// Votes for movies no longer in their show are discarded once the schedule is indexed
+func (scheduleAPI *scheduleAPIServer) loadLedger() error {
+	if scheduleAPI.opts.LedgerStore == nil {
+		return nil
+	}
+
+	bs, err := scheduleAPI.opts.LedgerStore.Load()
+	if err != nil {
+		return err
+	}
+	if bs == nil {
+		return nil
+	}
+
+	err = scheduleAPI.ledger.unmarshal(bs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal vote ledger: %v", err)
+	}
+
+	logger.Log.Info(
+		"vote ledger restored",
+		zap.Stringer("store", scheduleAPI.opts.LedgerStore),
+		zap.Int("shows", len(scheduleAPI.ledger.votes)),
+	)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/scheduling/internal/idempotency"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestLoadSnapshot(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Format(time.RFC3339)
+	results := fmt.Sprintf(`{"u1 /m k1":{"fingerprint":"f","code":0,"expires":%q}}`, expires)
+
+	tests := []struct {
+		name         string
+		schedule     bool // whether the schedule has a snapshot
+		results      string
+		changes      int
+		wantResults  int
+		wantChanges  int
+		wantSchedule bool
+	}{
+		{
+			name: "all stores", schedule: true, results: results, changes: 2,
+			wantResults: 1, wantChanges: 2, wantSchedule: true,
+		},
+		{
+			name: "no schedule snapshot", results: results, changes: 2,
+			wantResults: 1, wantChanges: 2,
+		},
+		{
+			name: "only a schedule snapshot", schedule: true,
+			wantSchedule: true,
+		},
+		{
+			name: "no snapshots",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := newTestServer(t)
+			setShow(&saved.weeklySchedule, 1, "A", 1, showPlaying("m1"))
+			saved.history.since = time.Now()
+			for i := 0; i < tt.changes; i++ {
+				saved.history.add(&scheduler.ScheduleChange{Method: "UpdateMovieDaySchedule"})
+			}
+
+			scheduleAPI := newTestServer(t)
+			scheduleAPI.opts.Store = NewMemoryStore()
+			scheduleAPI.opts.Idempotency = idempotency.NewCache(time.Hour, 100)
+			scheduleAPI.opts.IdempotencyStore = NewMemoryStore()
+			scheduleAPI.opts.HistoryStore = NewMemoryStore()
+			if tt.schedule {
+				bs, err := proto.Marshal(&saved.weeklySchedule)
+				if err != nil {
+					t.Fatal(err)
+				}
+				scheduleAPI.opts.Store.Save(bs)
+			}
+			if tt.results != "" {
+				scheduleAPI.opts.IdempotencyStore.Save([]byte(tt.results))
+			}
+			if tt.changes != 0 {
+				bs, err := saved.history.marshal()
+				if err != nil {
+					t.Fatal(err)
+				}
+				scheduleAPI.opts.HistoryStore.Save(bs)
+			}
+
+			err := scheduleAPI.loadSnapshot()
+			if err != nil {
+				t.Fatalf("loadSnapshot() failed: %v", err)
+			}
+
+			if got := proto.Equal(&saved.weeklySchedule, &scheduleAPI.weeklySchedule); got != tt.wantSchedule {
+				t.Errorf("schedule restored = %t, want %t", got, tt.wantSchedule)
+			}
+			if got := scheduleAPI.opts.Idempotency.Len(); got != tt.wantResults {
+				t.Errorf("%d idempotency results restored, want %d", got, tt.wantResults)
+			}
+			if got := len(scheduleAPI.history.changes); got != tt.wantChanges {
+				t.Errorf("%d changes restored, want %d", got, tt.wantChanges)
+			}
+			if scheduleAPI.history.since.IsZero() {
+				t.Errorf("time the history is kept since is not set")
+			}
+		})
+	}
+}
@@ -2,95 +2,180 @@ package service
 
 import (
 	"context"
-	"github.com/gidyon/rupacinema/account/pkg/api"
+	"errors"
 	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
 	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
-// days, screens, timeOfDay, seats, details
-var (
-	weekDays         = []int32{1, 2, 3, 4, 5, 6, 7}
-	screensAvailable = []string{"Screen 1"}
-	maxMoviesVoted   = 4
-)
-
-var showsPerDay = []struct {
-	playtime string
-	showID   int32
-}{
-	{"11am", 1},
-	{"3pm", 2},
-	{"6pm", 3},
-	{"9pm", 4},
-}
+// days of the week
+var weekDays = []int32{1, 2, 3, 4, 5, 6, 7}
 
 type scheduleAPIServer struct {
 	ctx            context.Context
-	muSchedule     sync.Mutex // guards weeklySchedule
+	muSchedule     sync.Mutex // guards weeklySchedule, index, ledger, draft, history and opts
 	weeklySchedule scheduler.DaysSchedule
+	draft          *draftSchedule // nil when there is no draft
+	history        *changeHistory
+	index          *movieIndex
+	ledger         *voteLedger
+	opts           Options
+	muSnapshot     sync.Mutex // serializes writes to the snapshot file
 	// Remote Services
-	accountServiceClient account.AccountAPIClient
-	movieAPIClient       movie.MovieAPIClient
+	movieAPIClient movie.MovieAPIClient
 }
 
-// NewShowScheduler creates a new show scheduler service
+// NewShowScheduler creates a new show scheduler service.
+// Requests are expected to be authenticated by the gRPC server interceptors.
 func NewShowScheduler(
 	ctx context.Context,
-	accountServiceClient account.AccountAPIClient,
 	movieAPIClient movie.MovieAPIClient,
+	opts Options,
 ) (scheduler.ShowSchedulerServer, error) {
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	scheduleAPI := &scheduleAPIServer{
 		ctx:        ctx,
 		muSchedule: sync.Mutex{},
 		weeklySchedule: scheduler.DaysSchedule{
 			DaysSchedule: make(map[int32]*scheduler.ScreensSchedule),
 		},
+		index:   newMovieIndex(),
+		ledger:  newVoteLedger(),
+		history: newChangeHistory(opts.HistorySize),
+		opts:    opts,
 		// Remote Services
-		accountServiceClient: accountServiceClient,
-		movieAPIClient:       movieAPIClient,
+		movieAPIClient: movieAPIClient,
 	}
 
-	err := scheduleAPI.initializeSchedule()
+	scheduleAPI.history.archive = opts.archiving()
+
+	err = scheduleAPI.initializeSchedule()
 	if err != nil {
 		return nil, err
 	}
 
-	// saves the current schedule in a file after 5 minutes
+	// saves the current schedule periodically
 	go scheduleAPI.saveScheduleWorker()
 
 	// worker that updates movies resource
 	go scheduleAPI.updateMovies()
 
-	return &scheduleAPIServer{}, nil
+	return scheduleAPI, nil
 }
 
 // FTW!
 func (scheduleAPI *scheduleAPIServer) initializeSchedule() error {
+	// Restore the schedule saved before the last shutdown
+	err := scheduleAPI.loadSnapshot()
+	if err != nil {
+		return err
+	}
+
+	// Add slots that are missing from the restored schedule
+	scheduleAPI.syncSlots()
+
+	scheduleAPI.reindex()
+
+	// Restore the draft being edited before the last shutdown
+	return scheduleAPI.loadDraft()
+}
+
+// adds days, screens and shows that are missing from the weekly schedule and
+// updates play times of existing shows.
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) syncSlots() {
 	for _, weekDay := range weekDays {
-		scheduleAPI.weeklySchedule.DaysSchedule[weekDay] = &scheduler.ScreensSchedule{
-			ScreensSchedule: make(map[string]*scheduler.ShowsSchedule),
+		if _, ok := scheduleAPI.weeklySchedule.DaysSchedule[weekDay]; !ok {
+			scheduleAPI.weeklySchedule.DaysSchedule[weekDay] = &scheduler.ScreensSchedule{
+				ScreensSchedule: make(map[string]*scheduler.ShowsSchedule),
+			}
+		}
+		// Versions start at 1 so that an expected version of 0 means no check
+		if scheduleAPI.weeklySchedule.DaysSchedule[weekDay].Version == 0 {
+			scheduleAPI.weeklySchedule.DaysSchedule[weekDay].Version = 1
 		}
 		screensSchedule := scheduleAPI.weeklySchedule.DaysSchedule[weekDay].ScreensSchedule
-		for _, screen := range screensAvailable {
-			screensSchedule[screen] = &scheduler.ShowsSchedule{
-				ShowsSchedule: make(map[int32]*scheduler.ShowSchedule),
+		for _, screen := range scheduleAPI.opts.Screens {
+			if _, ok := screensSchedule[screen]; !ok {
+				screensSchedule[screen] = &scheduler.ShowsSchedule{
+					ShowsSchedule: make(map[int32]*scheduler.ShowSchedule),
+				}
 			}
 			showsSchedule := scheduleAPI.weeklySchedule.DaysSchedule[weekDay].ScreensSchedule[screen].ShowsSchedule
-			for _, show := range showsPerDay {
-				showsSchedule[show.showID] = &scheduler.ShowSchedule{
-					PlayTime:    show.playtime,
-					Movie:       &movie.Movie{},
-					VotedMovies: make([]*movie.Movie, 0),
+			for _, show := range scheduleAPI.opts.Shows {
+				showSchedule, ok := showsSchedule[show.ID]
+				if !ok {
+					showsSchedule[show.ID] = &scheduler.ShowSchedule{
+						PlayTime:    show.PlayTime,
+						Movie:       &movie.Movie{},
+						VotedMovies: make([]*movie.Movie, 0),
+						Version:     1,
+					}
+					continue
+				}
+				if showSchedule.Version == 0 {
+					showSchedule.Version = 1
+				}
+				if showSchedule.PlayTime != show.PlayTime {
+					showSchedule.PlayTime = show.PlayTime
+					showSchedule.Version++
+					scheduleAPI.weeklySchedule.DaysSchedule[weekDay].Version++
+				}
+				if showSchedule.Movie == nil {
+					showSchedule.Movie = &movie.Movie{}
 				}
 			}
 		}
 	}
+}
+
+// lockSchedule locks the muSchedule mutex and records the time spent waiting on the request span
+func (scheduleAPI *scheduleAPIServer) lockSchedule(ctx context.Context) {
+	start := time.Now()
+	scheduleAPI.muSchedule.Lock()
+	trace.SpanFromContext(ctx).AddEvent(
+		"muSchedule acquired",
+		trace.WithAttributes(attribute.Int64("wait_us", time.Since(start).Microseconds())),
+	)
+}
+
+// Ready reports whether the weekly schedule has been loaded
+func (scheduleAPI *scheduleAPIServer) Ready() error {
+	if cancelled(scheduleAPI.ctx) {
+		return errors.New("scheduling service is stopping")
+	}
+
+	scheduleAPI.muSchedule.Lock()
+	defer scheduleAPI.muSchedule.Unlock()
+
+	if len(scheduleAPI.weeklySchedule.DaysSchedule) != len(weekDays) {
+		return errors.New("weekly schedule is not loaded")
+	}
+
 	return nil
 }
 
+// WeeklySchedule returns a copy of the weekly schedule
+func (scheduleAPI *scheduleAPIServer) WeeklySchedule() *scheduler.DaysSchedule {
+	scheduleAPI.muSchedule.Lock()
+	defer scheduleAPI.muSchedule.Unlock()
+
+	return proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+}
+
 // Assumes that the mutex gurading weeklySchedule is locked
 func (scheduleAPI *scheduleAPIServer) getDaySchedule(
 	weekDay int32,
@@ -137,7 +222,8 @@ func (scheduleAPI *scheduleAPIServer) existInSchedule(
 		return false, err
 	}
 
-	if showSchedule.Movie == nil {
+	// Shows without a movie hold an empty placeholder
+	if showSchedule.Movie == nil || showSchedule.Movie.Id == "" {
 		return false, nil
 	}
 
@@ -158,35 +244,36 @@ func (scheduleAPI *scheduleAPIServer) updateMovieInfo(
 	}
 	// Find the movie
 	for _, movieSchedule := range append(showShedule.VotedMovies, showShedule.Movie) {
-		// If the Id match, update the movie info
-		if movieSchedule.Id == movieItem.Id {
-			*movieSchedule = *movieItem
+		if movieSchedule.Id != movieItem.Id {
+			continue
+		}
+		// If the Id match, update the movie info. Votes are counted by the scheduler, not the movie service
+		refreshed := proto.Clone(movieItem).(*movie.Movie)
+		refreshed.CurrentVotes = movieSchedule.CurrentVotes
+		if !proto.Equal(movieSchedule, refreshed) {
+			*movieSchedule = *refreshed
 		}
 	}
+	// The programme is unchanged, so versions are kept and edits expecting them still apply
 }
 
 // The Pseudocode for voting up a movie:
 // 1. Validate fields from request
-// 2. Lock the mutex, and defer Unlock of the mutex
-// 3. Get show for the day and screen based on the input fields
-// 4. If the movie in show matches the Id of movie in request, increment current votes it and return
-// 5. Otherwise range over the voted movies
-// 6. When a match pf movie id is found, increment current votes
-// 7. Swap the movies if necessary
-// 8. Return the updated movie
+// 2. Take the voter from the caller claims, return err if the request is for another user
+// 3. Lock the mutex, and defer Unlock of the mutex
+// 4. Get show for the day and screen based on the input fields
+// 5. If the movie in show matches the Id of movie in request, increment current votes it and return
+// 6. Otherwise range over the voted movies
+// 7. When a match pf movie id is found, increment current votes, otherwise return an error
+// 8. Cast the votes refunded to the user in the show along with the vote, and record them in the vote ledger
+// 9. Swap the movies if necessary
+// 10. Increment the votes version of the show, and its version if the showing movie changed
+// 11. Record the change in the change history if the showing movie changed
+// 12. Return the updated movie
 func (scheduleAPI *scheduleAPIServer) VoteUpMovie(
 	ctx context.Context, voteReq *scheduler.VoteUpMovieRequest,
 ) (*movie.Movie, error) {
-	// Authenticate the request
-	_, err := scheduleAPI.accountServiceClient.AuthenticateRequest(
-		ctx, &empty.Empty{},
-	)
-	if err != nil {
-		return nil, err
-	}
-
 	movieID := voteReq.GetMovieId()
-	userID := voteReq.GetUserId()
 	screen := voteReq.GetScreen()
 	weekDay := voteReq.GetWeekDay()
 	showNumber := voteReq.GetShowNumber()
@@ -197,8 +284,6 @@ func (scheduleAPI *scheduleAPIServer) VoteUpMovie(
 		switch {
 		case strings.Trim(screen, " ") == "":
 			err = errMissingCredential("Screen")
-		case strings.Trim(userID, " ") == "":
-			err = errMissingCredential("User Id")
 		case strings.Trim(movieID, " ") == "":
 			err = errMissingCredential("Movie Id")
 		case weekDay <= 0 || weekDay > 7:
@@ -212,8 +297,21 @@ func (scheduleAPI *scheduleAPIServer) VoteUpMovie(
 		return nil, err
 	}
 
+	// Votes are cast by the authenticated caller, never on behalf of another user
+	claims, _ := auth.FromContext(ctx)
+	userID := ""
+	if claims != nil {
+		userID = claims.UserID
+	}
+	switch {
+	case strings.Trim(userID, " ") == "":
+		return nil, errMissingCredential("User Id")
+	case voteReq.GetUserId() != "" && voteReq.GetUserId() != userID:
+		return nil, errVoteForOtherUser()
+	}
+
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
 	// Get the show
@@ -222,65 +320,81 @@ func (scheduleAPI *scheduleAPIServer) VoteUpMovie(
 		return nil, err
 	}
 
+	showLabel := strconv.Itoa(int(showNumber))
+
+	slot := snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: showNumber}
+
 	// Increment the votes of the currently selected show
 	if showSchedule.Movie.Id == movieID {
-		showSchedule.Movie.CurrentVotes++
-		return showSchedule.Movie, nil
+		votes := 1 + scheduleAPI.ledger.redeem(slot, userID)
+		showSchedule.Movie.CurrentVotes += votes
+		votesCounter.WithLabelValues(screen, showLabel).Inc()
+		scheduleAPI.ledger.record(slot, movieID, userID, votes)
+		scheduleAPI.votesChanged(weekDay, showNumber, screen)
+		// A copy is returned since the schedule changes once the mutex is unlocked
+		return proto.Clone(showSchedule.Movie).(*movie.Movie), nil
 	}
 
+	// A swap changes the programme, so the show before the vote is kept to record it
+	before := proto.Clone(showSchedule).(*scheduler.ShowSchedule)
+
 	// Increment the vote in voted movies section and swap the result if necessary
+	voted := false
 	for _, movieItem := range showSchedule.VotedMovies {
 		if movieItem.Id == movieID {
-			movieItem.CurrentVotes++
+			votes := 1 + scheduleAPI.ledger.redeem(slot, userID)
+			movieItem.CurrentVotes += votes
+			votesCounter.WithLabelValues(screen, showLabel).Inc()
+			scheduleAPI.ledger.record(slot, movieID, userID, votes)
+			voted = true
 			break
 		}
 	}
 
+	// Return err if the movie is not in the show
+	if !voted {
+		return nil, errNoMovieScheduleExist(movieID)
+	}
+
 	// Change the movie in show depending on the votes between display movie and the voted movies
+	showingMovieID := showSchedule.Movie.Id
 	swapMovies(showSchedule.Movie, showSchedule.VotedMovies)
+	scheduleAPI.votesChanged(weekDay, showNumber, screen)
+	if showSchedule.Movie.Id != showingMovieID {
+		swapsCounter.WithLabelValues(screen, showLabel).Inc()
+		scheduleAPI.showChanged(weekDay, showNumber, screen)
+		scheduleAPI.recordShowChange(ctx, "VoteUpMovie", slot, before)
+	}
 
-	// Vote up the movie
-	return showSchedule.Movie, nil
+	// Vote up the movie. A copy is returned since the schedule changes once the mutex is unlocked
+	return proto.Clone(showSchedule.Movie).(*movie.Movie), nil
 }
 
 // The Pseudocode:
 // 1. Validate input fields from the request
 // 2. Get the movie resource remotely
 // 3. Lock the mutex and defer unlock
-// 4. Check that the movie does not exist in schedule, return an error if so
-// 5. Only then add the movie in schedule
-// 6. Return success
+// 4. Check that the show is at the expected version, if the request has one
+// 5. Check that the movie does not exist in schedule, return an error if so
+// 6. Only then add the movie in schedule
+// 7. Return success
 func (scheduleAPI *scheduleAPIServer) CreateMovieDaySchedule(
 	ctx context.Context, makeReq *scheduler.CreateMovieDayScheduleRequest,
 ) (*empty.Empty, error) {
-	// Authenticate the request
-	_, err := scheduleAPI.accountServiceClient.AuthenticateRequest(
-		ctx, &empty.Empty{},
-	)
+	// Validate the input
+	err := validateCreateMovieDaySchedule(makeReq)
 	if err != nil {
 		return nil, err
 	}
 
-	weekDay := makeReq.GetWeekDay()
-	screen := makeReq.GetScreen()
-	showNumber := makeReq.GetShow()
-	movieID := makeReq.GetMovieId()
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "CreateMovieDaySchedule")
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate the input
-	err := func() error {
-		var err error
-		switch {
-		case weekDay <= 0 || weekDay > 7:
-			err = errIncorrectVal("Week day")
-		case strings.Trim(screen, " ") == "":
-			err = errMissingCredential("Screen")
-		case strings.Trim(movieID, " ") == "":
-			err = errMissingCredential("Movie Id")
-		case showNumber <= 0:
-			err = errIncorrectVal("Show number")
-		}
-		return err
-	}()
+	// The If-Match header is the expected version when the request has none
+	makeReq.ExpectedVersion, err = ifMatchVersion(ctx, makeReq.GetExpectedVersion())
 	if err != nil {
 		return nil, err
 	}
@@ -289,7 +403,7 @@ func (scheduleAPI *scheduleAPIServer) CreateMovieDaySchedule(
 	movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
 		ctx,
 		&movie.GetMovieRequest{
-			MovieId: movieID,
+			MovieId: makeReq.GetMovieId(),
 		},
 	)
 	if err != nil {
@@ -297,66 +411,99 @@ func (scheduleAPI *scheduleAPIServer) CreateMovieDaySchedule(
 	}
 
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if makeReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "CreateMovieDaySchedule")()
+	}
+
+	err = scheduleAPI.createMovieDaySchedule(makeReq, movieItem)
+	if err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func validateCreateMovieDaySchedule(makeReq *scheduler.CreateMovieDayScheduleRequest) error {
+	var err error
+	switch {
+	case makeReq.GetWeekDay() <= 0 || makeReq.GetWeekDay() > 7:
+		err = errIncorrectVal("Week day")
+	case strings.Trim(makeReq.GetScreen(), " ") == "":
+		err = errMissingCredential("Screen")
+	case strings.Trim(makeReq.GetMovieId(), " ") == "":
+		err = errMissingCredential("Movie Id")
+	case makeReq.GetShow() <= 0:
+		err = errIncorrectVal("Show number")
+	}
+	return err
+}
+
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) createMovieDaySchedule(
+	makeReq *scheduler.CreateMovieDayScheduleRequest, movieItem *movie.Movie,
+) error {
+	weekDay := makeReq.GetWeekDay()
+	screen := makeReq.GetScreen()
+	showNumber := makeReq.GetShow()
+
+	// Return err if the show changed since the version the request expects
+	err := scheduleAPI.checkShowVersion(weekDay, showNumber, screen, makeReq.GetExpectedVersion())
+	if err != nil {
+		return err
+	}
+
 	// Ensure the movie exists does not exist in schedule
 	ok, err := scheduleAPI.existInSchedule(weekDay, showNumber, screen, movieItem.Id)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Return err if it exist in schedule
 	if ok {
-		return nil, errMovieScheduleExist(movieItem.Id)
+		return errMovieScheduleExist(movieItem.Id)
 	}
 
 	// Add the movie in schedule
 	showSchedule, _ := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
 	showSchedule.Movie = movieItem
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
 
-	return &empty.Empty{}, nil
+	return nil
 }
 
 // The Pseudocode:
 // 1. Validate the input fields from the request
 // 2. Get the remote movie
-// 3. Check that there is room to add the voted movie, if not we fail early
-// 4. Lock the mutex and defer unlock
-// 5. Check that the movie hasn't already been voted
-// 6. Only after step 5, do we add the movie in voted movies section
-// 7. Return successful
+// 3. Lock the mutex and defer unlock
+// 4. Check that the show is at the expected version, if the request has one
+// 5. Check that the movie isn't already showing or voted for
+// 6. Check that there is room to add the voted movie
+// 7. Only after step 5, do we add the movie in voted movies section
+// 8. Return successful
 func (scheduleAPI *scheduleAPIServer) AddVotedMovie(
 	ctx context.Context, addReq *scheduler.AddVotedMovieRequest,
 ) (*empty.Empty, error) {
-	// Authenticate the request
-	_, err := scheduleAPI.accountServiceClient.AuthenticateRequest(
-		ctx, &empty.Empty{},
-	)
+	// Validate the input fields from request
+	err := validateAddVotedMovie(addReq)
 	if err != nil {
 		return nil, err
 	}
 
-	weekDay := addReq.GetWeekDay()
-	screen := addReq.GetScreen()
-	showNumber := addReq.GetShow()
-	movieID := addReq.GetMovieId()
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "AddVotedMovie")
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate the input fields from request
-	err := func() error {
-		var err error
-		switch {
-		case weekDay <= 0 || weekDay > 7:
-			err = errIncorrectVal("Week day")
-		case strings.Trim(screen, " ") == "":
-			err = errMissingCredential("Screen")
-		case showNumber <= 0:
-			err = errIncorrectVal("Show number")
-		case strings.Trim(movieID, " ") == "":
-			err = errMissingCredential("Movie ID")
-		}
-		return err
-	}()
+	// The If-Match header is the expected version when the request has none
+	addReq.ExpectedVersion, err = ifMatchVersion(ctx, addReq.GetExpectedVersion())
 	if err != nil {
 		return nil, err
 	}
@@ -365,7 +512,7 @@ func (scheduleAPI *scheduleAPIServer) AddVotedMovie(
 	movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
 		ctx,
 		&movie.GetMovieRequest{
-			MovieId: movieID,
+			MovieId: addReq.GetMovieId(),
 		},
 	)
 	if err != nil {
@@ -373,93 +520,178 @@ func (scheduleAPI *scheduleAPIServer) AddVotedMovie(
 	}
 
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
-	// Ensure the movie exists does not exist in schedule
-	ok, err := scheduleAPI.existInSchedule(weekDay, showNumber, screen, movieItem.Id)
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if addReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "AddVotedMovie")()
+	}
+
+	err = scheduleAPI.addVotedMovie(addReq, movieItem)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return err if it exist in schedule
-	if ok {
-		return nil, errMovieScheduleExist(movieItem.Id)
+	return &empty.Empty{}, nil
+}
+
+func validateAddVotedMovie(addReq *scheduler.AddVotedMovieRequest) error {
+	var err error
+	switch {
+	case addReq.GetWeekDay() <= 0 || addReq.GetWeekDay() > 7:
+		err = errIncorrectVal("Week day")
+	case strings.Trim(addReq.GetScreen(), " ") == "":
+		err = errMissingCredential("Screen")
+	case addReq.GetShow() <= 0:
+		err = errIncorrectVal("Show number")
+	case strings.Trim(addReq.GetMovieId(), " ") == "":
+		err = errMissingCredential("Movie ID")
+	}
+	return err
+}
+
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) addVotedMovie(
+	addReq *scheduler.AddVotedMovieRequest, movieItem *movie.Movie,
+) error {
+	weekDay := addReq.GetWeekDay()
+	screen := addReq.GetScreen()
+	showNumber := addReq.GetShow()
+
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	if err != nil {
+		return err
+	}
+
+	// Return err if the show changed since the version the request expects
+	err = checkVersion(showSchedule, addReq.GetExpectedVersion())
+	if err != nil {
+		return err
+	}
+
+	// Return err if it is already showing or voted for
+	if inShow(showSchedule, movieItem.Id) {
+		return errMovieScheduleExist(movieItem.Id)
 	}
 
 	// Check there is room to add to voted movie
-	showSchedule, _ := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
-	if len(showSchedule.VotedMovies) >= maxMoviesVoted {
-		return nil, errNoVotedMovieRoom()
+	if len(showSchedule.VotedMovies) >= scheduleAPI.opts.MaxMoviesVoted {
+		return errNoVotedMovieRoom()
 	}
 
 	// Add movie to voted movies section
 	showSchedule.VotedMovies = append(showSchedule.VotedMovies, movieItem)
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
 
-	return &empty.Empty{}, nil
+	return nil
 }
 
 // The Pseudocode:
 // 1. Validate the input fields from the request
 // 2. Lock mutex and defer Unlock defer
-// 3. Endure that the movie exists in schedule, if it isn't return
-// 4. Set the CurrentVotes to be -ve and swap the movie with voted movies
+// 3. Check that the show is at the expected version, if the request has one
+// 4. Endure that the movie exists in schedule, if it isn't return
+// 5. If there are no voted movies, leave the show empty and return
+// 6. Set the CurrentVotes to be -ve and swap the movie with voted movies
 // NB: This will ensure the swapping is successful
-// 5. Delete the movie that has been swapped to voted movies
-// 6. Return success
+// 7. Delete the movie that has been swapped to voted movies
+// 8. Return success
 func (scheduleAPI *scheduleAPIServer) DeleteMovieDaySchedule(
 	ctx context.Context, delReq *scheduler.DeleteMovieDayScheduleRequest,
 ) (*empty.Empty, error) {
-	// Authenticate the request
-	_, err := scheduleAPI.accountServiceClient.AuthenticateRequest(
-		ctx, &empty.Empty{},
-	)
+	// Validate the input fields from request
+	err := validateDeleteMovieDaySchedule(delReq)
 	if err != nil {
 		return nil, err
 	}
 
-	weekDay := delReq.GetWeekDay()
-	screen := delReq.GetScreen()
-	showNumber := delReq.GetShow()
-	movieID := delReq.GetMovieId()
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "DeleteMovieDaySchedule")
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate the input fields from request
-	err := func() error {
-		var err error
-		switch {
-		case weekDay <= 0 || weekDay > 7:
-			err = errIncorrectVal("Week day")
-		case strings.Trim(screen, " ") == "":
-			err = errMissingCredential("Screen")
-		case showNumber <= 0:
-			err = errIncorrectVal("Show number")
-		case strings.Trim(movieID, " ") == "":
-			err = errMissingCredential("Movie ID")
-		}
-		return err
-	}()
+	// The If-Match header is the expected version when the request has none
+	delReq.ExpectedVersion, err = ifMatchVersion(ctx, delReq.GetExpectedVersion())
 	if err != nil {
 		return nil, err
 	}
 
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if delReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "DeleteMovieDaySchedule")()
+	}
+
+	err = scheduleAPI.deleteMovieDaySchedule(delReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func validateDeleteMovieDaySchedule(delReq *scheduler.DeleteMovieDayScheduleRequest) error {
+	var err error
+	switch {
+	case delReq.GetWeekDay() <= 0 || delReq.GetWeekDay() > 7:
+		err = errIncorrectVal("Week day")
+	case strings.Trim(delReq.GetScreen(), " ") == "":
+		err = errMissingCredential("Screen")
+	case delReq.GetShow() <= 0:
+		err = errIncorrectVal("Show number")
+	case strings.Trim(delReq.GetMovieId(), " ") == "":
+		err = errMissingCredential("Movie ID")
+	}
+	return err
+}
+
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) deleteMovieDaySchedule(
+	delReq *scheduler.DeleteMovieDayScheduleRequest,
+) error {
+	weekDay := delReq.GetWeekDay()
+	screen := delReq.GetScreen()
+	showNumber := delReq.GetShow()
+	movieID := delReq.GetMovieId()
+
+	// Return err if the show changed since the version the request expects
+	err := scheduleAPI.checkShowVersion(weekDay, showNumber, screen, delReq.GetExpectedVersion())
+	if err != nil {
+		return err
+	}
+
 	// Ensure the movie exists in schedule
 	ok, err := scheduleAPI.existInSchedule(weekDay, showNumber, screen, movieID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Return err if it doesn't exist in schedule
 	if !ok {
-		return nil, errNoMovieScheduleExist(movieID)
+		return errNoMovieScheduleExist(movieID)
 	}
 
 	// Ok will be true
 	showSchedule, _ := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
 
+	// Leave the show empty when there is no voted movie to replace the movie
+	if len(showSchedule.VotedMovies) == 0 {
+		showSchedule.Movie = &movie.Movie{}
+		scheduleAPI.showChanged(weekDay, showNumber, screen)
+		return nil
+	}
+
 	// So that the swapping succeeds
 	showSchedule.Movie.CurrentVotes = -1
 
@@ -470,8 +702,9 @@ func (scheduleAPI *scheduleAPIServer) DeleteMovieDaySchedule(
 	showSchedule.VotedMovies = append(
 		showSchedule.VotedMovies[:index], showSchedule.VotedMovies[index+1:]...,
 	)
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
 
-	return &empty.Empty{}, nil
+	return nil
 }
 
 func (scheduleAPI *scheduleAPIServer) GetDaySchedule(
@@ -483,16 +716,28 @@ func (scheduleAPI *scheduleAPIServer) GetDaySchedule(
 		return nil, errIncorrectVal("Week Day")
 	}
 
+	// Only programmers may read the draft
+	err := draftAllowed(ctx, getReq.GetDraft())
+	if err != nil {
+		return nil, err
+	}
+
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
+	// Read the draft instead of the published schedule if requested
+	if getReq.GetDraft() {
+		defer scheduleAPI.readDraft()()
+	}
+
 	daySchedule, err := scheduleAPI.getDaySchedule(weekDay)
 	if err != nil {
 		return nil, err
 	}
 
-	return daySchedule, nil
+	// A copy is returned since the schedule changes once the mutex is unlocked
+	return proto.Clone(daySchedule).(*scheduler.ScreensSchedule), nil
 }
 
 func (scheduleAPI *scheduleAPIServer) GetShowSchedule(
@@ -518,16 +763,28 @@ func (scheduleAPI *scheduleAPIServer) GetShowSchedule(
 		return nil, err
 	}
 
+	// Only programmers may read the draft
+	err = draftAllowed(ctx, getReq.GetDraft())
+	if err != nil {
+		return nil, err
+	}
+
 	// lock the muSchedule mutex and defer unlock
-	scheduleAPI.muSchedule.Lock()
+	scheduleAPI.lockSchedule(ctx)
 	defer scheduleAPI.muSchedule.Unlock()
 
+	// Read the draft instead of the published schedule if requested
+	if getReq.GetDraft() {
+		defer scheduleAPI.readDraft()()
+	}
+
 	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
 	if err != nil {
 		return nil, err
 	}
 
-	return showSchedule, nil
+	// A copy is returned since the schedule changes once the mutex is unlocked
+	return proto.Clone(showSchedule).(*scheduler.ShowSchedule), nil
 }
 
 func higherVotesIndex(movies []*movie.Movie) int {
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVoteUpMovie(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		req              *scheduler.VoteUpMovieRequest
+		wantCode         codes.Code
+		wantShowing      string
+		wantVotes        map[string]int32 // votes of each movie in the show
+		wantLedger       map[string]int32 // votes of the caller in the ledger for each movie
+		wantVersion      int64
+		wantVotesVersion int64
+	}{
+		{
+			name:             "showing movie",
+			ctx:              userContext("u1"),
+			req:              &scheduler.VoteUpMovieRequest{MovieId: "m1"},
+			wantShowing:      "m1",
+			wantVotes:        map[string]int32{"m1": 2, "v1": 1, "v2": 0},
+			wantLedger:       map[string]int32{"m1": 1},
+			wantVersion:      1,
+			wantVotesVersion: 1,
+		},
+		{
+			name:             "voted movie",
+			ctx:              userContext("u1"),
+			req:              &scheduler.VoteUpMovieRequest{MovieId: "v2"},
+			wantShowing:      "m1",
+			wantVotes:        map[string]int32{"m1": 1, "v1": 1, "v2": 1},
+			wantLedger:       map[string]int32{"v2": 1},
+			wantVersion:      1,
+			wantVotesVersion: 1,
+		},
+		{
+			name:             "voted movie overtaking the showing movie",
+			ctx:              userContext("u1"),
+			req:              &scheduler.VoteUpMovieRequest{MovieId: "v1", UserId: "u1"},
+			wantShowing:      "v1",
+			wantVotes:        map[string]int32{"m1": 1, "v1": 2, "v2": 0},
+			wantLedger:       map[string]int32{"v1": 1},
+			wantVersion:      2,
+			wantVotesVersion: 1,
+		},
+		{
+			name:     "movie not in the show",
+			ctx:      userContext("u1"),
+			req:      &scheduler.VoteUpMovieRequest{MovieId: "v3"},
+			wantCode: codes.Unknown,
+		},
+		{
+			name:     "on behalf of another user",
+			ctx:      userContext("u1"),
+			req:      &scheduler.VoteUpMovieRequest{MovieId: "m1", UserId: "u2"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "anonymous caller",
+			ctx:      context.Background(),
+			req:      &scheduler.VoteUpMovieRequest{MovieId: "m1"},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+				PlayTime:    "10:00",
+				Movie:       &movie.Movie{Id: "m1", CurrentVotes: 1},
+				VotedMovies: []*movie.Movie{{Id: "v1", CurrentVotes: 1}, {Id: "v2"}},
+				Version:     1,
+			})
+			scheduleAPI.reindex()
+
+			tt.req.WeekDay, tt.req.ShowNumber, tt.req.Screen = slot.WeekDay, slot.Show, slot.Screen
+			got, err := scheduleAPI.VoteUpMovie(tt.ctx, tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("VoteUpMovie() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if err != nil {
+				if showSchedule.GetVersion() != 1 || showSchedule.GetVotesVersion() != 0 {
+					t.Errorf("versions changed by a failed vote")
+				}
+				if len(scheduleAPI.ledger.votes) != 0 {
+					t.Errorf("ledger = %v, want no votes", scheduleAPI.ledger.votes)
+				}
+				return
+			}
+
+			if got.GetId() != tt.wantShowing || showSchedule.GetMovie().GetId() != tt.wantShowing {
+				t.Errorf("showing movie = %q, want %q", showSchedule.GetMovie().GetId(), tt.wantShowing)
+			}
+			for _, movieItem := range append(showSchedule.GetVotedMovies(), showSchedule.GetMovie()) {
+				if movieItem.GetCurrentVotes() != tt.wantVotes[movieItem.GetId()] {
+					t.Errorf(
+						"votes of %q = %d, want %d",
+						movieItem.GetId(), movieItem.GetCurrentVotes(), tt.wantVotes[movieItem.GetId()],
+					)
+				}
+			}
+			for movieID, want := range tt.wantLedger {
+				if got := scheduleAPI.ledger.votes[slot][movieID]["u1"]; got != want {
+					t.Errorf("ledger votes of u1 for %q = %d, want %d", movieID, got, want)
+				}
+				if got := scheduleAPI.ledger.count(slot, movieID); got != want {
+					t.Errorf("ledger votes for %q = %d, want %d", movieID, got, want)
+				}
+			}
+			if showSchedule.GetVersion() != tt.wantVersion {
+				t.Errorf("version = %d, want %d", showSchedule.GetVersion(), tt.wantVersion)
+			}
+			if showSchedule.GetVotesVersion() != tt.wantVotesVersion {
+				t.Errorf("votes version = %d, want %d", showSchedule.GetVotesVersion(), tt.wantVotesVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeMovieAPI serves a movie for every id, except ids starting with "missing"
+type fakeMovieAPI struct {
+	movie.MovieAPIClient
+}
+
+func (fakeMovieAPI) GetMovie(
+	ctx context.Context, getReq *movie.GetMovieRequest, _ ...grpc.CallOption,
+) (*movie.Movie, error) {
+	if strings.HasPrefix(getReq.GetMovieId(), "missing") {
+		return nil, status.Errorf(codes.NotFound, "movie %q not found", getReq.GetMovieId())
+	}
+	return &movie.Movie{Id: getReq.GetMovieId(), Title: "Movie " + getReq.GetMovieId()}, nil
+}
+
+// returns a scheduler with screens A and B, each playing shows 1 and 2 every day, and no movies
+func newTestServer(t *testing.T) *scheduleAPIServer {
+	t.Helper()
+	logger.Log = zap.NewNop()
+
+	opts := Options{
+		Screens:        []string{"A", "B"},
+		Shows:          []Show{{ID: 1, PlayTime: "10:00"}, {ID: 2, PlayTime: "14:00"}},
+		MaxMoviesVoted: 3,
+		HistorySize:    100,
+	}
+	scheduleAPI := &scheduleAPIServer{
+		ctx: context.Background(),
+		weeklySchedule: scheduler.DaysSchedule{
+			DaysSchedule: make(map[int32]*scheduler.ScreensSchedule),
+		},
+		index:          newMovieIndex(),
+		ledger:         newVoteLedger(),
+		history:        newChangeHistory(opts.HistorySize),
+		opts:           opts,
+		movieAPIClient: fakeMovieAPI{},
+	}
+	scheduleAPI.syncSlots()
+	scheduleAPI.reindex()
+	return scheduleAPI
+}
+
+// returns a context authenticated as a user with the given roles
+func userContext(userID string, roles ...string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Claims{UserID: userID, Roles: roles})
+}
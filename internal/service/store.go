@@ -0,0 +1,80 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// SnapshotStore persists snapshots of the weekly schedule
+type SnapshotStore interface {
+	// Save replaces the stored snapshot
+	Save(snapshot []byte) error
+	// Load returns the stored snapshot, or nil if there is none
+	Load() ([]byte, error)
+	// String describes where snapshots are kept
+	String() string
+}
+
+type fileStore struct {
+	path string
+}
+
+// NewFileStore creates a snapshot store that keeps the snapshot in a file
+func NewFileStore(path string) SnapshotStore {
+	return &fileStore{path: path}
+}
+
+func (store *fileStore) Save(snapshot []byte) error {
+	// Write to a temporary file first so that a crash never leaves a partial snapshot
+	tmpFile := store.path + ".tmp"
+	err := ioutil.WriteFile(tmpFile, snapshot, 0666)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, store.path)
+}
+
+func (store *fileStore) Load() ([]byte, error) {
+	bs, err := ioutil.ReadFile(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bs, nil
+}
+
+func (store *fileStore) String() string {
+	return "file " + store.path
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	snapshot []byte
+}
+
+// NewMemoryStore creates a snapshot store that keeps the snapshot in memory.
+// The schedule is lost when the service stops.
+func NewMemoryStore() SnapshotStore {
+	return &memoryStore{}
+}
+
+func (store *memoryStore) Save(snapshot []byte) error {
+	store.mu.Lock()
+	store.snapshot = append([]byte(nil), snapshot...)
+	store.mu.Unlock()
+	return nil
+}
+
+func (store *memoryStore) Load() ([]byte, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.snapshot, nil
+}
+
+func (store *memoryStore) String() string {
+	return "memory"
+}
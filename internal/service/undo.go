@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// The Pseudocode:
+// 1. Validate the change id
+// 2. Check that the caller is a programmer or an admin
+// 3. Lock the mutex and defer unlock
+// 4. Get the change from the change history, return err if it is not kept
+// 5. Return err if the change was made by voting or changed the screens and shows
+// 6. Return err if any show it changed has a different movie, voted movies or play time since
+// 7. Put back every show as it was before the change, keeping the votes cast since
+// 8. Increase the versions of the shows and their days
+// 9. Record the changes in the change history
+// 10. Return the differences made
+func (scheduleAPI *scheduleAPIServer) UndoChange(
+	ctx context.Context, undoReq *scheduler.UndoChangeRequest,
+) (*scheduler.UndoChangeResponse, error) {
+	// Validate the input
+	if undoReq.GetChangeId() <= 0 {
+		return nil, errIncorrectVal("Change ID")
+	}
+
+	// Only programmers and admins may undo changes
+	claims, _ := auth.FromContext(ctx)
+	if !claims.HasRole(auth.RoleProgrammer) && !claims.HasRole(auth.RoleAdmin) {
+		return nil, errPermissionDenied("UndoChange")
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	var change *scheduler.ScheduleChange
+	for _, recorded := range scheduleAPI.history.changes {
+		if recorded.GetId() == undoReq.GetChangeId() {
+			change = recorded
+			break
+		}
+	}
+	if change == nil {
+		return nil, errChangeNotFound(undoReq.GetChangeId())
+	}
+
+	// Only votes that swapped the showing movie are recorded
+	if change.GetMethod() == "VoteUpMovie" {
+		return nil, errChangeNotUndoable(change.GetId(), "votes cannot be undone")
+	}
+
+	for _, showChange := range change.GetShows() {
+		if showChange.GetBefore() == nil || showChange.GetAfter() == nil {
+			return nil, errChangeNotUndoable(change.GetId(), "changes to the screens and shows cannot be undone")
+		}
+		slot := changedSlot(showChange)
+		if !sameProgramme(snapshot.Lookup(&scheduleAPI.weeklySchedule, slot), showChange.GetAfter()) {
+			return nil, errChangeConflict(change.GetId(), slot)
+		}
+	}
+
+	before := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+	record := scheduleAPI.recordChanges(ctx, "UndoChange")
+
+	for _, showChange := range change.GetShows() {
+		err := scheduleAPI.restoreShow(changedSlot(showChange), showChange.GetBefore(), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	record()
+
+	return &scheduler.UndoChangeResponse{
+		Differences: scheduleDifferences(snapshot.Diff(before, &scheduleAPI.weeklySchedule)),
+	}, nil
+}
+
+// The Pseudocode:
+// 1. Validate the input fields
+// 2. Check that the caller is an admin
+// 3. Lock the mutex and defer unlock
+// 4. Roll back a copy of the published schedule to the point in time using the change history and its archive
+// 5. Put back every show of the day, or of the week, as it was then, keeping the configured play times and votes
+// 6. Increase the versions of the shows that changed and their days
+// 7. Record the changes in the change history
+// 8. Return the differences made
+func (scheduleAPI *scheduleAPIServer) RestoreSchedule(
+	ctx context.Context, restoreReq *scheduler.RestoreScheduleRequest,
+) (*scheduler.RestoreScheduleResponse, error) {
+	// Validate the input
+	if restoreReq.GetRestoreTime() == nil {
+		return nil, errMissingCredential("Restore time")
+	}
+	if restoreReq.GetWeekDay() < 0 || restoreReq.GetWeekDay() > 7 {
+		return nil, errIncorrectVal("Week Day")
+	}
+	restoreTime, err := ptypes.Timestamp(restoreReq.GetRestoreTime())
+	if err != nil {
+		return nil, errIncorrectVal("Restore time")
+	}
+
+	// Only admins may restore the schedule
+	claims, _ := auth.FromContext(ctx)
+	if !claims.HasRole(auth.RoleAdmin) {
+		return nil, errPermissionDenied("RestoreSchedule")
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	weeklySchedule, err := scheduleAPI.scheduleAt(restoreTime)
+	if err != nil {
+		return nil, err
+	}
+
+	before := proto.Clone(&scheduleAPI.weeklySchedule).(*scheduler.DaysSchedule)
+	record := scheduleAPI.recordChanges(ctx, "RestoreSchedule")
+
+	for _, slot := range snapshot.Slots(before) {
+		if restoreReq.GetWeekDay() != 0 && slot.WeekDay != restoreReq.GetWeekDay() {
+			continue
+		}
+		showSchedule := snapshot.Lookup(weeklySchedule, slot)
+		if showSchedule == nil {
+			continue
+		}
+		err = scheduleAPI.restoreShow(slot, showSchedule, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	record()
+
+	return &scheduler.RestoreScheduleResponse{
+		Differences: scheduleDifferences(snapshot.Diff(before, &scheduleAPI.weeklySchedule)),
+	}, nil
+}
+
+// restoreShow puts back a show as it was, keeping its current play time and versions.
+// Votes cast since for the movies in both are kept if keepVotes is set.
+// The versions of the show and its day are increased if it changes.
+// Assumes that the mutex guarding weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) restoreShow(
+	slot snapshot.Slot, showSchedule *scheduler.ShowSchedule, keepVotes bool,
+) error {
+	current, err := scheduleAPI.getShowSchedule(slot.WeekDay, slot.Show, slot.Screen)
+	if err != nil {
+		return err
+	}
+
+	restored := proto.Clone(showSchedule).(*scheduler.ShowSchedule)
+	restored.PlayTime, restored.Version = current.PlayTime, current.Version
+	restored.VotesVersion = current.VotesVersion
+	if restored.Movie == nil {
+		restored.Movie = &movie.Movie{}
+	}
+	if keepVotes {
+		currentVotes := make(map[string]int32)
+		for _, movieItem := range append(current.GetVotedMovies(), current.GetMovie()) {
+			currentVotes[movieItem.GetId()] = movieItem.GetCurrentVotes()
+		}
+		for _, movieItem := range append(restored.GetVotedMovies(), restored.GetMovie()) {
+			if votes, ok := currentVotes[movieItem.GetId()]; ok && movieItem.GetId() != "" {
+				movieItem.CurrentVotes = votes
+			}
+		}
+	}
+	if proto.Equal(current, restored) {
+		return nil
+	}
+
+	*current = *restored
+	scheduleAPI.ledger.prune(slot, current)
+	scheduleAPI.showChanged(slot.WeekDay, slot.Show, slot.Screen)
+
+	return nil
+}
+
+func changedSlot(showChange *scheduler.ShowChange) snapshot.Slot {
+	return snapshot.Slot{WeekDay: showChange.GetWeekDay(), Screen: showChange.GetScreen(), Show: showChange.GetShow()}
+}
+
+// checks whether two shows play the same movie, with the same voted movies, at the same time
+func sameProgramme(showSchedule, other *scheduler.ShowSchedule) bool {
+	if showSchedule == nil || other == nil {
+		return showSchedule == other
+	}
+	if showSchedule.GetPlayTime() != other.GetPlayTime() ||
+		showSchedule.GetMovie().GetId() != other.GetMovie().GetId() ||
+		len(showSchedule.GetVotedMovies()) != len(other.GetVotedMovies()) {
+		return false
+	}
+	for i, votedMovie := range showSchedule.GetVotedMovies() {
+		if votedMovie.GetId() != other.GetVotedMovies()[i].GetId() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRestoreShow(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	// The show now plays m2 with v1 voted, and played m1 with v1 and m2 voted before
+	current := &scheduler.ShowSchedule{
+		PlayTime:     "10:00",
+		Movie:        &movie.Movie{Id: "m2", CurrentVotes: 5},
+		VotedMovies:  []*movie.Movie{{Id: "v1", CurrentVotes: 3}},
+		Version:      4,
+		VotesVersion: 6,
+	}
+	before := &scheduler.ShowSchedule{
+		PlayTime:    "09:00",
+		Movie:       &movie.Movie{Id: "m1", CurrentVotes: 1},
+		VotedMovies: []*movie.Movie{{Id: "v1"}, {Id: "m2", CurrentVotes: 2}},
+		Version:     1,
+	}
+
+	tests := []struct {
+		name        string
+		restored    *scheduler.ShowSchedule
+		keepVotes   bool
+		wantVotes   map[string]int32
+		wantMovie   string
+		wantVersion int64
+	}{
+		{
+			name:        "votes cast since are kept",
+			restored:    before,
+			keepVotes:   true,
+			wantMovie:   "m1",
+			wantVotes:   map[string]int32{"m1": 1, "v1": 3, "m2": 5},
+			wantVersion: 5,
+		},
+		{
+			name:        "votes are restored",
+			restored:    before,
+			wantMovie:   "m1",
+			wantVotes:   map[string]int32{"m1": 1, "v1": 0, "m2": 2},
+			wantVersion: 5,
+		},
+		{
+			name: "same show at another time and version is unchanged",
+			restored: &scheduler.ShowSchedule{
+				PlayTime:    "09:00",
+				Movie:       &movie.Movie{Id: "m2", CurrentVotes: 5},
+				VotedMovies: []*movie.Movie{{Id: "v1", CurrentVotes: 3}},
+				Version:     1,
+			},
+			wantMovie:   "m2",
+			wantVotes:   map[string]int32{"m2": 5, "v1": 3},
+			wantVersion: 4,
+		},
+		{
+			name:        "show without a movie",
+			restored:    &scheduler.ShowSchedule{},
+			wantMovie:   "",
+			wantVotes:   map[string]int32{"": 0},
+			wantVersion: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, current)
+			restored := proto.Clone(tt.restored).(*scheduler.ShowSchedule)
+
+			err := scheduleAPI.restoreShow(slot, tt.restored, tt.keepVotes)
+			if err != nil {
+				t.Fatalf("restoreShow() error = %v", err)
+			}
+
+			if !proto.Equal(restored, tt.restored) {
+				t.Errorf("restoreShow() changed the show it restored")
+			}
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if showSchedule.GetMovie() == nil {
+				t.Fatalf("restored show has no movie")
+			}
+			if got := showSchedule.GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+			votes := make(map[string]int32)
+			for _, movieItem := range append(showSchedule.GetVotedMovies(), showSchedule.GetMovie()) {
+				votes[movieItem.GetId()] = movieItem.GetCurrentVotes()
+			}
+			for movieID, want := range tt.wantVotes {
+				if votes[movieID] != want {
+					t.Errorf("votes of %q = %d, want %d", movieID, votes[movieID], want)
+				}
+			}
+			if showSchedule.GetPlayTime() != current.GetPlayTime() {
+				t.Errorf("play time = %q, want %q", showSchedule.GetPlayTime(), current.GetPlayTime())
+			}
+			if showSchedule.GetVersion() != tt.wantVersion {
+				t.Errorf("version = %d, want %d", showSchedule.GetVersion(), tt.wantVersion)
+			}
+			if showSchedule.GetVotesVersion() != current.GetVotesVersion() {
+				t.Errorf("votes version = %d, want %d", showSchedule.GetVotesVersion(), current.GetVotesVersion())
+			}
+		})
+	}
+}
+
+func TestRestoreShowNotInSchedule(t *testing.T) {
+	scheduleAPI := newTestServer(t)
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	err := scheduleAPI.restoreShow(slot, showPlaying("m1"), true)
+	if err == nil {
+		t.Fatal("restoreShow() restored a show that is not in the schedule")
+	}
+	if showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot); showSchedule != nil {
+		t.Errorf("show %s = %v, want none", slot, showSchedule)
+	}
+}
+
+func TestUndoChange(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name      string
+		roles     []string
+		method    string
+		changed   bool // whether the show changed again after the change
+		wantCode  codes.Code
+		wantMovie string
+	}{
+		{name: "programmer", roles: []string{auth.RoleProgrammer}, method: "UpdateMovieDaySchedule", wantMovie: "m1"},
+		{name: "admin", roles: []string{auth.RoleAdmin}, method: "UpdateMovieDaySchedule", wantMovie: "m1"},
+		{
+			name: "customer", method: "UpdateMovieDaySchedule",
+			wantCode: codes.PermissionDenied, wantMovie: "m2",
+		},
+		{
+			name: "vote", roles: []string{auth.RoleProgrammer}, method: "VoteUpMovie",
+			wantCode: codes.FailedPrecondition, wantMovie: "m2",
+		},
+		{
+			name: "show changed since", roles: []string{auth.RoleProgrammer}, method: "UpdateMovieDaySchedule",
+			changed: true, wantCode: codes.FailedPrecondition, wantMovie: "m3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+
+			// The change replaced m1 with m2
+			after := showPlaying("m2")
+			setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, after)
+			scheduleAPI.history.add(&scheduler.ScheduleChange{
+				ChangeTime: ptypes.TimestampNow(),
+				Method:     tt.method,
+				Shows: []*scheduler.ShowChange{{
+					WeekDay: slot.WeekDay, Screen: slot.Screen, Show: slot.Show,
+					Before: showPlaying("m1"), After: after,
+				}},
+			})
+			if tt.changed {
+				setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, showPlaying("m3"))
+			}
+
+			_, err := scheduleAPI.UndoChange(
+				userContext("u1", tt.roles...), &scheduler.UndoChangeRequest{ChangeId: scheduleAPI.history.lastID},
+			)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("UndoChange() error = %v, want code %s", err, tt.wantCode)
+			}
+			if got := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot).GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+
+			wantChanges := 1
+			if err == nil {
+				wantChanges = 2
+			}
+			if len(scheduleAPI.history.changes) != wantChanges {
+				t.Errorf("%d changes recorded, want %d", len(scheduleAPI.history.changes), wantChanges)
+			}
+		})
+	}
+}
+
+func TestRestoreSchedule(t *testing.T) {
+	since := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name      string
+		archive   bool
+		archived  bool // whether the dropped changes are archived before restoring
+		at        time.Time
+		roles     []string
+		wantCode  codes.Code
+		wantMovie string
+		wantVotes int32
+	}{
+		{
+			name: "from the change history", archive: true, at: since.Add(150 * time.Minute),
+			roles: []string{auth.RoleAdmin}, wantMovie: "m2",
+		},
+		{
+			name: "from changes not archived yet", archive: true, at: since.Add(90 * time.Minute),
+			roles: []string{auth.RoleAdmin}, wantMovie: "m1", wantVotes: 4,
+		},
+		{
+			name: "from the archive", archive: true, archived: true, at: since.Add(90 * time.Minute),
+			roles: []string{auth.RoleAdmin}, wantMovie: "m1", wantVotes: 4,
+		},
+		{
+			name: "to when the archive starts", archive: true, archived: true, at: since,
+			roles: []string{auth.RoleAdmin}, wantMovie: "m0",
+		},
+		{
+			name: "before the archive", archive: true, archived: true, at: since.Add(-time.Second),
+			roles: []string{auth.RoleAdmin}, wantCode: codes.OutOfRange, wantMovie: "m3",
+		},
+		{
+			name: "older than the change history without an archive", at: since.Add(90 * time.Minute),
+			roles: []string{auth.RoleAdmin}, wantCode: codes.OutOfRange, wantMovie: "m3",
+		},
+		{
+			name: "programmer", archive: true, at: since.Add(150 * time.Minute),
+			roles: []string{auth.RoleProgrammer}, wantCode: codes.PermissionDenied, wantMovie: "m3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			scheduleAPI.opts.Store = NewMemoryStore()
+			scheduleAPI.opts.HistoryArchiveSize = 10
+			scheduleAPI.opts.HistoryArchive = NewMemoryStore()
+			scheduleAPI.history = newChangeHistory(1)
+			scheduleAPI.history.archive = tt.archive
+			scheduleAPI.history.since = since
+
+			// The show played m0 to m3 an hour apart, and m3 has m1 voted with 4 votes now
+			playing := []string{"m0", "m1", "m2", "m3"}
+			for i := 1; i < len(playing); i++ {
+				changeTime, _ := ptypes.TimestampProto(since.Add(time.Duration(i) * time.Hour))
+				scheduleAPI.history.add(&scheduler.ScheduleChange{
+					ChangeTime: changeTime,
+					Method:     "UpdateMovieDaySchedule",
+					Shows: []*scheduler.ShowChange{{
+						WeekDay: slot.WeekDay, Screen: slot.Screen, Show: slot.Show,
+						Before: showPlaying(playing[i-1]), After: showPlaying(playing[i]),
+					}},
+				})
+			}
+			current := showPlaying("m3")
+			current.VotedMovies = []*movie.Movie{{Id: "m1", CurrentVotes: 4}}
+			setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, current)
+			scheduleAPI.reindex()
+
+			if tt.archived {
+				err := scheduleAPI.saveSnapshot()
+				if err != nil {
+					t.Fatalf("saveSnapshot() failed: %v", err)
+				}
+				if len(scheduleAPI.history.dropped) != 0 {
+					t.Fatalf("%d dropped changes are not archived", len(scheduleAPI.history.dropped))
+				}
+			}
+
+			restoreTime, _ := ptypes.TimestampProto(tt.at)
+			_, err := scheduleAPI.RestoreSchedule(
+				userContext("a1", tt.roles...), &scheduler.RestoreScheduleRequest{RestoreTime: restoreTime},
+			)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("RestoreSchedule() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if got := showSchedule.GetMovie().GetId(); got != tt.wantMovie {
+				t.Errorf("movie = %q, want %q", got, tt.wantMovie)
+			}
+			if err == nil && showSchedule.GetMovie().GetCurrentVotes() != tt.wantVotes {
+				t.Errorf("votes = %d, want %d", showSchedule.GetMovie().GetCurrentVotes(), tt.wantVotes)
+			}
+		})
+	}
+}
@@ -8,8 +8,20 @@ import (
 	"time"
 )
 
-// retrieves updated movie resource for a all days schedule after 10 minutes
+// retrieves updated movie resource for all days schedule periodically
 func (scheduleAPI *scheduleAPIServer) updateMovies() {
+	for {
+		select {
+		case <-scheduleAPI.ctx.Done():
+			return
+		case <-time.After(scheduleAPI.options().MovieRefreshInterval):
+			scheduleAPI.refreshMovies()
+		}
+	}
+}
+
+// refreshMovies retrieves the movie resource of every movie in the schedule once
+func (scheduleAPI *scheduleAPIServer) refreshMovies() {
 	type updatedMovie struct {
 		weekDay       int32
 		showNumber    int32
@@ -17,125 +29,127 @@ func (scheduleAPI *scheduleAPIServer) updateMovies() {
 		movieResource *movie.Movie
 		err           error
 	}
-	resChan := make(chan updatedMovie, maxMoviesVoted+1)
 
-	for {
-		select {
-		case <-scheduleAPI.ctx.Done():
-			return
-		case <-time.After(time.Duration(20 * time.Minute)):
-			// Send requests to retrieve movie resource concurrently
-			// Use channel to send the result of the goroutine fetching the movie
-			// Lock the mutex, update the movie and unlock it
-
-			func() {
-				// Waitgroup to wait for goroutines to finish
-				wg := &sync.WaitGroup{}
+	// Send requests to retrieve movie resource concurrently
+	// Use channel to send the result of the goroutine fetching the movie
+	// Lock the mutex, update the movie and unlock it
+	// Waitgroup to wait for goroutines to finish
+	wg := &sync.WaitGroup{}
 
-				// lock the muSchedule mutex
-				scheduleAPI.muSchedule.Lock()
-				for _, weekDay := range weekDays {
-					weekDay := weekDay
-					for _, screen := range screensAvailable {
-						screen := screen
-						for _, show := range showsPerDay {
-							showNumber := show.showID
-							showSchedule, err := scheduleAPI.getShowSchedule(
-								weekDay, showNumber, screen,
-							)
-							if err != nil {
-								logger.Log.Warn(
-									"error while getting schedule",
-									zap.Error(err),
-									zap.String("Operation", "updateMovies"),
-								)
-								continue
-							}
-							// Range voted movies
-							for _, votedMovie := range showSchedule.VotedMovies {
-								wg.Add(1)
-								go func(movieID string) {
-									// Get the movie resource
-									movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
-										scheduleAPI.ctx,
-										&movie.GetMovieRequest{
-											MovieId: movieID,
-										},
-									)
-									// Send the movie resource to the channel
-									select {
-									case <-scheduleAPI.ctx.Done():
-										return
-									case resChan <- updatedMovie{
-										weekDay:       weekDay,
-										showNumber:    showNumber,
-										screen:        screen,
-										movieResource: movieItem,
-										err:           err,
-									}:
-									}
-								}(votedMovie.Id)
-							}
+	// lock the muSchedule mutex
+	scheduleAPI.muSchedule.Lock()
 
-							// Get the movie in display
-							wg.Add(1)
-							movieID := showSchedule.Movie.Id
-							go func() {
-								// Get the movie resource
-								movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
-									scheduleAPI.ctx,
-									&movie.GetMovieRequest{
-										MovieId: movieID,
-									},
-								)
-								// Send the movie resource to the channel
-								select {
-								case <-scheduleAPI.ctx.Done():
-									return
-								case resChan <- updatedMovie{
-									weekDay:       weekDay,
-									showNumber:    showNumber,
-									screen:        screen,
-									movieResource: movieItem,
-									err:           err,
-								}:
-								}
+	// The channel is closed at the end of every cycle
+	resChan := make(chan updatedMovie, scheduleAPI.opts.MaxMoviesVoted+1)
 
-							}()
-						}
+	for _, weekDay := range weekDays {
+		weekDay := weekDay
+		for _, screen := range scheduleAPI.opts.Screens {
+			screen := screen
+			for _, show := range scheduleAPI.opts.Shows {
+				showNumber := show.ID
+				showSchedule, err := scheduleAPI.getShowSchedule(
+					weekDay, showNumber, screen,
+				)
+				if err != nil {
+					logger.Log.Warn(
+						"error while getting schedule",
+						zap.Error(err),
+						zap.String("Operation", "updateMovies"),
+					)
+					continue
+				}
+				// Range voted movies
+				for _, votedMovie := range showSchedule.VotedMovies {
+					if votedMovie.GetId() == "" {
+						continue
 					}
+					wg.Add(1)
+					go func(movieID string) {
+						defer wg.Done()
+						// Get the movie resource
+						movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
+							scheduleAPI.ctx,
+							&movie.GetMovieRequest{
+								MovieId: movieID,
+							},
+						)
+						// Send the movie resource to the channel
+						select {
+						case <-scheduleAPI.ctx.Done():
+							return
+						case resChan <- updatedMovie{
+							weekDay:       weekDay,
+							showNumber:    showNumber,
+							screen:        screen,
+							movieResource: movieItem,
+							err:           err,
+						}:
+						}
+					}(votedMovie.Id)
 				}
-				// Unlock the mutex
-				scheduleAPI.muSchedule.Unlock()
 
+				// Get the movie in display. Shows without a movie hold an empty placeholder
+				movieID := showSchedule.GetMovie().GetId()
+				if movieID == "" {
+					continue
+				}
+				wg.Add(1)
 				go func() {
-					// Wait for all goroutines to complete
-					wg.Wait()
-					// Then close the result channel
-					close(resChan)
+					defer wg.Done()
+					// Get the movie resource
+					movieItem, err := scheduleAPI.movieAPIClient.GetMovie(
+						scheduleAPI.ctx,
+						&movie.GetMovieRequest{
+							MovieId: movieID,
+						},
+					)
+					// Send the movie resource to the channel
+					select {
+					case <-scheduleAPI.ctx.Done():
+						return
+					case resChan <- updatedMovie{
+						weekDay:       weekDay,
+						showNumber:    showNumber,
+						screen:        screen,
+						movieResource: movieItem,
+						err:           err,
+					}:
+					}
+
 				}()
+			}
+		}
+	}
+	// Unlock the mutex
+	scheduleAPI.muSchedule.Unlock()
 
-				// Range over the results
-				for res := range resChan {
+	go func() {
+		// Wait for all goroutines to complete
+		wg.Wait()
+		// Then close the result channel
+		close(resChan)
+	}()
 
-					// Lock the mutex
-					scheduleAPI.muSchedule.Lock()
+	// Range over the results
+	for res := range resChan {
+		if res.err != nil {
+			movieRefreshFailures.Inc()
+			logger.Log.Error(
+				"Error while fetching result",
+				zap.Error(res.err),
+			)
+			continue
+		}
 
-					if res.err != nil {
-						logger.Log.Error(
-							"Error while fetching result",
-							zap.Error(res.err),
-						)
-						continue
-					}
-					scheduleAPI.updateMovieInfo(
-						res.weekDay, res.showNumber, res.screen, res.movieResource,
-					)
+		// Lock the mutex
+		scheduleAPI.muSchedule.Lock()
 
-					// Unlock the mutex
-					scheduleAPI.muSchedule.Unlock()
-				}
-			}()
-		}
+		scheduleAPI.updateMovieInfo(
+			res.weekDay, res.showNumber, res.screen, res.movieResource,
+		)
+
+		// Unlock the mutex
+		scheduleAPI.muSchedule.Unlock()
 	}
 }
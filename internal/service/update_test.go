@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+)
+
+// countingMovieAPI records the ids of the movies requested from the fake movie service
+type countingMovieAPI struct {
+	fakeMovieAPI
+	mu       sync.Mutex
+	movieIDs []string
+}
+
+func (movieAPI *countingMovieAPI) GetMovie(
+	ctx context.Context, getReq *movie.GetMovieRequest, opts ...grpc.CallOption,
+) (*movie.Movie, error) {
+	movieAPI.mu.Lock()
+	movieAPI.movieIDs = append(movieAPI.movieIDs, getReq.GetMovieId())
+	movieAPI.mu.Unlock()
+	return movieAPI.fakeMovieAPI.GetMovie(ctx, getReq, opts...)
+}
+
+func TestRefreshMovies(t *testing.T) {
+	scheduleAPI := newTestServer(t)
+	movieAPI := &countingMovieAPI{}
+	scheduleAPI.movieAPIClient = movieAPI
+
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+	showSchedule.Movie = &movie.Movie{Id: "m1", Title: "Old title", CurrentVotes: 2}
+	showSchedule.VotedMovies = []*movie.Movie{{Id: "v1", CurrentVotes: 1}, {Id: "missing1"}}
+
+	failures := testutil.ToFloat64(movieRefreshFailures)
+	scheduleAPI.refreshMovies()
+
+	// Shows without a movie hold empty placeholders that are not requested
+	sort.Strings(movieAPI.movieIDs)
+	want := []string{"m1", "missing1", "v1"}
+	if len(movieAPI.movieIDs) != len(want) {
+		t.Fatalf("requested movies %v, want %v", movieAPI.movieIDs, want)
+	}
+	for i := range want {
+		if movieAPI.movieIDs[i] != want[i] {
+			t.Fatalf("requested movies %v, want %v", movieAPI.movieIDs, want)
+		}
+	}
+	if got := testutil.ToFloat64(movieRefreshFailures) - failures; got != 1 {
+		t.Errorf("movie refresh failures increased by %v, want 1", got)
+	}
+
+	if showSchedule.GetMovie().GetTitle() != "Movie m1" || showSchedule.GetMovie().GetCurrentVotes() != 2 {
+		t.Errorf("showing movie = %v, want it refreshed with its votes", showSchedule.GetMovie())
+	}
+	if showSchedule.GetVotedMovies()[0].GetTitle() != "Movie v1" {
+		t.Errorf("voted movie = %v, want it refreshed", showSchedule.GetVotedMovies()[0])
+	}
+}
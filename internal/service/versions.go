@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ifMatchHeader is the metadata key of the version a request expects the show to have.
+// The REST gateway forwards the If-Match header under it
+const ifMatchHeader = "if-match"
+
+// showChanged increments the version of a show and of its day, then updates the movie index
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) showChanged(weekDay, show int32, screen string) {
+	if daySchedule, ok := scheduleAPI.weeklySchedule.DaysSchedule[weekDay]; ok {
+		daySchedule.Version++
+		if showSchedule, err := scheduleAPI.getShowSchedule(weekDay, show, screen); err == nil {
+			showSchedule.Version++
+		}
+	}
+	scheduleAPI.reindexShow(weekDay, show, screen)
+}
+
+// votesChanged increments the votes version of a show and of its day.
+// Votes do not change the programme, so edits expecting a version are not affected by them
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) votesChanged(weekDay, show int32, screen string) {
+	if daySchedule, ok := scheduleAPI.weeklySchedule.DaysSchedule[weekDay]; ok {
+		daySchedule.VotesVersion++
+		if showSchedule, err := scheduleAPI.getShowSchedule(weekDay, show, screen); err == nil {
+			showSchedule.VotesVersion++
+		}
+	}
+}
+
+// checkVersion fails with Aborted when the show is not at the expected version.
+// An expected version of 0 skips the check
+func checkVersion(showSchedule *scheduler.ShowSchedule, expectedVersion int64) error {
+	if expectedVersion == 0 || showSchedule.GetVersion() == expectedVersion {
+		return nil
+	}
+	return errVersionMismatch(expectedVersion, showSchedule.GetVersion())
+}
+
+// checkShowVersion gets a show and checks that it is at the expected version
+// Assumes that the mutex gurading weeklySchedule is locked
+func (scheduleAPI *scheduleAPIServer) checkShowVersion(
+	weekDay, show int32, screen string, expectedVersion int64,
+) error {
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, show, screen)
+	if err != nil {
+		return err
+	}
+	return checkVersion(showSchedule, expectedVersion)
+}
+
+// ifMatchVersion returns the version in the if-match metadata of the request, or 0 if there is none.
+// Quoted and weak entity tags are accepted. The votes version after the dot in entity tags is ignored
+func ifMatchVersion(ctx context.Context, expectedVersion int64) (int64, error) {
+	if expectedVersion != 0 {
+		return expectedVersion, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(ifMatchHeader)) == 0 {
+		return 0, nil
+	}
+	etag := strings.Trim(strings.TrimPrefix(md.Get(ifMatchHeader)[0], "W/"), `"`)
+	if etag == "*" {
+		return 0, nil
+	}
+	etag = strings.SplitN(etag, ".", 2)[0]
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid if-match version %q", etag)
+	}
+	return version, nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		ifMatch         []string
+		expectedVersion int64
+		want            int64
+		wantCode        codes.Code
+	}{
+		{name: "no header", want: 0},
+		{name: "expected version in request", ifMatch: []string{`"3"`}, expectedVersion: 5, want: 5},
+		{name: "quoted", ifMatch: []string{`"3"`}, want: 3},
+		{name: "weak", ifMatch: []string{`W/"3"`}, want: 3},
+		{name: "with votes version", ifMatch: []string{`"3.7"`}, want: 3},
+		{name: "any", ifMatch: []string{"*"}, want: 0},
+		{name: "not a number", ifMatch: []string{`"abc"`}, wantCode: codes.InvalidArgument},
+		{name: "not positive", ifMatch: []string{`"0"`}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, ifMatch := range tt.ifMatch {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ifMatchHeader, ifMatch))
+			}
+
+			got, err := ifMatchVersion(ctx, tt.expectedVersion)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ifMatchVersion() error = %v, want code %s", err, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("ifMatchVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name             string
+		votes            []string
+		wantVersion      int64
+		wantVotesVersion int64
+	}{
+		{
+			name:        "edits without votes",
+			wantVersion: 3,
+		},
+		{
+			name:             "vote for the showing movie",
+			votes:            []string{"m1"},
+			wantVersion:      3,
+			wantVotesVersion: 1,
+		},
+		{
+			name:             "votes for a voted movie that does not overtake the showing movie",
+			votes:            []string{"m1", "m1", "v1"},
+			wantVersion:      3,
+			wantVotesVersion: 3,
+		},
+		{
+			name:             "vote that swaps the showing movie",
+			votes:            []string{"v1"},
+			wantVersion:      4,
+			wantVotesVersion: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			ctx := userContext("p1", auth.RoleProgrammer)
+
+			_, err := scheduleAPI.CreateMovieDaySchedule(ctx, &scheduler.CreateMovieDayScheduleRequest{
+				WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "m1",
+			})
+			if err != nil {
+				t.Fatalf("CreateMovieDaySchedule() failed: %v", err)
+			}
+			_, err = scheduleAPI.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+				WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v1",
+			})
+			if err != nil {
+				t.Fatalf("AddVotedMovie() failed: %v", err)
+			}
+
+			for i, movieID := range tt.votes {
+				_, err = scheduleAPI.VoteUpMovie(userContext(fmt.Sprintf("u%d", i)), &scheduler.VoteUpMovieRequest{
+					WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: movieID,
+				})
+				if err != nil {
+					t.Fatalf("VoteUpMovie(%q) failed: %v", movieID, err)
+				}
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if showSchedule.GetVersion() != tt.wantVersion {
+				t.Errorf("version = %d, want %d", showSchedule.GetVersion(), tt.wantVersion)
+			}
+			if showSchedule.GetVotesVersion() != tt.wantVotesVersion {
+				t.Errorf("votes version = %d, want %d", showSchedule.GetVotesVersion(), tt.wantVotesVersion)
+			}
+			daySchedule := scheduleAPI.weeklySchedule.GetDaysSchedule()[slot.WeekDay]
+			if daySchedule.GetVersion() != tt.wantVersion {
+				t.Errorf("day version = %d, want %d", daySchedule.GetVersion(), tt.wantVersion)
+			}
+			if daySchedule.GetVotesVersion() != tt.wantVotesVersion {
+				t.Errorf("day votes version = %d, want %d", daySchedule.GetVotesVersion(), tt.wantVotesVersion)
+			}
+
+			// Edits expecting the version are not affected by votes
+			_, err = scheduleAPI.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+				WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v2",
+				ExpectedVersion: tt.wantVersion - 1,
+			})
+			if status.Code(err) != codes.Aborted {
+				t.Errorf("AddVotedMovie() at a stale version error = %v, want code %s", err, codes.Aborted)
+			}
+			_, err = scheduleAPI.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+				WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v2",
+				ExpectedVersion: tt.wantVersion,
+			})
+			if err != nil {
+				t.Errorf("AddVotedMovie() at the current version failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestMovieRefresh(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	scheduleAPI := newTestServer(t)
+	ctx := userContext("p1", auth.RoleProgrammer)
+
+	_, err := scheduleAPI.CreateMovieDaySchedule(ctx, &scheduler.CreateMovieDayScheduleRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "m1",
+	})
+	if err != nil {
+		t.Fatalf("CreateMovieDaySchedule() failed: %v", err)
+	}
+	_, err = scheduleAPI.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v1",
+	})
+	if err != nil {
+		t.Fatalf("AddVotedMovie() failed: %v", err)
+	}
+	for i, movieID := range []string{"m1", "m1", "v1"} {
+		_, err = scheduleAPI.VoteUpMovie(userContext(fmt.Sprintf("u%d", i)), &scheduler.VoteUpMovieRequest{
+			WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: movieID,
+		})
+		if err != nil {
+			t.Fatalf("VoteUpMovie(%q) failed: %v", movieID, err)
+		}
+	}
+
+	showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+	version, votesVersion := showSchedule.GetVersion(), showSchedule.GetVotesVersion()
+
+	// The movie service knows nothing of the votes cast in the scheduler
+	for i := 0; i < 2; i++ {
+		for _, movieID := range []string{"m1", "v1"} {
+			scheduleAPI.updateMovieInfo(slot.WeekDay, slot.Show, slot.Screen, &movie.Movie{
+				Id: movieID, Title: "Refreshed " + movieID,
+			})
+		}
+	}
+
+	for movieID, want := range map[string]int64{"m1": 2, "v1": 1} {
+		movieItem := showSchedule.GetMovie()
+		if movieID != movieItem.GetId() {
+			movieItem = showSchedule.GetVotedMovies()[0]
+		}
+		if movieItem.GetTitle() != "Refreshed "+movieID {
+			t.Errorf("movie %s title = %q, want it refreshed", movieID, movieItem.GetTitle())
+		}
+		if movieItem.GetCurrentVotes() != want {
+			t.Errorf("movie %s votes = %d, want %d", movieID, movieItem.GetCurrentVotes(), want)
+		}
+	}
+	if showSchedule.GetVersion() != version || showSchedule.GetVotesVersion() != votesVersion {
+		t.Errorf("versions = %d.%d, want %d.%d",
+			showSchedule.GetVersion(), showSchedule.GetVotesVersion(), version, votesVersion)
+	}
+
+	// Edits expecting the version before the refresh still apply
+	_, err = scheduleAPI.AddVotedMovie(ctx, &scheduler.AddVotedMovieRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v2",
+		ExpectedVersion: version,
+	})
+	if err != nil {
+		t.Errorf("AddVotedMovie() at the version before the refresh failed: %v", err)
+	}
+}
+
+func TestReadsReturnCopies(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	scheduleAPI := newTestServer(t)
+	setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+		PlayTime: "10:00",
+		Movie:    &movie.Movie{Id: "m1", CurrentVotes: 1},
+		Version:  1,
+	})
+	scheduleAPI.reindex()
+
+	voteReq := &scheduler.VoteUpMovieRequest{
+		WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "m1",
+	}
+	voted, err := scheduleAPI.VoteUpMovie(userContext("u1"), voteReq)
+	if err != nil {
+		t.Fatalf("VoteUpMovie() failed: %v", err)
+	}
+	showSchedule, err := scheduleAPI.GetShowSchedule(context.Background(), &scheduler.GetShowScheduleRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen,
+	})
+	if err != nil {
+		t.Fatalf("GetShowSchedule() failed: %v", err)
+	}
+	daySchedule, err := scheduleAPI.GetDaySchedule(context.Background(), &scheduler.GetDayScheduleRequest{
+		WeekDay: slot.WeekDay,
+	})
+	if err != nil {
+		t.Fatalf("GetDaySchedule() failed: %v", err)
+	}
+
+	// Responses are marshaled after the mutex is unlocked, so later votes must not change them
+	_, err = scheduleAPI.VoteUpMovie(userContext("u2"), voteReq)
+	if err != nil {
+		t.Fatalf("VoteUpMovie() failed: %v", err)
+	}
+
+	if voted.GetCurrentVotes() != 2 {
+		t.Errorf("voted movie votes = %d, want 2", voted.GetCurrentVotes())
+	}
+	if showSchedule.GetMovie().GetCurrentVotes() != 2 || showSchedule.GetVotesVersion() != 1 {
+		t.Errorf("show = %v, want the show before the second vote", showSchedule)
+	}
+	dayShow := daySchedule.GetScreensSchedule()[slot.Screen].GetShowsSchedule()[slot.Show]
+	if dayShow.GetMovie().GetCurrentVotes() != 2 || dayShow.GetVotesVersion() != 1 {
+		t.Errorf("day show = %v, want the show before the second vote", dayShow)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// The Pseudocode:
+// 1. Validate the input fields from the request
+// 2. Lock the mutex and defer unlock
+// 3. Check that the show is at the expected version, if the request has one
+// 4. Ensure that the movie is in the voted movies section
+// 5. Remove the movie, freeing its seat in the voted movies section
+// 6. Take the votes cast for the movie out of the vote ledger, crediting them to the users that cast them if requested.
+// Votes whose voters the ledger does not know are discarded
+// 7. Return the refunds and the number of votes discarded
+func (scheduleAPI *scheduleAPIServer) RemoveVotedMovie(
+	ctx context.Context, removeReq *scheduler.RemoveVotedMovieRequest,
+) (*scheduler.RemoveVotedMovieResponse, error) {
+	weekDay := removeReq.GetWeekDay()
+	screen := removeReq.GetScreen()
+	showNumber := removeReq.GetShow()
+	movieID := removeReq.GetMovieId()
+
+	// Validate the input fields from request
+	err := func() error {
+		var err error
+		switch {
+		case weekDay <= 0 || weekDay > 7:
+			err = errIncorrectVal("Week day")
+		case strings.Trim(screen, " ") == "":
+			err = errMissingCredential("Screen")
+		case showNumber <= 0:
+			err = errIncorrectVal("Show number")
+		case strings.Trim(movieID, " ") == "":
+			err = errMissingCredential("Movie ID")
+		case removeReq.GetDraft() && removeReq.GetRefundVotes():
+			err = errRefundFromDraft()
+		}
+		return err
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "RemoveVotedMovie")
+	if err != nil {
+		return nil, err
+	}
+
+	// The If-Match header is the expected version when the request has none
+	removeReq.ExpectedVersion, err = ifMatchVersion(ctx, removeReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if removeReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "RemoveVotedMovie")()
+	}
+
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return err if the show changed since the version the request expects
+	err = checkVersion(showSchedule, removeReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	removedIndex := -1
+	for index, votedMovie := range showSchedule.VotedMovies {
+		if votedMovie.Id == movieID {
+			removedIndex = index
+			break
+		}
+	}
+
+	// Return err if it isn't a voted movie
+	if removedIndex < 0 {
+		return nil, errNoVotedMovie(movieID)
+	}
+	removed := showSchedule.VotedMovies[removedIndex]
+
+	showSchedule.VotedMovies = append(
+		showSchedule.VotedMovies[:removedIndex], showSchedule.VotedMovies[removedIndex+1:]...,
+	)
+
+	res := &scheduler.RemoveVotedMovieResponse{
+		Refunds:        make([]*scheduler.VoteRefund, 0),
+		DiscardedVotes: removed.CurrentVotes,
+	}
+
+	slot := snapshot.Slot{WeekDay: weekDay, Screen: screen, Show: showNumber}
+	userVotes := scheduleAPI.ledger.take(slot, movieID)
+	if removeReq.GetRefundVotes() {
+		scheduleAPI.ledger.credit(slot, userVotes)
+		res.Refunds = refunds(userVotes)
+		for _, refund := range res.Refunds {
+			res.DiscardedVotes -= refund.Votes
+		}
+	}
+
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
+
+	return res, nil
+}
+
+// The Pseudocode:
+// 1. Validate the input fields from the request
+// 2. Lock the mutex and defer unlock
+// 3. Check that the show is at the expected version, if the request has one
+// 4. Ensure that the movie ids are exactly the voted movies of the show
+// 5. Reorder the voted movies. When voted movies tie on votes, the first one is swapped in for the showing movie
+// 6. Return success
+func (scheduleAPI *scheduleAPIServer) ReorderVotedMovies(
+	ctx context.Context, reorderReq *scheduler.ReorderVotedMoviesRequest,
+) (*empty.Empty, error) {
+	weekDay := reorderReq.GetWeekDay()
+	screen := reorderReq.GetScreen()
+	showNumber := reorderReq.GetShow()
+	movieIDs := reorderReq.GetMovieIds()
+
+	// Validate the input fields from request
+	err := func() error {
+		var err error
+		switch {
+		case weekDay <= 0 || weekDay > 7:
+			err = errIncorrectVal("Week day")
+		case strings.Trim(screen, " ") == "":
+			err = errMissingCredential("Screen")
+		case showNumber <= 0:
+			err = errIncorrectVal("Show number")
+		}
+		return err
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may edit the schedule or its draft
+	err = editAllowed(ctx, "ReorderVotedMovies")
+	if err != nil {
+		return nil, err
+	}
+
+	// The If-Match header is the expected version when the request has none
+	reorderReq.ExpectedVersion, err = ifMatchVersion(ctx, reorderReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock the muSchedule mutex and defer unlock
+	scheduleAPI.lockSchedule(ctx)
+	defer scheduleAPI.muSchedule.Unlock()
+
+	// Edit the draft instead of the published schedule if requested,
+	// otherwise record the changes in the change history
+	if reorderReq.GetDraft() {
+		defer scheduleAPI.useDraft()()
+	} else {
+		defer scheduleAPI.recordChanges(ctx, "ReorderVotedMovies")()
+	}
+
+	showSchedule, err := scheduleAPI.getShowSchedule(weekDay, showNumber, screen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return err if the show changed since the version the request expects
+	err = checkVersion(showSchedule, reorderReq.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	votedMovies := make(map[string]*movie.Movie, len(showSchedule.VotedMovies))
+	for _, votedMovie := range showSchedule.VotedMovies {
+		votedMovies[votedMovie.Id] = votedMovie
+	}
+
+	if len(movieIDs) != len(votedMovies) {
+		return nil, errVotedMovieOrder(len(votedMovies), len(movieIDs))
+	}
+
+	reordered := make([]*movie.Movie, 0, len(movieIDs))
+	seen := make(map[string]bool, len(movieIDs))
+	for _, movieID := range movieIDs {
+		if seen[movieID] {
+			return nil, errDuplicateVotedMovie(movieID)
+		}
+		seen[movieID] = true
+		votedMovie, ok := votedMovies[movieID]
+		if !ok {
+			return nil, errNoVotedMovie(movieID)
+		}
+		reordered = append(reordered, votedMovie)
+	}
+
+	showSchedule.VotedMovies = reordered
+	scheduleAPI.showChanged(weekDay, showNumber, screen)
+
+	return &empty.Empty{}, nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/internal/auth"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// plays m1 with v1 and v2 voted in a slot of the test server, where u1 and u2 cast the votes for v1
+func seedVotedShow(t *testing.T, scheduleAPI *scheduleAPIServer, slot snapshot.Slot) {
+	t.Helper()
+	setShow(&scheduleAPI.weeklySchedule, slot.WeekDay, slot.Screen, slot.Show, &scheduler.ShowSchedule{
+		PlayTime:    "10:00",
+		Movie:       &movie.Movie{Id: "m1", CurrentVotes: 5},
+		VotedMovies: []*movie.Movie{{Id: "v1"}, {Id: "v2"}},
+		Version:     1,
+	})
+	scheduleAPI.reindex()
+	for _, userID := range []string{"u1", "u2", "u1"} {
+		_, err := scheduleAPI.VoteUpMovie(userContext(userID), &scheduler.VoteUpMovieRequest{
+			WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "v1",
+		})
+		if err != nil {
+			t.Fatalf("VoteUpMovie() failed: %v", err)
+		}
+	}
+}
+
+func TestRemoveVotedMovie(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name          string
+		req           *scheduler.RemoveVotedMovieRequest
+		unknownVotes  int32 // votes for v1 whose voters are not in the ledger
+		wantCode      codes.Code
+		wantRefunds   map[string]int32
+		wantDiscarded int32
+		wantVoted     []string
+		wantCredits   map[string]int32
+	}{
+		{
+			name: "votes refunded",
+			req: &scheduler.RemoveVotedMovieRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1", RefundVotes: true,
+			},
+			wantRefunds: map[string]int32{"u1": 2, "u2": 1},
+			wantVoted:   []string{"v2"},
+			wantCredits: map[string]int32{"u1": 2, "u2": 1},
+		},
+		{
+			name:          "votes discarded",
+			req:           &scheduler.RemoveVotedMovieRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1"},
+			wantRefunds:   map[string]int32{},
+			wantDiscarded: 3,
+			wantVoted:     []string{"v2"},
+		},
+		{
+			name: "votes with unknown voters are not refunded",
+			req: &scheduler.RemoveVotedMovieRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1", RefundVotes: true,
+			},
+			unknownVotes:  2,
+			wantRefunds:   map[string]int32{"u1": 2, "u2": 1},
+			wantDiscarded: 2,
+			wantVoted:     []string{"v2"},
+			wantCredits:   map[string]int32{"u1": 2, "u2": 1},
+		},
+		{
+			name:          "votes with unknown voters are discarded",
+			req:           &scheduler.RemoveVotedMovieRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1"},
+			unknownVotes:  2,
+			wantRefunds:   map[string]int32{},
+			wantDiscarded: 5,
+			wantVoted:     []string{"v2"},
+		},
+		{
+			name:      "movie that is not voted",
+			req:       &scheduler.RemoveVotedMovieRequest{WeekDay: 1, Show: 1, Screen: "A", MovieId: "m1"},
+			wantCode:  codes.NotFound,
+			wantVoted: []string{"v1", "v2"},
+		},
+		{
+			name: "refund from the draft",
+			req: &scheduler.RemoveVotedMovieRequest{
+				WeekDay: 1, Show: 1, Screen: "A", MovieId: "v1", RefundVotes: true, Draft: true,
+			},
+			wantCode:  codes.InvalidArgument,
+			wantVoted: []string{"v1", "v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedVotedShow(t, scheduleAPI, slot)
+			snapshot.Lookup(&scheduleAPI.weeklySchedule, slot).VotedMovies[0].CurrentVotes += tt.unknownVotes
+
+			res, err := scheduleAPI.RemoveVotedMovie(userContext("p1", auth.RoleProgrammer), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("RemoveVotedMovie() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, tt.wantVoted) {
+				t.Errorf("voted movies = %v, want %v", got, tt.wantVoted)
+			}
+			if err != nil {
+				return
+			}
+			if len(res.GetRefunds()) != len(tt.wantRefunds) {
+				t.Fatalf("refunds = %v, want %v", res.GetRefunds(), tt.wantRefunds)
+			}
+			for _, refund := range res.GetRefunds() {
+				if refund.GetVotes() != tt.wantRefunds[refund.GetUserId()] {
+					t.Errorf(
+						"refunded %d votes to %q, want %d",
+						refund.GetVotes(), refund.GetUserId(), tt.wantRefunds[refund.GetUserId()],
+					)
+				}
+			}
+			if res.GetDiscardedVotes() != tt.wantDiscarded {
+				t.Errorf("discarded votes = %d, want %d", res.GetDiscardedVotes(), tt.wantDiscarded)
+			}
+			if got := scheduleAPI.ledger.count(slot, "v1"); got != 0 {
+				t.Errorf("ledger keeps %d votes for the removed movie", got)
+			}
+			credits := scheduleAPI.ledger.credits[slot]
+			if len(credits) != len(tt.wantCredits) {
+				t.Fatalf("credits = %v, want %v", credits, tt.wantCredits)
+			}
+			for userID, votes := range tt.wantCredits {
+				if credits[userID] != votes {
+					t.Errorf("credits = %v, want %v", credits, tt.wantCredits)
+				}
+			}
+		})
+	}
+}
+
+func TestRefundAfterReload(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+	programmerCtx := userContext("p1", auth.RoleProgrammer)
+
+	saved := newTestServer(t)
+	saved.opts.Store = NewMemoryStore()
+	saved.opts.LedgerStore = NewMemoryStore()
+	seedVotedShow(t, saved, slot)
+
+	err := saved.saveSnapshot()
+	if err != nil {
+		t.Fatalf("saveSnapshot() failed: %v", err)
+	}
+
+	scheduleAPI := newTestServer(t)
+	scheduleAPI.opts.Store = saved.opts.Store
+	scheduleAPI.opts.LedgerStore = saved.opts.LedgerStore
+	err = scheduleAPI.initializeSchedule()
+	if err != nil {
+		t.Fatalf("initializeSchedule() failed: %v", err)
+	}
+
+	res, err := scheduleAPI.RemoveVotedMovie(programmerCtx, &scheduler.RemoveVotedMovieRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v1", RefundVotes: true,
+	})
+	if err != nil {
+		t.Fatalf("RemoveVotedMovie() after reload failed: %v", err)
+	}
+
+	want := []*scheduler.VoteRefund{
+		{UserId: "u1", Votes: 2},
+		{UserId: "u2", Votes: 1},
+	}
+	if len(res.GetRefunds()) != len(want) {
+		t.Fatalf("refunds = %v, want %v", res.GetRefunds(), want)
+	}
+	for i, refund := range res.GetRefunds() {
+		if refund.GetUserId() != want[i].UserId || refund.GetVotes() != want[i].Votes {
+			t.Errorf("refund %d = %v, want %v", i, refund, want[i])
+		}
+	}
+	if res.GetDiscardedVotes() != 0 {
+		t.Errorf("discarded votes = %d, want 0", res.GetDiscardedVotes())
+	}
+}
+
+func TestRefundedVotesAreCastAgain(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	saved := newTestServer(t)
+	saved.opts.Store = NewMemoryStore()
+	saved.opts.LedgerStore = NewMemoryStore()
+	seedVotedShow(t, saved, slot)
+
+	_, err := saved.RemoveVotedMovie(userContext("p1", auth.RoleProgrammer), &scheduler.RemoveVotedMovieRequest{
+		WeekDay: slot.WeekDay, Show: slot.Show, Screen: slot.Screen, MovieId: "v1", RefundVotes: true,
+	})
+	if err != nil {
+		t.Fatalf("RemoveVotedMovie() failed: %v", err)
+	}
+	err = saved.saveSnapshot()
+	if err != nil {
+		t.Fatalf("saveSnapshot() failed: %v", err)
+	}
+
+	// The credits are saved with the ledger
+	scheduleAPI := newTestServer(t)
+	scheduleAPI.opts.Store = saved.opts.Store
+	scheduleAPI.opts.LedgerStore = saved.opts.LedgerStore
+	err = scheduleAPI.initializeSchedule()
+	if err != nil {
+		t.Fatalf("initializeSchedule() failed: %v", err)
+	}
+
+	vote := func(userID string) {
+		t.Helper()
+		_, err := scheduleAPI.VoteUpMovie(userContext(userID), &scheduler.VoteUpMovieRequest{
+			WeekDay: slot.WeekDay, ShowNumber: slot.Show, Screen: slot.Screen, MovieId: "v2",
+		})
+		if err != nil {
+			t.Fatalf("VoteUpMovie() failed: %v", err)
+		}
+	}
+
+	// u1 casts the two refunded votes with the next vote, and only once
+	vote("u1")
+	vote("u1")
+	showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+	if got := showSchedule.GetVotedMovies()[0].GetCurrentVotes(); got != 4 {
+		t.Errorf("votes of v2 = %d, want 4", got)
+	}
+	if got := scheduleAPI.ledger.votes[slot]["v2"]["u1"]; got != 4 {
+		t.Errorf("ledger votes of u1 for v2 = %d, want 4", got)
+	}
+	if got := scheduleAPI.ledger.credits[slot]; len(got) != 1 || got["u2"] != 1 {
+		t.Errorf("credits = %v, want only the credit of u2", got)
+	}
+}
+
+func TestReorderVotedMovies(t *testing.T) {
+	slot := snapshot.Slot{WeekDay: 1, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name      string
+		movieIDs  []string
+		wantCode  codes.Code
+		wantVoted []string
+	}{
+		{name: "reordered", movieIDs: []string{"v2", "v1"}, wantVoted: []string{"v2", "v1"}},
+		{name: "same order", movieIDs: []string{"v1", "v2"}, wantVoted: []string{"v1", "v2"}},
+		{name: "missing movie", movieIDs: []string{"v2"}, wantCode: codes.InvalidArgument, wantVoted: []string{"v1", "v2"}},
+		{
+			name: "movie listed twice", movieIDs: []string{"v2", "v2"},
+			wantCode: codes.InvalidArgument, wantVoted: []string{"v1", "v2"},
+		},
+		{
+			name: "movie that is not voted", movieIDs: []string{"v2", "m1"},
+			wantCode: codes.NotFound, wantVoted: []string{"v1", "v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleAPI := newTestServer(t)
+			seedVotedShow(t, scheduleAPI, slot)
+
+			_, err := scheduleAPI.ReorderVotedMovies(
+				userContext("p1", auth.RoleProgrammer),
+				&scheduler.ReorderVotedMoviesRequest{WeekDay: 1, Show: 1, Screen: "A", MovieIds: tt.movieIDs},
+			)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ReorderVotedMovies() error = %v, want code %s", err, tt.wantCode)
+			}
+			showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+			if got := movieIDs(showSchedule.GetVotedMovies()); !equalStrings(got, tt.wantVoted) {
+				t.Errorf("voted movies = %v, want %v", got, tt.wantVoted)
+			}
+		})
+	}
+}
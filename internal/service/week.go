@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/snapshot"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The Pseudocode:
+// 1. Validate the week days, screens and field mask from the request
+// 2. Lock the mutex
+// 3. Copy the shows in the requested days and screens, or the shows of the movie when given
+// 4. Unlock the mutex
+// 5. Clear the fields not selected by the field mask
+// 6. Return the shows; days and screens without shows are left out
+func (scheduleAPI *scheduleAPIServer) GetWeekSchedule(
+	ctx context.Context, getReq *scheduler.GetWeekScheduleRequest,
+) (*scheduler.DaysSchedule, error) {
+	fromWeekDay := getReq.GetFromWeekDay()
+	toWeekDay := getReq.GetToWeekDay()
+	if fromWeekDay == 0 {
+		fromWeekDay = weekDays[0]
+	}
+	if toWeekDay == 0 {
+		toWeekDay = weekDays[len(weekDays)-1]
+	}
+
+	// Validate the input
+	err := func() error {
+		var err error
+		switch {
+		case fromWeekDay < 1 || fromWeekDay > 7:
+			err = errIncorrectVal("From week day")
+		case toWeekDay < 1 || toWeekDay > 7:
+			err = errIncorrectVal("To week day")
+		case fromWeekDay > toWeekDay:
+			err = status.Errorf(
+				codes.InvalidArgument, "from week day %d is after to week day %d", fromWeekDay, toWeekDay,
+			)
+		}
+		return err
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only programmers may read the draft
+	err = draftAllowed(ctx, getReq.GetDraft())
+	if err != nil {
+		return nil, err
+	}
+
+	mask, err := newFieldMask(&scheduler.ShowSchedule{}, getReq.GetFieldMask().GetPaths())
+	if err != nil {
+		return nil, err
+	}
+
+	screens := make(map[string]bool, len(getReq.GetScreens()))
+	for _, screen := range getReq.GetScreens() {
+		screens[screen] = true
+	}
+
+	// lock the muSchedule mutex
+	scheduleAPI.lockSchedule(ctx)
+
+	// Read the draft instead of the published schedule if requested
+	restore := func() {}
+	if getReq.GetDraft() {
+		restore = scheduleAPI.readDraft()
+	}
+
+	for screen := range screens {
+		if !contains(scheduleAPI.opts.Screens, screen) {
+			restore()
+			scheduleAPI.muSchedule.Unlock()
+			return nil, errNoMovieScheduleForScreen(screen)
+		}
+	}
+
+	var slots []snapshot.Slot
+	if movieID := getReq.GetMovieId(); movieID != "" {
+		slots = scheduleAPI.index.lookup(movieID)
+	} else {
+		slots = snapshot.Slots(&scheduleAPI.weeklySchedule)
+	}
+
+	weekSchedule := &scheduler.DaysSchedule{
+		DaysSchedule: make(map[int32]*scheduler.ScreensSchedule),
+	}
+	for _, slot := range slots {
+		if slot.WeekDay < fromWeekDay || slot.WeekDay > toWeekDay {
+			continue
+		}
+		if len(screens) != 0 && !screens[slot.Screen] {
+			continue
+		}
+		showSchedule := snapshot.Lookup(&scheduleAPI.weeklySchedule, slot)
+		if showSchedule == nil {
+			continue
+		}
+		addShow(weekSchedule, slot, proto.Clone(showSchedule).(*scheduler.ShowSchedule))
+	}
+
+	restore()
+	// Unlock the mutex
+	scheduleAPI.muSchedule.Unlock()
+
+	for _, daySchedule := range weekSchedule.DaysSchedule {
+		for _, screenSchedule := range daySchedule.ScreensSchedule {
+			for _, showSchedule := range screenSchedule.ShowsSchedule {
+				mask.prune(showSchedule)
+			}
+		}
+	}
+
+	return weekSchedule, nil
+}
+
+// adds a show to the weekly schedule, creating its day and screen if necessary
+func addShow(weeklySchedule *scheduler.DaysSchedule, slot snapshot.Slot, showSchedule *scheduler.ShowSchedule) {
+	daySchedule, ok := weeklySchedule.DaysSchedule[slot.WeekDay]
+	if !ok {
+		daySchedule = &scheduler.ScreensSchedule{
+			ScreensSchedule: make(map[string]*scheduler.ShowsSchedule),
+		}
+		weeklySchedule.DaysSchedule[slot.WeekDay] = daySchedule
+	}
+	screenSchedule, ok := daySchedule.ScreensSchedule[slot.Screen]
+	if !ok {
+		screenSchedule = &scheduler.ShowsSchedule{
+			ShowsSchedule: make(map[int32]*scheduler.ShowSchedule),
+		}
+		daySchedule.ScreensSchedule[slot.Screen] = screenSchedule
+	}
+	screenSchedule.ShowsSchedule[slot.Show] = showSchedule
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldMask is the tree of fields selected by field mask paths.
+// A field selected without sub fields, a nil sub mask, keeps all of its sub fields.
+type fieldMask map[string]fieldMask
+
+// newFieldMask parses field mask paths, checking that every path names fields of msg.
+// No paths selects every field. A field selected entirely is kept entirely when paths
+// also select some of its sub fields.
+func newFieldMask(msg proto.Message, paths []string) (fieldMask, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	mask := make(fieldMask)
+	for _, path := range paths {
+		names := strings.Split(path, ".")
+		msgType := reflect.TypeOf(msg)
+		for _, name := range names {
+			if msgType == nil {
+				return nil, errInvalidFieldMask(path)
+			}
+			field, ok := protoField(msgType.Elem(), name)
+			if !ok {
+				return nil, errInvalidFieldMask(path)
+			}
+			msgType = messageType(field.Type)
+		}
+
+		node := mask
+		for i, name := range names {
+			subMask, selected := node[name]
+			if selected && subMask == nil {
+				break
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if subMask == nil {
+				subMask = make(fieldMask)
+				node[name] = subMask
+			}
+			node = subMask
+		}
+	}
+
+	return mask, nil
+}
+
+// prune clears the fields of msg that are not selected by the mask
+func (mask fieldMask) prune(msg proto.Message) {
+	if len(mask) == 0 {
+		return
+	}
+	mask.pruneValue(reflect.ValueOf(msg))
+}
+
+func (mask fieldMask) pruneValue(msgVal reflect.Value) {
+	if msgVal.IsNil() {
+		return
+	}
+	structVal := msgVal.Elem()
+	for i := 0; i < structVal.NumField(); i++ {
+		name, ok := protoName(structVal.Type().Field(i))
+		if !ok {
+			continue
+		}
+		fieldVal := structVal.Field(i)
+		subMask, selected := mask[name]
+		switch {
+		case !selected:
+			fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		case len(subMask) == 0:
+		case fieldVal.Kind() == reflect.Ptr:
+			subMask.pruneValue(fieldVal)
+		case fieldVal.Kind() == reflect.Slice:
+			for j := 0; j < fieldVal.Len(); j++ {
+				subMask.pruneValue(fieldVal.Index(j))
+			}
+		}
+	}
+}
+
+// returns the struct field of a generated message with the given proto name
+func protoField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		if fieldName, ok := protoName(structType.Field(i)); ok && fieldName == name {
+			return structType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// returns the proto name of a generated message field
+func protoName(field reflect.StructField) (string, bool) {
+	for _, opt := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(opt, "name=") {
+			return strings.TrimPrefix(opt, "name="), true
+		}
+	}
+	return "", false
+}
+
+// returns the message type of a field or of the elements of a repeated field, or nil if it is not a message
+func messageType(fieldType reflect.Type) reflect.Type {
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct {
+		return fieldType
+	}
+	return nil
+}
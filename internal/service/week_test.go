@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFieldMask(t *testing.T) {
+	newShow := func() *scheduler.ShowSchedule {
+		return &scheduler.ShowSchedule{
+			PlayTime: "10:00",
+			Movie:    &movie.Movie{Id: "m1", Title: "Movie m1", CurrentVotes: 4},
+			VotedMovies: []*movie.Movie{
+				{Id: "v1", Title: "Movie v1", CurrentVotes: 2},
+				{Id: "v2", Title: "Movie v2"},
+			},
+			Version:      3,
+			VotesVersion: 5,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		wantCode codes.Code
+		want     *scheduler.ShowSchedule
+	}{
+		{name: "no paths", want: newShow()},
+		{
+			name:  "scalar fields",
+			paths: []string{"play_time", "version"},
+			want:  &scheduler.ShowSchedule{PlayTime: "10:00", Version: 3},
+		},
+		{
+			name:  "whole message field",
+			paths: []string{"movie"},
+			want:  &scheduler.ShowSchedule{Movie: &movie.Movie{Id: "m1", Title: "Movie m1", CurrentVotes: 4}},
+		},
+		{
+			name:  "nested path",
+			paths: []string{"movie.title"},
+			want:  &scheduler.ShowSchedule{Movie: &movie.Movie{Title: "Movie m1"}},
+		},
+		{
+			name:  "nested paths of the same message",
+			paths: []string{"movie.id", "movie.current_votes"},
+			want:  &scheduler.ShowSchedule{Movie: &movie.Movie{Id: "m1", CurrentVotes: 4}},
+		},
+		{
+			name:  "repeated message field",
+			paths: []string{"voted_movies.id"},
+			want: &scheduler.ShowSchedule{
+				VotedMovies: []*movie.Movie{{Id: "v1"}, {Id: "v2"}},
+			},
+		},
+		{
+			name:  "message field and one of its fields",
+			paths: []string{"movie.id", "movie", "movie.title"},
+			want:  &scheduler.ShowSchedule{Movie: &movie.Movie{Id: "m1", Title: "Movie m1", CurrentVotes: 4}},
+		},
+		{name: "unknown field", paths: []string{"screen"}, wantCode: codes.InvalidArgument},
+		{name: "unknown nested field", paths: []string{"movie.unknown_field"}, wantCode: codes.InvalidArgument},
+		{name: "sub field of a scalar", paths: []string{"play_time.hour"}, wantCode: codes.InvalidArgument},
+		{name: "json name", paths: []string{"playTime"}, wantCode: codes.InvalidArgument},
+		{name: "empty path", paths: []string{""}, wantCode: codes.InvalidArgument},
+		{name: "empty field name", paths: []string{"movie..id"}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := newFieldMask(&scheduler.ShowSchedule{}, tt.paths)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("newFieldMask(%q) error = %v, want code %s", tt.paths, err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			got := newShow()
+			mask.prune(got)
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("pruned show = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,7 +10,9 @@ import (
 	empty "github.com/golang/protobuf/ptypes/empty"
 	_ "github.com/grpc-ecosystem/grpc-gateway/protoc-gen-swagger/options"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	field_mask "google.golang.org/genproto/protobuf/field_mask"
 	grpc "google.golang.org/grpc"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	math "math"
 	proto1 "movie/api/proto"
 )
@@ -26,11 +28,69 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
-// Show for a particular time of day
+// Layout of an imported or exported schedule
+type ScheduleFormat int32
+
+const (
+	// Header row followed by week_day,screen,show,movie_id,voted_movie_ids rows.
+	// Voted movie ids are separated by semicolons
+	ScheduleFormat_CSV ScheduleFormat = 0
+	// Array of ScheduleRow objects
+	ScheduleFormat_JSON ScheduleFormat = 1
+)
+
+var ScheduleFormat_name = map[int32]string{
+	0: "CSV",
+	1: "JSON",
+}
+
+var ScheduleFormat_value = map[string]int32{
+	"CSV":  0,
+	"JSON": 1,
+}
+
+func (x ScheduleFormat) String() string {
+	return proto.EnumName(ScheduleFormat_name, int32(x))
+}
+
+func (ScheduleFormat) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{0}
+}
+
+// Whether a movie is the one showing or one of the voted movies of a show
+type MovieShowRole int32
+
+const (
+	MovieShowRole_SHOWING   MovieShowRole = 0
+	MovieShowRole_NOMINATED MovieShowRole = 1
+)
+
+var MovieShowRole_name = map[int32]string{
+	0: "SHOWING",
+	1: "NOMINATED",
+}
+
+var MovieShowRole_value = map[string]int32{
+	"SHOWING":   0,
+	"NOMINATED": 1,
+}
+
+func (x MovieShowRole) String() string {
+	return proto.EnumName(MovieShowRole_name, int32(x))
+}
+
+func (MovieShowRole) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{1}
+}
+
+// Show for a particular time of day.
+// Version increases every time the programme of the show changes; votes increase votes_version instead
 type ShowSchedule struct {
 	PlayTime             string          `protobuf:"bytes,1,opt,name=play_time,json=playTime,proto3" json:"play_time,omitempty"`
 	Movie                *proto1.Movie   `protobuf:"bytes,2,opt,name=movie,proto3" json:"movie,omitempty"`
 	VotedMovies          []*proto1.Movie `protobuf:"bytes,3,rep,name=voted_movies,json=votedMovies,proto3" json:"voted_movies,omitempty"`
+	Version              int64           `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	VotesVersion         int64           `protobuf:"varint,5,opt,name=votes_version,json=votesVersion,proto3" json:"votes_version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -82,6 +142,20 @@ func (m *ShowSchedule) GetVotedMovies() []*proto1.Movie {
 	return nil
 }
 
+func (m *ShowSchedule) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ShowSchedule) GetVotesVersion() int64 {
+	if m != nil {
+		return m.VotesVersion
+	}
+	return 0
+}
+
 // Shows in a day. E.g 1, 2, 3, 4
 type ShowsSchedule struct {
 	ShowsSchedule        map[int32]*ShowSchedule `protobuf:"bytes,1,rep,name=shows_schedule,json=showsSchedule,proto3" json:"shows_schedule,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -122,9 +196,12 @@ func (m *ShowsSchedule) GetShowsSchedule() map[int32]*ShowSchedule {
 	return nil
 }
 
-// Shows playing in a day for a particular screen
+// Shows playing in a day for a particular screen.
+// Version increases every time the programme of a show in the day changes; votes increase votes_version instead
 type ScreensSchedule struct {
 	ScreensSchedule      map[string]*ShowsSchedule `protobuf:"bytes,1,rep,name=screens_schedule,json=screensSchedule,proto3" json:"screens_schedule,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Version              int64                     `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	VotesVersion         int64                     `protobuf:"varint,3,opt,name=votes_version,json=votesVersion,proto3" json:"votes_version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
 	XXX_unrecognized     []byte                    `json:"-"`
 	XXX_sizecache        int32                     `json:"-"`
@@ -162,6 +239,20 @@ func (m *ScreensSchedule) GetScreensSchedule() map[string]*ShowsSchedule {
 	return nil
 }
 
+func (m *ScreensSchedule) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ScreensSchedule) GetVotesVersion() int64 {
+	if m != nil {
+		return m.VotesVersion
+	}
+	return 0
+}
+
 // Shows schedule for a particular day of the week
 type DaysSchedule struct {
 	DaysSchedule         map[int32]*ScreensSchedule `protobuf:"bytes,1,rep,name=days_schedule,json=daysSchedule,proto3" json:"days_schedule,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -204,7 +295,8 @@ func (m *DaysSchedule) GetDaysSchedule() map[int32]*ScreensSchedule {
 
 // Request to vote up a movie
 type VoteUpMovieRequest struct {
-	MovieId              string   `protobuf:"bytes,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	MovieId string `protobuf:"bytes,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	// Optional. Votes are cast by the authenticated caller; a different user is rejected
 	UserId               string   `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Screen               string   `protobuf:"bytes,5,opt,name=screen,proto3" json:"screen,omitempty"`
 	ShowTime             string   `protobuf:"bytes,6,opt,name=show_time,json=showTime,proto3" json:"show_time,omitempty"`
@@ -285,6 +377,7 @@ func (m *VoteUpMovieRequest) GetShowNumber() int32 {
 // Request to get schedule
 type GetDayScheduleRequest struct {
 	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Draft                bool     `protobuf:"varint,2,opt,name=draft,proto3" json:"draft,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -322,11 +415,101 @@ func (m *GetDayScheduleRequest) GetWeekDay() int32 {
 	return 0
 }
 
+func (m *GetDayScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to get the schedule for a range of week days.
+// Every filter is optional; week days default to 1 through 7.
+// Field mask paths are relative to ShowSchedule e.g play_time, movie.id, voted_movies.title
+type GetWeekScheduleRequest struct {
+	FromWeekDay          int32                 `protobuf:"varint,1,opt,name=from_week_day,json=fromWeekDay,proto3" json:"from_week_day,omitempty"`
+	ToWeekDay            int32                 `protobuf:"varint,2,opt,name=to_week_day,json=toWeekDay,proto3" json:"to_week_day,omitempty"`
+	Screens              []string              `protobuf:"bytes,3,rep,name=screens,proto3" json:"screens,omitempty"`
+	MovieId              string                `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	FieldMask            *field_mask.FieldMask `protobuf:"bytes,5,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	Draft                bool                  `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *GetWeekScheduleRequest) Reset()         { *m = GetWeekScheduleRequest{} }
+func (m *GetWeekScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*GetWeekScheduleRequest) ProtoMessage()    {}
+func (*GetWeekScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{6}
+}
+
+func (m *GetWeekScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetWeekScheduleRequest.Unmarshal(m, b)
+}
+func (m *GetWeekScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetWeekScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *GetWeekScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetWeekScheduleRequest.Merge(m, src)
+}
+func (m *GetWeekScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_GetWeekScheduleRequest.Size(m)
+}
+func (m *GetWeekScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetWeekScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetWeekScheduleRequest proto.InternalMessageInfo
+
+func (m *GetWeekScheduleRequest) GetFromWeekDay() int32 {
+	if m != nil {
+		return m.FromWeekDay
+	}
+	return 0
+}
+
+func (m *GetWeekScheduleRequest) GetToWeekDay() int32 {
+	if m != nil {
+		return m.ToWeekDay
+	}
+	return 0
+}
+
+func (m *GetWeekScheduleRequest) GetScreens() []string {
+	if m != nil {
+		return m.Screens
+	}
+	return nil
+}
+
+func (m *GetWeekScheduleRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *GetWeekScheduleRequest) GetFieldMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.FieldMask
+	}
+	return nil
+}
+
+func (m *GetWeekScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
 // Request to get show
 type GetShowScheduleRequest struct {
 	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
 	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
 	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	Draft                bool     `protobuf:"varint,4,opt,name=draft,proto3" json:"draft,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -336,7 +519,7 @@ func (m *GetShowScheduleRequest) Reset()         { *m = GetShowScheduleRequest{}
 func (m *GetShowScheduleRequest) String() string { return proto.CompactTextString(m) }
 func (*GetShowScheduleRequest) ProtoMessage()    {}
 func (*GetShowScheduleRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d00842e68e05382a, []int{6}
+	return fileDescriptor_d00842e68e05382a, []int{7}
 }
 
 func (m *GetShowScheduleRequest) XXX_Unmarshal(b []byte) error {
@@ -378,12 +561,21 @@ func (m *GetShowScheduleRequest) GetScreen() string {
 	return ""
 }
 
+func (m *GetShowScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
 // Request to add a movie to the voted movie section
 type AddVotedMovieRequest struct {
 	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
 	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
 	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
 	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -393,7 +585,7 @@ func (m *AddVotedMovieRequest) Reset()         { *m = AddVotedMovieRequest{} }
 func (m *AddVotedMovieRequest) String() string { return proto.CompactTextString(m) }
 func (*AddVotedMovieRequest) ProtoMessage()    {}
 func (*AddVotedMovieRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d00842e68e05382a, []int{7}
+	return fileDescriptor_d00842e68e05382a, []int{8}
 }
 
 func (m *AddVotedMovieRequest) XXX_Unmarshal(b []byte) error {
@@ -442,215 +634,2539 @@ func (m *AddVotedMovieRequest) GetMovieId() string {
 	return ""
 }
 
-// Request to create a new show schedule for a day
-type CreateMovieDayScheduleRequest struct {
+func (m *AddVotedMovieRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *AddVotedMovieRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to remove a movie from the voted movie section.
+// Votes cast for the movie are refunded to the users that cast them when refund_votes is set, otherwise they are discarded.
+// Refunded votes are cast again with the user's next vote in the show.
+// Votes with unknown voters, such as votes restored without the vote ledger, are always discarded
+type RemoveVotedMovieRequest struct {
 	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
 	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
 	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
 	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	RefundVotes          bool     `protobuf:"varint,5,opt,name=refund_votes,json=refundVotes,proto3" json:"refund_votes,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,6,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,7,opt,name=draft,proto3" json:"draft,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CreateMovieDayScheduleRequest) Reset()         { *m = CreateMovieDayScheduleRequest{} }
-func (m *CreateMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
-func (*CreateMovieDayScheduleRequest) ProtoMessage()    {}
-func (*CreateMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d00842e68e05382a, []int{8}
+func (m *RemoveVotedMovieRequest) Reset()         { *m = RemoveVotedMovieRequest{} }
+func (m *RemoveVotedMovieRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveVotedMovieRequest) ProtoMessage()    {}
+func (*RemoveVotedMovieRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{9}
 }
 
-func (m *CreateMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CreateMovieDayScheduleRequest.Unmarshal(m, b)
+func (m *RemoveVotedMovieRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveVotedMovieRequest.Unmarshal(m, b)
 }
-func (m *CreateMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CreateMovieDayScheduleRequest.Marshal(b, m, deterministic)
+func (m *RemoveVotedMovieRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveVotedMovieRequest.Marshal(b, m, deterministic)
 }
-func (m *CreateMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CreateMovieDayScheduleRequest.Merge(m, src)
+func (m *RemoveVotedMovieRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveVotedMovieRequest.Merge(m, src)
 }
-func (m *CreateMovieDayScheduleRequest) XXX_Size() int {
-	return xxx_messageInfo_CreateMovieDayScheduleRequest.Size(m)
+func (m *RemoveVotedMovieRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveVotedMovieRequest.Size(m)
 }
-func (m *CreateMovieDayScheduleRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_CreateMovieDayScheduleRequest.DiscardUnknown(m)
+func (m *RemoveVotedMovieRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveVotedMovieRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CreateMovieDayScheduleRequest proto.InternalMessageInfo
+var xxx_messageInfo_RemoveVotedMovieRequest proto.InternalMessageInfo
 
-func (m *CreateMovieDayScheduleRequest) GetWeekDay() int32 {
+func (m *RemoveVotedMovieRequest) GetWeekDay() int32 {
 	if m != nil {
 		return m.WeekDay
 	}
 	return 0
 }
 
-func (m *CreateMovieDayScheduleRequest) GetShow() int32 {
+func (m *RemoveVotedMovieRequest) GetShow() int32 {
 	if m != nil {
 		return m.Show
 	}
 	return 0
 }
 
-func (m *CreateMovieDayScheduleRequest) GetScreen() string {
+func (m *RemoveVotedMovieRequest) GetScreen() string {
 	if m != nil {
 		return m.Screen
 	}
 	return ""
 }
 
-func (m *CreateMovieDayScheduleRequest) GetMovieId() string {
+func (m *RemoveVotedMovieRequest) GetMovieId() string {
 	if m != nil {
 		return m.MovieId
 	}
 	return ""
 }
 
-// Request to delete a show schedule for a day
-type DeleteMovieDayScheduleRequest struct {
+func (m *RemoveVotedMovieRequest) GetRefundVotes() bool {
+	if m != nil {
+		return m.RefundVotes
+	}
+	return false
+}
+
+func (m *RemoveVotedMovieRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *RemoveVotedMovieRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Votes cast by a user for a removed movie, credited back to the user in the show
+type VoteRefund struct {
+	UserId               string   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Votes                int32    `protobuf:"varint,2,opt,name=votes,proto3" json:"votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VoteRefund) Reset()         { *m = VoteRefund{} }
+func (m *VoteRefund) String() string { return proto.CompactTextString(m) }
+func (*VoteRefund) ProtoMessage()    {}
+func (*VoteRefund) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{10}
+}
+
+func (m *VoteRefund) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoteRefund.Unmarshal(m, b)
+}
+func (m *VoteRefund) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoteRefund.Marshal(b, m, deterministic)
+}
+func (m *VoteRefund) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoteRefund.Merge(m, src)
+}
+func (m *VoteRefund) XXX_Size() int {
+	return xxx_messageInfo_VoteRefund.Size(m)
+}
+func (m *VoteRefund) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoteRefund.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoteRefund proto.InternalMessageInfo
+
+func (m *VoteRefund) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *VoteRefund) GetVotes() int32 {
+	if m != nil {
+		return m.Votes
+	}
+	return 0
+}
+
+// Response after removing a voted movie
+type RemoveVotedMovieResponse struct {
+	Refunds              []*VoteRefund `protobuf:"bytes,1,rep,name=refunds,proto3" json:"refunds,omitempty"`
+	DiscardedVotes       int32         `protobuf:"varint,2,opt,name=discarded_votes,json=discardedVotes,proto3" json:"discarded_votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *RemoveVotedMovieResponse) Reset()         { *m = RemoveVotedMovieResponse{} }
+func (m *RemoveVotedMovieResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveVotedMovieResponse) ProtoMessage()    {}
+func (*RemoveVotedMovieResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{11}
+}
+
+func (m *RemoveVotedMovieResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveVotedMovieResponse.Unmarshal(m, b)
+}
+func (m *RemoveVotedMovieResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveVotedMovieResponse.Marshal(b, m, deterministic)
+}
+func (m *RemoveVotedMovieResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveVotedMovieResponse.Merge(m, src)
+}
+func (m *RemoveVotedMovieResponse) XXX_Size() int {
+	return xxx_messageInfo_RemoveVotedMovieResponse.Size(m)
+}
+func (m *RemoveVotedMovieResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveVotedMovieResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveVotedMovieResponse proto.InternalMessageInfo
+
+func (m *RemoveVotedMovieResponse) GetRefunds() []*VoteRefund {
+	if m != nil {
+		return m.Refunds
+	}
+	return nil
+}
+
+func (m *RemoveVotedMovieResponse) GetDiscardedVotes() int32 {
+	if m != nil {
+		return m.DiscardedVotes
+	}
+	return 0
+}
+
+// Request to reorder the voted movie section. movie_ids must contain every voted movie of the show once
+type ReorderVotedMoviesRequest struct {
 	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
 	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
 	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
-	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	MovieIds             []string `protobuf:"bytes,4,rep,name=movie_ids,json=movieIds,proto3" json:"movie_ids,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DeleteMovieDayScheduleRequest) Reset()         { *m = DeleteMovieDayScheduleRequest{} }
-func (m *DeleteMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
-func (*DeleteMovieDayScheduleRequest) ProtoMessage()    {}
-func (*DeleteMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d00842e68e05382a, []int{9}
+func (m *ReorderVotedMoviesRequest) Reset()         { *m = ReorderVotedMoviesRequest{} }
+func (m *ReorderVotedMoviesRequest) String() string { return proto.CompactTextString(m) }
+func (*ReorderVotedMoviesRequest) ProtoMessage()    {}
+func (*ReorderVotedMoviesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{12}
 }
 
-func (m *DeleteMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Unmarshal(m, b)
+func (m *ReorderVotedMoviesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReorderVotedMoviesRequest.Unmarshal(m, b)
 }
-func (m *DeleteMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Marshal(b, m, deterministic)
+func (m *ReorderVotedMoviesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReorderVotedMoviesRequest.Marshal(b, m, deterministic)
 }
-func (m *DeleteMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteMovieDayScheduleRequest.Merge(m, src)
+func (m *ReorderVotedMoviesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReorderVotedMoviesRequest.Merge(m, src)
 }
-func (m *DeleteMovieDayScheduleRequest) XXX_Size() int {
-	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Size(m)
+func (m *ReorderVotedMoviesRequest) XXX_Size() int {
+	return xxx_messageInfo_ReorderVotedMoviesRequest.Size(m)
 }
-func (m *DeleteMovieDayScheduleRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteMovieDayScheduleRequest.DiscardUnknown(m)
+func (m *ReorderVotedMoviesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReorderVotedMoviesRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteMovieDayScheduleRequest proto.InternalMessageInfo
+var xxx_messageInfo_ReorderVotedMoviesRequest proto.InternalMessageInfo
 
-func (m *DeleteMovieDayScheduleRequest) GetWeekDay() int32 {
+func (m *ReorderVotedMoviesRequest) GetWeekDay() int32 {
 	if m != nil {
 		return m.WeekDay
 	}
 	return 0
 }
 
-func (m *DeleteMovieDayScheduleRequest) GetShow() int32 {
+func (m *ReorderVotedMoviesRequest) GetShow() int32 {
 	if m != nil {
 		return m.Show
 	}
 	return 0
 }
 
-func (m *DeleteMovieDayScheduleRequest) GetScreen() string {
+func (m *ReorderVotedMoviesRequest) GetScreen() string {
 	if m != nil {
 		return m.Screen
 	}
 	return ""
 }
 
-func (m *DeleteMovieDayScheduleRequest) GetMovieId() string {
+func (m *ReorderVotedMoviesRequest) GetMovieIds() []string {
 	if m != nil {
-		return m.MovieId
+		return m.MovieIds
 	}
-	return ""
+	return nil
 }
 
-func init() {
-	proto.RegisterType((*ShowSchedule)(nil), "rupacinema.movie.ShowSchedule")
-	proto.RegisterType((*ShowsSchedule)(nil), "rupacinema.movie.ShowsSchedule")
-	proto.RegisterMapType((map[int32]*ShowSchedule)(nil), "rupacinema.movie.ShowsSchedule.ShowsScheduleEntry")
-	proto.RegisterType((*ScreensSchedule)(nil), "rupacinema.movie.ScreensSchedule")
-	proto.RegisterMapType((map[string]*ShowsSchedule)(nil), "rupacinema.movie.ScreensSchedule.ScreensScheduleEntry")
-	proto.RegisterType((*DaysSchedule)(nil), "rupacinema.movie.DaysSchedule")
-	proto.RegisterMapType((map[int32]*ScreensSchedule)(nil), "rupacinema.movie.DaysSchedule.DaysScheduleEntry")
-	proto.RegisterType((*VoteUpMovieRequest)(nil), "rupacinema.movie.VoteUpMovieRequest")
-	proto.RegisterType((*GetDayScheduleRequest)(nil), "rupacinema.movie.GetDayScheduleRequest")
-	proto.RegisterType((*GetShowScheduleRequest)(nil), "rupacinema.movie.GetShowScheduleRequest")
-	proto.RegisterType((*AddVotedMovieRequest)(nil), "rupacinema.movie.AddVotedMovieRequest")
-	proto.RegisterType((*CreateMovieDayScheduleRequest)(nil), "rupacinema.movie.CreateMovieDayScheduleRequest")
-	proto.RegisterType((*DeleteMovieDayScheduleRequest)(nil), "rupacinema.movie.DeleteMovieDayScheduleRequest")
+func (m *ReorderVotedMoviesRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
 }
 
-func init() { proto.RegisterFile("schedule.proto", fileDescriptor_d00842e68e05382a) }
-
-var fileDescriptor_d00842e68e05382a = []byte{
-	// 791 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x55, 0x4f, 0x4f, 0xdb, 0x48,
-	0x14, 0xd7, 0x24, 0x24, 0x90, 0x97, 0x04, 0xd8, 0x59, 0x08, 0xc6, 0x61, 0x81, 0x1d, 0xa1, 0x5d,
-	0x84, 0x96, 0x78, 0x95, 0xfd, 0x2b, 0x6e, 0x6d, 0x83, 0x10, 0x87, 0xf6, 0x10, 0x0a, 0x52, 0x7b,
-	0x49, 0x27, 0xf1, 0x34, 0x44, 0x24, 0x76, 0x6a, 0x3b, 0x80, 0x55, 0xf5, 0x50, 0xd4, 0x4a, 0xbd,
-	0x57, 0xea, 0x77, 0xe9, 0x47, 0xa8, 0xd4, 0x5b, 0x4f, 0xbd, 0xf7, 0x83, 0x54, 0xf3, 0x26, 0x0e,
-	0x76, 0xec, 0x10, 0x21, 0x21, 0xf5, 0x92, 0xf8, 0xbd, 0x79, 0x7f, 0x7e, 0xbf, 0x37, 0xef, 0xbd,
-	0x81, 0x79, 0xb7, 0x75, 0x2a, 0xcc, 0x41, 0x57, 0x54, 0xfa, 0x8e, 0xed, 0xd9, 0x74, 0xd1, 0x19,
-	0xf4, 0x79, 0xab, 0x63, 0x89, 0x1e, 0xaf, 0xf4, 0xec, 0xf3, 0x8e, 0xd0, 0xcb, 0x6d, 0xdb, 0x6e,
-	0x77, 0x85, 0x81, 0xe7, 0xcd, 0xc1, 0x73, 0x43, 0xf4, 0xfa, 0x9e, 0xaf, 0xcc, 0xf5, 0xb5, 0xe1,
-	0x21, 0xef, 0x77, 0x0c, 0x6e, 0x59, 0xb6, 0xc7, 0xbd, 0x8e, 0x6d, 0xb9, 0xc3, 0xd3, 0x3f, 0xf0,
-	0xaf, 0xb5, 0xdb, 0x16, 0xd6, 0xae, 0x7b, 0xc1, 0xdb, 0x6d, 0xe1, 0x18, 0x76, 0x1f, 0x2d, 0x12,
-	0xac, 0xcb, 0x98, 0x0f, 0x43, 0xa1, 0xc2, 0x40, 0x59, 0x1d, 0xb2, 0x0f, 0x04, 0x0a, 0x47, 0xa7,
-	0xf6, 0xc5, 0xd1, 0x10, 0x2e, 0x2d, 0x43, 0xae, 0xdf, 0xe5, 0x7e, 0xc3, 0xeb, 0xf4, 0x84, 0x46,
-	0x36, 0xc9, 0x76, 0xae, 0x3e, 0x27, 0x15, 0x8f, 0x3b, 0x3d, 0x41, 0x77, 0x21, 0x83, 0xce, 0x5a,
-	0x6a, 0x93, 0x6c, 0xe7, 0xab, 0x2b, 0x95, 0x71, 0x56, 0x95, 0x87, 0xf2, 0xb7, 0xae, 0xac, 0xe8,
-	0x1e, 0x14, 0xce, 0x6d, 0x4f, 0x98, 0x0d, 0x14, 0x5d, 0x2d, 0xbd, 0x99, 0xbe, 0xc9, 0x2b, 0x8f,
-	0xc6, 0xf8, 0xed, 0xb2, 0xcf, 0x04, 0x8a, 0x12, 0x98, 0x3b, 0x42, 0xf6, 0x04, 0xe6, 0x5d, 0xa9,
-	0x68, 0x04, 0xa5, 0xd5, 0x08, 0xc6, 0xab, 0xc6, 0xe3, 0x45, 0x1c, 0xa3, 0xd2, 0xbe, 0xe5, 0x39,
-	0x7e, 0xbd, 0xe8, 0x86, 0x75, 0xfa, 0x33, 0xa0, 0x71, 0x23, 0xba, 0x08, 0xe9, 0x33, 0xe1, 0x63,
-	0x11, 0x32, 0x75, 0xf9, 0x49, 0xff, 0x86, 0xcc, 0x39, 0xef, 0x0e, 0x02, 0xfe, 0xeb, 0xc9, 0x99,
-	0x83, 0x28, 0x75, 0x65, 0xbc, 0x97, 0xfa, 0x9f, 0xb0, 0xaf, 0x04, 0x16, 0x8e, 0x5a, 0x8e, 0x10,
-	0xd6, 0x35, 0x21, 0x0e, 0x8b, 0xae, 0x52, 0x8d, 0x53, 0xfa, 0x37, 0x21, 0x70, 0xd4, 0x79, 0x5c,
-	0x56, 0xb4, 0x16, 0xdc, 0xa8, 0x56, 0x6f, 0xc1, 0x52, 0x92, 0x61, 0x98, 0x5a, 0x4e, 0x51, 0xfb,
-	0x27, 0x4a, 0x6d, 0x63, 0x4a, 0x51, 0xc3, 0xdc, 0x3e, 0x11, 0x28, 0xd4, 0xb8, 0x7f, 0x4d, 0xec,
-	0x18, 0x8a, 0x26, 0xf7, 0x63, 0xac, 0xfe, 0x8c, 0xc7, 0x0c, 0xbb, 0x45, 0x04, 0xc5, 0xa7, 0x60,
-	0x86, 0x54, 0x7a, 0x13, 0x7e, 0x8a, 0x99, 0x24, 0x5c, 0xd2, 0x7f, 0x51, 0x26, 0xbf, 0x4e, 0xad,
-	0x65, 0x98, 0xcb, 0x47, 0x02, 0xf4, 0xc4, 0xf6, 0xc4, 0x71, 0x5f, 0xf5, 0xa4, 0x78, 0x31, 0x10,
-	0xae, 0x47, 0x57, 0x61, 0x0e, 0x5d, 0x1b, 0x1d, 0x73, 0x58, 0xb4, 0x59, 0x94, 0x0f, 0x4d, 0xba,
-	0x02, 0xb3, 0x03, 0x57, 0x38, 0xf2, 0x24, 0x85, 0x27, 0x59, 0x29, 0x1e, 0x9a, 0xb4, 0x04, 0x59,
-	0x75, 0x1d, 0x5a, 0x46, 0xe9, 0x95, 0x24, 0x27, 0x4c, 0x76, 0x9f, 0x9a, 0xb0, 0xac, 0x9a, 0x30,
-	0xa9, 0xc0, 0x09, 0x5b, 0x85, 0xb9, 0x0b, 0x21, 0xce, 0x1a, 0x26, 0xf7, 0xb5, 0x34, 0x72, 0x9a,
-	0x95, 0x72, 0x8d, 0xfb, 0x74, 0x03, 0xf2, 0xe8, 0x67, 0x0d, 0x7a, 0x4d, 0xe1, 0x68, 0x33, 0x78,
-	0x0a, 0x52, 0xf5, 0x08, 0x35, 0xac, 0x0a, 0xcb, 0x07, 0xc2, 0xab, 0x71, 0x7f, 0x44, 0xec, 0x1a,
-	0xfd, 0x28, 0x28, 0x89, 0x04, 0x65, 0x0d, 0x28, 0x1d, 0x08, 0x2f, 0xd2, 0xb5, 0x53, 0x9d, 0x28,
-	0x85, 0x19, 0x99, 0x16, 0xf9, 0x66, 0xea, 0xf8, 0x1d, 0x62, 0x9b, 0x0e, 0xb3, 0x65, 0x97, 0xb0,
-	0x74, 0xcf, 0x34, 0x4f, 0x46, 0x93, 0x7d, 0xb7, 0xe1, 0x23, 0x17, 0x33, 0x13, 0xb9, 0x18, 0xf6,
-	0x9a, 0xc0, 0x2f, 0x0f, 0x1c, 0xc1, 0x3d, 0x81, 0x89, 0x6f, 0x55, 0x97, 0xbb, 0xc4, 0x50, 0x13,
-	0x5d, 0xf1, 0x03, 0x31, 0x54, 0xdf, 0x64, 0xd5, 0x26, 0x0d, 0x32, 0x3b, 0xb4, 0x0b, 0xf9, 0x50,
-	0x8f, 0xd3, 0xad, 0xf8, 0x84, 0xc4, 0x47, 0x40, 0x9f, 0xb4, 0xb6, 0xd9, 0xfa, 0xd5, 0x97, 0x6f,
-	0xef, 0x53, 0x1a, 0xfb, 0x19, 0x9f, 0x98, 0x60, 0xe6, 0x1d, 0x43, 0x6e, 0xf3, 0x3d, 0xb2, 0x43,
-	0x5d, 0x28, 0x46, 0x3a, 0x80, 0xfe, 0x16, 0x8f, 0x94, 0xd4, 0x22, 0x7a, 0xa9, 0xa2, 0x5e, 0xc1,
-	0x4a, 0xf0, 0x44, 0x56, 0xf6, 0xe5, 0x13, 0xc9, 0x18, 0x26, 0x5c, 0x63, 0x2b, 0x49, 0x09, 0xb9,
-	0x69, 0xca, 0xa4, 0x6f, 0x09, 0x94, 0x92, 0x2f, 0x9f, 0x1a, 0xf1, 0xf4, 0x37, 0xb6, 0xc9, 0xad,
-	0x71, 0x04, 0x5f, 0x12, 0xc7, 0x15, 0x81, 0x52, 0x72, 0x03, 0x24, 0xe1, 0xb8, 0xb1, 0x55, 0x26,
-	0xe2, 0xd8, 0x40, 0x1c, 0xab, 0x3b, 0x93, 0x70, 0xd0, 0x77, 0x04, 0xe6, 0xa3, 0x9b, 0x81, 0xfe,
-	0x1e, 0x4f, 0x9e, 0xb8, 0x3b, 0xf4, 0xe9, 0xeb, 0x93, 0xed, 0x60, 0xfe, 0x2d, 0xca, 0x26, 0xe4,
-	0x37, 0x5e, 0x06, 0x1d, 0xfe, 0x8a, 0x5e, 0xc2, 0xc2, 0xd8, 0xbe, 0xa1, 0xdb, 0x89, 0x50, 0x12,
-	0x56, 0x92, 0x3e, 0xe5, 0xbd, 0x65, 0x65, 0x04, 0xb2, 0x4c, 0xc7, 0x3b, 0x51, 0x4e, 0xce, 0xfd,
-	0xfc, 0xd3, 0xdc, 0x48, 0xd3, 0xcc, 0x62, 0x09, 0xff, 0xfa, 0x1e, 0x00, 0x00, 0xff, 0xff, 0xa1,
-	0x1a, 0x64, 0x42, 0xa7, 0x09, 0x00, 0x00,
+func (m *ReorderVotedMoviesRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+// Request to create a new show schedule for a day
+type CreateMovieDayScheduleRequest struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
+	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *CreateMovieDayScheduleRequest) Reset()         { *m = CreateMovieDayScheduleRequest{} }
+func (m *CreateMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateMovieDayScheduleRequest) ProtoMessage()    {}
+func (*CreateMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{13}
+}
 
-// ShowSchedulerClient is the client API for ShowScheduler service.
+func (m *CreateMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateMovieDayScheduleRequest.Unmarshal(m, b)
+}
+func (m *CreateMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateMovieDayScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateMovieDayScheduleRequest.Merge(m, src)
+}
+func (m *CreateMovieDayScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateMovieDayScheduleRequest.Size(m)
+}
+func (m *CreateMovieDayScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateMovieDayScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateMovieDayScheduleRequest proto.InternalMessageInfo
+
+func (m *CreateMovieDayScheduleRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *CreateMovieDayScheduleRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *CreateMovieDayScheduleRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *CreateMovieDayScheduleRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *CreateMovieDayScheduleRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *CreateMovieDayScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to delete a show schedule for a day
+type DeleteMovieDayScheduleRequest struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
+	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteMovieDayScheduleRequest) Reset()         { *m = DeleteMovieDayScheduleRequest{} }
+func (m *DeleteMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteMovieDayScheduleRequest) ProtoMessage()    {}
+func (*DeleteMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{14}
+}
+
+func (m *DeleteMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Unmarshal(m, b)
+}
+func (m *DeleteMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteMovieDayScheduleRequest.Merge(m, src)
+}
+func (m *DeleteMovieDayScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteMovieDayScheduleRequest.Size(m)
+}
+func (m *DeleteMovieDayScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteMovieDayScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteMovieDayScheduleRequest proto.InternalMessageInfo
+
+func (m *DeleteMovieDayScheduleRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *DeleteMovieDayScheduleRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *DeleteMovieDayScheduleRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *DeleteMovieDayScheduleRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *DeleteMovieDayScheduleRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *DeleteMovieDayScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to replace the movie showing in a show
+type UpdateMovieDayScheduleRequest struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
+	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,6,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateMovieDayScheduleRequest) Reset()         { *m = UpdateMovieDayScheduleRequest{} }
+func (m *UpdateMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateMovieDayScheduleRequest) ProtoMessage()    {}
+func (*UpdateMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{15}
+}
+
+func (m *UpdateMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateMovieDayScheduleRequest.Unmarshal(m, b)
+}
+func (m *UpdateMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateMovieDayScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateMovieDayScheduleRequest.Merge(m, src)
+}
+func (m *UpdateMovieDayScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateMovieDayScheduleRequest.Size(m)
+}
+func (m *UpdateMovieDayScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateMovieDayScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateMovieDayScheduleRequest proto.InternalMessageInfo
+
+func (m *UpdateMovieDayScheduleRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *UpdateMovieDayScheduleRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *UpdateMovieDayScheduleRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *UpdateMovieDayScheduleRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *UpdateMovieDayScheduleRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *UpdateMovieDayScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to move a show, with its voted movies, to another day, screen or show
+type MoveMovieDayScheduleRequest struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
+	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	ToWeekDay            int32    `protobuf:"varint,5,opt,name=to_week_day,json=toWeekDay,proto3" json:"to_week_day,omitempty"`
+	ToShow               int32    `protobuf:"varint,6,opt,name=to_show,json=toShow,proto3" json:"to_show,omitempty"`
+	ToScreen             string   `protobuf:"bytes,7,opt,name=to_screen,json=toScreen,proto3" json:"to_screen,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,8,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	ToExpectedVersion    int64    `protobuf:"varint,9,opt,name=to_expected_version,json=toExpectedVersion,proto3" json:"to_expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,10,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MoveMovieDayScheduleRequest) Reset()         { *m = MoveMovieDayScheduleRequest{} }
+func (m *MoveMovieDayScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*MoveMovieDayScheduleRequest) ProtoMessage()    {}
+func (*MoveMovieDayScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{16}
+}
+
+func (m *MoveMovieDayScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MoveMovieDayScheduleRequest.Unmarshal(m, b)
+}
+func (m *MoveMovieDayScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MoveMovieDayScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *MoveMovieDayScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MoveMovieDayScheduleRequest.Merge(m, src)
+}
+func (m *MoveMovieDayScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_MoveMovieDayScheduleRequest.Size(m)
+}
+func (m *MoveMovieDayScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MoveMovieDayScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MoveMovieDayScheduleRequest proto.InternalMessageInfo
+
+func (m *MoveMovieDayScheduleRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *MoveMovieDayScheduleRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *MoveMovieDayScheduleRequest) GetToWeekDay() int32 {
+	if m != nil {
+		return m.ToWeekDay
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetToShow() int32 {
+	if m != nil {
+		return m.ToShow
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetToScreen() string {
+	if m != nil {
+		return m.ToScreen
+	}
+	return ""
+}
+
+func (m *MoveMovieDayScheduleRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetToExpectedVersion() int64 {
+	if m != nil {
+		return m.ToExpectedVersion
+	}
+	return 0
+}
+
+func (m *MoveMovieDayScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Request to swap the movies and voted movies of two shows
+type SwapMovieDaySchedulesRequest struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Show                 int32    `protobuf:"varint,2,opt,name=show,proto3" json:"show,omitempty"`
+	Screen               string   `protobuf:"bytes,3,opt,name=screen,proto3" json:"screen,omitempty"`
+	OtherWeekDay         int32    `protobuf:"varint,4,opt,name=other_week_day,json=otherWeekDay,proto3" json:"other_week_day,omitempty"`
+	OtherShow            int32    `protobuf:"varint,5,opt,name=other_show,json=otherShow,proto3" json:"other_show,omitempty"`
+	OtherScreen          string   `protobuf:"bytes,6,opt,name=other_screen,json=otherScreen,proto3" json:"other_screen,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,7,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	OtherExpectedVersion int64    `protobuf:"varint,8,opt,name=other_expected_version,json=otherExpectedVersion,proto3" json:"other_expected_version,omitempty"`
+	Draft                bool     `protobuf:"varint,9,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SwapMovieDaySchedulesRequest) Reset()         { *m = SwapMovieDaySchedulesRequest{} }
+func (m *SwapMovieDaySchedulesRequest) String() string { return proto.CompactTextString(m) }
+func (*SwapMovieDaySchedulesRequest) ProtoMessage()    {}
+func (*SwapMovieDaySchedulesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{17}
+}
+
+func (m *SwapMovieDaySchedulesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapMovieDaySchedulesRequest.Unmarshal(m, b)
+}
+func (m *SwapMovieDaySchedulesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapMovieDaySchedulesRequest.Marshal(b, m, deterministic)
+}
+func (m *SwapMovieDaySchedulesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapMovieDaySchedulesRequest.Merge(m, src)
+}
+func (m *SwapMovieDaySchedulesRequest) XXX_Size() int {
+	return xxx_messageInfo_SwapMovieDaySchedulesRequest.Size(m)
+}
+func (m *SwapMovieDaySchedulesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapMovieDaySchedulesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapMovieDaySchedulesRequest proto.InternalMessageInfo
+
+func (m *SwapMovieDaySchedulesRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetOtherWeekDay() int32 {
+	if m != nil {
+		return m.OtherWeekDay
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetOtherShow() int32 {
+	if m != nil {
+		return m.OtherShow
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetOtherScreen() string {
+	if m != nil {
+		return m.OtherScreen
+	}
+	return ""
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetOtherExpectedVersion() int64 {
+	if m != nil {
+		return m.OtherExpectedVersion
+	}
+	return 0
+}
+
+func (m *SwapMovieDaySchedulesRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// A change to the schedule in a batch
+type ScheduleOperation struct {
+	// Types that are valid to be assigned to Operation:
+	//	*ScheduleOperation_Create
+	//	*ScheduleOperation_Delete
+	//	*ScheduleOperation_AddVoted
+	//	*ScheduleOperation_Move
+	Operation            isScheduleOperation_Operation `protobuf_oneof:"operation"`
+	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
+	XXX_unrecognized     []byte                        `json:"-"`
+	XXX_sizecache        int32                         `json:"-"`
+}
+
+func (m *ScheduleOperation) Reset()         { *m = ScheduleOperation{} }
+func (m *ScheduleOperation) String() string { return proto.CompactTextString(m) }
+func (*ScheduleOperation) ProtoMessage()    {}
+func (*ScheduleOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{18}
+}
+
+func (m *ScheduleOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleOperation.Unmarshal(m, b)
+}
+func (m *ScheduleOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleOperation.Marshal(b, m, deterministic)
+}
+func (m *ScheduleOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleOperation.Merge(m, src)
+}
+func (m *ScheduleOperation) XXX_Size() int {
+	return xxx_messageInfo_ScheduleOperation.Size(m)
+}
+func (m *ScheduleOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleOperation proto.InternalMessageInfo
+
+type isScheduleOperation_Operation interface {
+	isScheduleOperation_Operation()
+}
+
+type ScheduleOperation_Create struct {
+	Create *CreateMovieDayScheduleRequest `protobuf:"bytes,1,opt,name=create,proto3,oneof"`
+}
+
+type ScheduleOperation_Delete struct {
+	Delete *DeleteMovieDayScheduleRequest `protobuf:"bytes,2,opt,name=delete,proto3,oneof"`
+}
+
+type ScheduleOperation_AddVoted struct {
+	AddVoted *AddVotedMovieRequest `protobuf:"bytes,3,opt,name=add_voted,json=addVoted,proto3,oneof"`
+}
+
+type ScheduleOperation_Move struct {
+	Move *MoveMovieDayScheduleRequest `protobuf:"bytes,4,opt,name=move,proto3,oneof"`
+}
+
+func (*ScheduleOperation_Create) isScheduleOperation_Operation() {}
+
+func (*ScheduleOperation_Delete) isScheduleOperation_Operation() {}
+
+func (*ScheduleOperation_AddVoted) isScheduleOperation_Operation() {}
+
+func (*ScheduleOperation_Move) isScheduleOperation_Operation() {}
+
+func (m *ScheduleOperation) GetOperation() isScheduleOperation_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (m *ScheduleOperation) GetCreate() *CreateMovieDayScheduleRequest {
+	if x, ok := m.GetOperation().(*ScheduleOperation_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (m *ScheduleOperation) GetDelete() *DeleteMovieDayScheduleRequest {
+	if x, ok := m.GetOperation().(*ScheduleOperation_Delete); ok {
+		return x.Delete
+	}
+	return nil
+}
+
+func (m *ScheduleOperation) GetAddVoted() *AddVotedMovieRequest {
+	if x, ok := m.GetOperation().(*ScheduleOperation_AddVoted); ok {
+		return x.AddVoted
+	}
+	return nil
+}
+
+func (m *ScheduleOperation) GetMove() *MoveMovieDayScheduleRequest {
+	if x, ok := m.GetOperation().(*ScheduleOperation_Move); ok {
+		return x.Move
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*ScheduleOperation) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*ScheduleOperation_Create)(nil),
+		(*ScheduleOperation_Delete)(nil),
+		(*ScheduleOperation_AddVoted)(nil),
+		(*ScheduleOperation_Move)(nil),
+	}
+}
+
+// Request to apply changes to the schedule together.
+// Operations are applied in order, so later operations see the changes of earlier ones
+type BatchUpdateScheduleRequest struct {
+	Operations           []*ScheduleOperation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	ValidateOnly         bool                 `protobuf:"varint,2,opt,name=validate_only,json=validateOnly,proto3" json:"validate_only,omitempty"`
+	Draft                bool                 `protobuf:"varint,3,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *BatchUpdateScheduleRequest) Reset()         { *m = BatchUpdateScheduleRequest{} }
+func (m *BatchUpdateScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchUpdateScheduleRequest) ProtoMessage()    {}
+func (*BatchUpdateScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{19}
+}
+
+func (m *BatchUpdateScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchUpdateScheduleRequest.Unmarshal(m, b)
+}
+func (m *BatchUpdateScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchUpdateScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *BatchUpdateScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchUpdateScheduleRequest.Merge(m, src)
+}
+func (m *BatchUpdateScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchUpdateScheduleRequest.Size(m)
+}
+func (m *BatchUpdateScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchUpdateScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchUpdateScheduleRequest proto.InternalMessageInfo
+
+func (m *BatchUpdateScheduleRequest) GetOperations() []*ScheduleOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+func (m *BatchUpdateScheduleRequest) GetValidateOnly() bool {
+	if m != nil {
+		return m.ValidateOnly
+	}
+	return false
+}
+
+func (m *BatchUpdateScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// An operation that failed. Operations are numbered from 1 and code is a gRPC status code
+type BatchOperationError struct {
+	Operation            int32    `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Code                 int32    `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchOperationError) Reset()         { *m = BatchOperationError{} }
+func (m *BatchOperationError) String() string { return proto.CompactTextString(m) }
+func (*BatchOperationError) ProtoMessage()    {}
+func (*BatchOperationError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{20}
+}
+
+func (m *BatchOperationError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchOperationError.Unmarshal(m, b)
+}
+func (m *BatchOperationError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchOperationError.Marshal(b, m, deterministic)
+}
+func (m *BatchOperationError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchOperationError.Merge(m, src)
+}
+func (m *BatchOperationError) XXX_Size() int {
+	return xxx_messageInfo_BatchOperationError.Size(m)
+}
+func (m *BatchOperationError) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchOperationError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchOperationError proto.InternalMessageInfo
+
+func (m *BatchOperationError) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *BatchOperationError) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *BatchOperationError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Response after applying a batch. Nothing is applied when there are errors
+type BatchUpdateScheduleResponse struct {
+	Errors               []*BatchOperationError `protobuf:"bytes,1,rep,name=errors,proto3" json:"errors,omitempty"`
+	Applied              bool                   `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *BatchUpdateScheduleResponse) Reset()         { *m = BatchUpdateScheduleResponse{} }
+func (m *BatchUpdateScheduleResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchUpdateScheduleResponse) ProtoMessage()    {}
+func (*BatchUpdateScheduleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{21}
+}
+
+func (m *BatchUpdateScheduleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchUpdateScheduleResponse.Unmarshal(m, b)
+}
+func (m *BatchUpdateScheduleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchUpdateScheduleResponse.Marshal(b, m, deterministic)
+}
+func (m *BatchUpdateScheduleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchUpdateScheduleResponse.Merge(m, src)
+}
+func (m *BatchUpdateScheduleResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchUpdateScheduleResponse.Size(m)
+}
+func (m *BatchUpdateScheduleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchUpdateScheduleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchUpdateScheduleResponse proto.InternalMessageInfo
+
+func (m *BatchUpdateScheduleResponse) GetErrors() []*BatchOperationError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *BatchUpdateScheduleResponse) GetApplied() bool {
+	if m != nil {
+		return m.Applied
+	}
+	return false
+}
+
+// A show in an imported or exported schedule
+type ScheduleRow struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Screen               string   `protobuf:"bytes,2,opt,name=screen,proto3" json:"screen,omitempty"`
+	Show                 int32    `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+	MovieId              string   `protobuf:"bytes,4,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	VotedMovieIds        []string `protobuf:"bytes,5,rep,name=voted_movie_ids,json=votedMovieIds,proto3" json:"voted_movie_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScheduleRow) Reset()         { *m = ScheduleRow{} }
+func (m *ScheduleRow) String() string { return proto.CompactTextString(m) }
+func (*ScheduleRow) ProtoMessage()    {}
+func (*ScheduleRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{22}
+}
+
+func (m *ScheduleRow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleRow.Unmarshal(m, b)
+}
+func (m *ScheduleRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleRow.Marshal(b, m, deterministic)
+}
+func (m *ScheduleRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleRow.Merge(m, src)
+}
+func (m *ScheduleRow) XXX_Size() int {
+	return xxx_messageInfo_ScheduleRow.Size(m)
+}
+func (m *ScheduleRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleRow proto.InternalMessageInfo
+
+func (m *ScheduleRow) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *ScheduleRow) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *ScheduleRow) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *ScheduleRow) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+func (m *ScheduleRow) GetVotedMovieIds() []string {
+	if m != nil {
+		return m.VotedMovieIds
+	}
+	return nil
+}
+
+// Request to import shows into the weekly schedule
+type ImportScheduleRequest struct {
+	Format               ScheduleFormat `protobuf:"varint,1,opt,name=format,proto3,enum=rupacinema.movie.ScheduleFormat" json:"format,omitempty"`
+	Data                 string         `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	DryRun               bool           `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Draft                bool           `protobuf:"varint,4,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ImportScheduleRequest) Reset()         { *m = ImportScheduleRequest{} }
+func (m *ImportScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportScheduleRequest) ProtoMessage()    {}
+func (*ImportScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{23}
+}
+
+func (m *ImportScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportScheduleRequest.Unmarshal(m, b)
+}
+func (m *ImportScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportScheduleRequest.Merge(m, src)
+}
+func (m *ImportScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportScheduleRequest.Size(m)
+}
+func (m *ImportScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportScheduleRequest proto.InternalMessageInfo
+
+func (m *ImportScheduleRequest) GetFormat() ScheduleFormat {
+	if m != nil {
+		return m.Format
+	}
+	return ScheduleFormat_CSV
+}
+
+func (m *ImportScheduleRequest) GetData() string {
+	if m != nil {
+		return m.Data
+	}
+	return ""
+}
+
+func (m *ImportScheduleRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *ImportScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// A row that failed validation. CSV rows are numbered from 2 after the header row, JSON rows from 1
+type ImportRowError struct {
+	Row                  int32    `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportRowError) Reset()         { *m = ImportRowError{} }
+func (m *ImportRowError) String() string { return proto.CompactTextString(m) }
+func (*ImportRowError) ProtoMessage()    {}
+func (*ImportRowError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{24}
+}
+
+func (m *ImportRowError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRowError.Unmarshal(m, b)
+}
+func (m *ImportRowError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRowError.Marshal(b, m, deterministic)
+}
+func (m *ImportRowError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRowError.Merge(m, src)
+}
+func (m *ImportRowError) XXX_Size() int {
+	return xxx_messageInfo_ImportRowError.Size(m)
+}
+func (m *ImportRowError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRowError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRowError proto.InternalMessageInfo
+
+func (m *ImportRowError) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportRowError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Response after importing shows
+type ImportScheduleResponse struct {
+	Rows                 int32             `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Errors               []*ImportRowError `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	Applied              bool              `protobuf:"varint,3,opt,name=applied,proto3" json:"applied,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ImportScheduleResponse) Reset()         { *m = ImportScheduleResponse{} }
+func (m *ImportScheduleResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportScheduleResponse) ProtoMessage()    {}
+func (*ImportScheduleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{25}
+}
+
+func (m *ImportScheduleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportScheduleResponse.Unmarshal(m, b)
+}
+func (m *ImportScheduleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportScheduleResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportScheduleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportScheduleResponse.Merge(m, src)
+}
+func (m *ImportScheduleResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportScheduleResponse.Size(m)
+}
+func (m *ImportScheduleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportScheduleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportScheduleResponse proto.InternalMessageInfo
+
+func (m *ImportScheduleResponse) GetRows() int32 {
+	if m != nil {
+		return m.Rows
+	}
+	return 0
+}
+
+func (m *ImportScheduleResponse) GetErrors() []*ImportRowError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *ImportScheduleResponse) GetApplied() bool {
+	if m != nil {
+		return m.Applied
+	}
+	return false
+}
+
+// Request to export the weekly schedule
+type ExportScheduleRequest struct {
+	Format               ScheduleFormat `protobuf:"varint,1,opt,name=format,proto3,enum=rupacinema.movie.ScheduleFormat" json:"format,omitempty"`
+	Draft                bool           `protobuf:"varint,2,opt,name=draft,proto3" json:"draft,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ExportScheduleRequest) Reset()         { *m = ExportScheduleRequest{} }
+func (m *ExportScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportScheduleRequest) ProtoMessage()    {}
+func (*ExportScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{26}
+}
+
+func (m *ExportScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportScheduleRequest.Unmarshal(m, b)
+}
+func (m *ExportScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportScheduleRequest.Merge(m, src)
+}
+func (m *ExportScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportScheduleRequest.Size(m)
+}
+func (m *ExportScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportScheduleRequest proto.InternalMessageInfo
+
+func (m *ExportScheduleRequest) GetFormat() ScheduleFormat {
+	if m != nil {
+		return m.Format
+	}
+	return ScheduleFormat_CSV
+}
+
+func (m *ExportScheduleRequest) GetDraft() bool {
+	if m != nil {
+		return m.Draft
+	}
+	return false
+}
+
+// Response containing the exported weekly schedule
+type ExportScheduleResponse struct {
+	Format               ScheduleFormat `protobuf:"varint,1,opt,name=format,proto3,enum=rupacinema.movie.ScheduleFormat" json:"format,omitempty"`
+	Data                 string         `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ExportScheduleResponse) Reset()         { *m = ExportScheduleResponse{} }
+func (m *ExportScheduleResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportScheduleResponse) ProtoMessage()    {}
+func (*ExportScheduleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{27}
+}
+
+func (m *ExportScheduleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportScheduleResponse.Unmarshal(m, b)
+}
+func (m *ExportScheduleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportScheduleResponse.Marshal(b, m, deterministic)
+}
+func (m *ExportScheduleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportScheduleResponse.Merge(m, src)
+}
+func (m *ExportScheduleResponse) XXX_Size() int {
+	return xxx_messageInfo_ExportScheduleResponse.Size(m)
+}
+func (m *ExportScheduleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportScheduleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportScheduleResponse proto.InternalMessageInfo
+
+func (m *ExportScheduleResponse) GetFormat() ScheduleFormat {
+	if m != nil {
+		return m.Format
+	}
+	return ScheduleFormat_CSV
+}
+
+func (m *ExportScheduleResponse) GetData() string {
+	if m != nil {
+		return m.Data
+	}
+	return ""
+}
+
+// Request to find the shows a movie is scheduled or nominated in
+type FindMovieShowsRequest struct {
+	MovieId              string   `protobuf:"bytes,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FindMovieShowsRequest) Reset()         { *m = FindMovieShowsRequest{} }
+func (m *FindMovieShowsRequest) String() string { return proto.CompactTextString(m) }
+func (*FindMovieShowsRequest) ProtoMessage()    {}
+func (*FindMovieShowsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{28}
+}
+
+func (m *FindMovieShowsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindMovieShowsRequest.Unmarshal(m, b)
+}
+func (m *FindMovieShowsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindMovieShowsRequest.Marshal(b, m, deterministic)
+}
+func (m *FindMovieShowsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindMovieShowsRequest.Merge(m, src)
+}
+func (m *FindMovieShowsRequest) XXX_Size() int {
+	return xxx_messageInfo_FindMovieShowsRequest.Size(m)
+}
+func (m *FindMovieShowsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindMovieShowsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindMovieShowsRequest proto.InternalMessageInfo
+
+func (m *FindMovieShowsRequest) GetMovieId() string {
+	if m != nil {
+		return m.MovieId
+	}
+	return ""
+}
+
+// A show that a movie is scheduled or nominated in.
+// Rank is 1 for the movie with the most votes in the show; movies with equal votes share a rank
+type MovieShow struct {
+	WeekDay              int32         `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Screen               string        `protobuf:"bytes,2,opt,name=screen,proto3" json:"screen,omitempty"`
+	Show                 int32         `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+	PlayTime             string        `protobuf:"bytes,4,opt,name=play_time,json=playTime,proto3" json:"play_time,omitempty"`
+	Role                 MovieShowRole `protobuf:"varint,5,opt,name=role,proto3,enum=rupacinema.movie.MovieShowRole" json:"role,omitempty"`
+	Votes                int32         `protobuf:"varint,6,opt,name=votes,proto3" json:"votes,omitempty"`
+	Rank                 int32         `protobuf:"varint,7,opt,name=rank,proto3" json:"rank,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *MovieShow) Reset()         { *m = MovieShow{} }
+func (m *MovieShow) String() string { return proto.CompactTextString(m) }
+func (*MovieShow) ProtoMessage()    {}
+func (*MovieShow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{29}
+}
+
+func (m *MovieShow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MovieShow.Unmarshal(m, b)
+}
+func (m *MovieShow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MovieShow.Marshal(b, m, deterministic)
+}
+func (m *MovieShow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MovieShow.Merge(m, src)
+}
+func (m *MovieShow) XXX_Size() int {
+	return xxx_messageInfo_MovieShow.Size(m)
+}
+func (m *MovieShow) XXX_DiscardUnknown() {
+	xxx_messageInfo_MovieShow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MovieShow proto.InternalMessageInfo
+
+func (m *MovieShow) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *MovieShow) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *MovieShow) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *MovieShow) GetPlayTime() string {
+	if m != nil {
+		return m.PlayTime
+	}
+	return ""
+}
+
+func (m *MovieShow) GetRole() MovieShowRole {
+	if m != nil {
+		return m.Role
+	}
+	return MovieShowRole_SHOWING
+}
+
+func (m *MovieShow) GetVotes() int32 {
+	if m != nil {
+		return m.Votes
+	}
+	return 0
+}
+
+func (m *MovieShow) GetRank() int32 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+// Response containing the shows of a movie in day, screen and show order
+type FindMovieShowsResponse struct {
+	Shows                []*MovieShow `protobuf:"bytes,1,rep,name=shows,proto3" json:"shows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *FindMovieShowsResponse) Reset()         { *m = FindMovieShowsResponse{} }
+func (m *FindMovieShowsResponse) String() string { return proto.CompactTextString(m) }
+func (*FindMovieShowsResponse) ProtoMessage()    {}
+func (*FindMovieShowsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{30}
+}
+
+func (m *FindMovieShowsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindMovieShowsResponse.Unmarshal(m, b)
+}
+func (m *FindMovieShowsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindMovieShowsResponse.Marshal(b, m, deterministic)
+}
+func (m *FindMovieShowsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindMovieShowsResponse.Merge(m, src)
+}
+func (m *FindMovieShowsResponse) XXX_Size() int {
+	return xxx_messageInfo_FindMovieShowsResponse.Size(m)
+}
+func (m *FindMovieShowsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindMovieShowsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindMovieShowsResponse proto.InternalMessageInfo
+
+func (m *FindMovieShowsResponse) GetShows() []*MovieShow {
+	if m != nil {
+		return m.Shows
+	}
+	return nil
+}
+
+// A change to one field of a show between two schedules.
+// Field is one of slot, play_time, movie, votes and voted_movies
+type ScheduleDifference struct {
+	WeekDay              int32    `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Screen               string   `protobuf:"bytes,2,opt,name=screen,proto3" json:"screen,omitempty"`
+	Show                 int32    `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+	Field                string   `protobuf:"bytes,4,opt,name=field,proto3" json:"field,omitempty"`
+	Before               string   `protobuf:"bytes,5,opt,name=before,proto3" json:"before,omitempty"`
+	After                string   `protobuf:"bytes,6,opt,name=after,proto3" json:"after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScheduleDifference) Reset()         { *m = ScheduleDifference{} }
+func (m *ScheduleDifference) String() string { return proto.CompactTextString(m) }
+func (*ScheduleDifference) ProtoMessage()    {}
+func (*ScheduleDifference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{31}
+}
+
+func (m *ScheduleDifference) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleDifference.Unmarshal(m, b)
+}
+func (m *ScheduleDifference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleDifference.Marshal(b, m, deterministic)
+}
+func (m *ScheduleDifference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleDifference.Merge(m, src)
+}
+func (m *ScheduleDifference) XXX_Size() int {
+	return xxx_messageInfo_ScheduleDifference.Size(m)
+}
+func (m *ScheduleDifference) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleDifference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleDifference proto.InternalMessageInfo
+
+func (m *ScheduleDifference) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *ScheduleDifference) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *ScheduleDifference) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *ScheduleDifference) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *ScheduleDifference) GetBefore() string {
+	if m != nil {
+		return m.Before
+	}
+	return ""
+}
+
+func (m *ScheduleDifference) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+// Request to compare the draft with the published schedule
+type DiffDraftRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DiffDraftRequest) Reset()         { *m = DiffDraftRequest{} }
+func (m *DiffDraftRequest) String() string { return proto.CompactTextString(m) }
+func (*DiffDraftRequest) ProtoMessage()    {}
+func (*DiffDraftRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{32}
+}
+
+func (m *DiffDraftRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffDraftRequest.Unmarshal(m, b)
+}
+func (m *DiffDraftRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffDraftRequest.Marshal(b, m, deterministic)
+}
+func (m *DiffDraftRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffDraftRequest.Merge(m, src)
+}
+func (m *DiffDraftRequest) XXX_Size() int {
+	return xxx_messageInfo_DiffDraftRequest.Size(m)
+}
+func (m *DiffDraftRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffDraftRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffDraftRequest proto.InternalMessageInfo
+
+// Response containing the changes the draft makes to the published schedule in day, screen and show order
+type DiffDraftResponse struct {
+	Differences          []*ScheduleDifference `protobuf:"bytes,1,rep,name=differences,proto3" json:"differences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DiffDraftResponse) Reset()         { *m = DiffDraftResponse{} }
+func (m *DiffDraftResponse) String() string { return proto.CompactTextString(m) }
+func (*DiffDraftResponse) ProtoMessage()    {}
+func (*DiffDraftResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{33}
+}
+
+func (m *DiffDraftResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffDraftResponse.Unmarshal(m, b)
+}
+func (m *DiffDraftResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffDraftResponse.Marshal(b, m, deterministic)
+}
+func (m *DiffDraftResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffDraftResponse.Merge(m, src)
+}
+func (m *DiffDraftResponse) XXX_Size() int {
+	return xxx_messageInfo_DiffDraftResponse.Size(m)
+}
+func (m *DiffDraftResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffDraftResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffDraftResponse proto.InternalMessageInfo
+
+func (m *DiffDraftResponse) GetDifferences() []*ScheduleDifference {
+	if m != nil {
+		return m.Differences
+	}
+	return nil
+}
+
+// Request to publish the draft
+type PublishDraftRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublishDraftRequest) Reset()         { *m = PublishDraftRequest{} }
+func (m *PublishDraftRequest) String() string { return proto.CompactTextString(m) }
+func (*PublishDraftRequest) ProtoMessage()    {}
+func (*PublishDraftRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{34}
+}
+
+func (m *PublishDraftRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishDraftRequest.Unmarshal(m, b)
+}
+func (m *PublishDraftRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishDraftRequest.Marshal(b, m, deterministic)
+}
+func (m *PublishDraftRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishDraftRequest.Merge(m, src)
+}
+func (m *PublishDraftRequest) XXX_Size() int {
+	return xxx_messageInfo_PublishDraftRequest.Size(m)
+}
+func (m *PublishDraftRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishDraftRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishDraftRequest proto.InternalMessageInfo
+
+// Response containing the changes made to the published schedule
+type PublishDraftResponse struct {
+	Differences          []*ScheduleDifference `protobuf:"bytes,1,rep,name=differences,proto3" json:"differences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *PublishDraftResponse) Reset()         { *m = PublishDraftResponse{} }
+func (m *PublishDraftResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishDraftResponse) ProtoMessage()    {}
+func (*PublishDraftResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{35}
+}
+
+func (m *PublishDraftResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishDraftResponse.Unmarshal(m, b)
+}
+func (m *PublishDraftResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishDraftResponse.Marshal(b, m, deterministic)
+}
+func (m *PublishDraftResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishDraftResponse.Merge(m, src)
+}
+func (m *PublishDraftResponse) XXX_Size() int {
+	return xxx_messageInfo_PublishDraftResponse.Size(m)
+}
+func (m *PublishDraftResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishDraftResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishDraftResponse proto.InternalMessageInfo
+
+func (m *PublishDraftResponse) GetDifferences() []*ScheduleDifference {
+	if m != nil {
+		return m.Differences
+	}
+	return nil
+}
+
+// The state of a show before and after a change. Before is unset for added slots and after for removed ones
+type ShowChange struct {
+	WeekDay              int32         `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Screen               string        `protobuf:"bytes,2,opt,name=screen,proto3" json:"screen,omitempty"`
+	Show                 int32         `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+	Before               *ShowSchedule `protobuf:"bytes,4,opt,name=before,proto3" json:"before,omitempty"`
+	After                *ShowSchedule `protobuf:"bytes,5,opt,name=after,proto3" json:"after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ShowChange) Reset()         { *m = ShowChange{} }
+func (m *ShowChange) String() string { return proto.CompactTextString(m) }
+func (*ShowChange) ProtoMessage()    {}
+func (*ShowChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{36}
+}
+
+func (m *ShowChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShowChange.Unmarshal(m, b)
+}
+func (m *ShowChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShowChange.Marshal(b, m, deterministic)
+}
+func (m *ShowChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShowChange.Merge(m, src)
+}
+func (m *ShowChange) XXX_Size() int {
+	return xxx_messageInfo_ShowChange.Size(m)
+}
+func (m *ShowChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShowChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShowChange proto.InternalMessageInfo
+
+func (m *ShowChange) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *ShowChange) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *ShowChange) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *ShowChange) GetBefore() *ShowSchedule {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *ShowChange) GetAfter() *ShowSchedule {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+// A mutation of the published schedule. Votes and movie refreshes are not recorded
+type ScheduleChange struct {
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ChangeTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=change_time,json=changeTime,proto3" json:"change_time,omitempty"`
+	// Id of the user who made the change, empty for changes made by the service
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Name of the RPC or operation that made the change
+	Method               string        `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Shows                []*ShowChange `protobuf:"bytes,5,rep,name=shows,proto3" json:"shows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ScheduleChange) Reset()         { *m = ScheduleChange{} }
+func (m *ScheduleChange) String() string { return proto.CompactTextString(m) }
+func (*ScheduleChange) ProtoMessage()    {}
+func (*ScheduleChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{37}
+}
+
+func (m *ScheduleChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleChange.Unmarshal(m, b)
+}
+func (m *ScheduleChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleChange.Marshal(b, m, deterministic)
+}
+func (m *ScheduleChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleChange.Merge(m, src)
+}
+func (m *ScheduleChange) XXX_Size() int {
+	return xxx_messageInfo_ScheduleChange.Size(m)
+}
+func (m *ScheduleChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleChange proto.InternalMessageInfo
+
+func (m *ScheduleChange) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ScheduleChange) GetChangeTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.ChangeTime
+	}
+	return nil
+}
+
+func (m *ScheduleChange) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *ScheduleChange) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *ScheduleChange) GetShows() []*ShowChange {
+	if m != nil {
+		return m.Shows
+	}
+	return nil
+}
+
+// Request to list changes made to the published schedule, newest first.
+// Filters that are set must all match; day, screen and show match changes to any show in them
+type ListScheduleChangesRequest struct {
+	WeekDay              int32                  `protobuf:"varint,1,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Screen               string                 `protobuf:"bytes,2,opt,name=screen,proto3" json:"screen,omitempty"`
+	Show                 int32                  `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+	Actor                string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	FromTime             *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`
+	ToTime               *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`
+	PageSize             int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string                 `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ListScheduleChangesRequest) Reset()         { *m = ListScheduleChangesRequest{} }
+func (m *ListScheduleChangesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListScheduleChangesRequest) ProtoMessage()    {}
+func (*ListScheduleChangesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{38}
+}
+
+func (m *ListScheduleChangesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListScheduleChangesRequest.Unmarshal(m, b)
+}
+func (m *ListScheduleChangesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListScheduleChangesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListScheduleChangesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListScheduleChangesRequest.Merge(m, src)
+}
+func (m *ListScheduleChangesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListScheduleChangesRequest.Size(m)
+}
+func (m *ListScheduleChangesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListScheduleChangesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListScheduleChangesRequest proto.InternalMessageInfo
+
+func (m *ListScheduleChangesRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+func (m *ListScheduleChangesRequest) GetScreen() string {
+	if m != nil {
+		return m.Screen
+	}
+	return ""
+}
+
+func (m *ListScheduleChangesRequest) GetShow() int32 {
+	if m != nil {
+		return m.Show
+	}
+	return 0
+}
+
+func (m *ListScheduleChangesRequest) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *ListScheduleChangesRequest) GetFromTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.FromTime
+	}
+	return nil
+}
+
+func (m *ListScheduleChangesRequest) GetToTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.ToTime
+	}
+	return nil
+}
+
+func (m *ListScheduleChangesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListScheduleChangesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+// Response containing a page of changes
+type ListScheduleChangesResponse struct {
+	Changes              []*ScheduleChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	NextPageToken        string            `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ListScheduleChangesResponse) Reset()         { *m = ListScheduleChangesResponse{} }
+func (m *ListScheduleChangesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListScheduleChangesResponse) ProtoMessage()    {}
+func (*ListScheduleChangesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{39}
+}
+
+func (m *ListScheduleChangesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListScheduleChangesResponse.Unmarshal(m, b)
+}
+func (m *ListScheduleChangesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListScheduleChangesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListScheduleChangesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListScheduleChangesResponse.Merge(m, src)
+}
+func (m *ListScheduleChangesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListScheduleChangesResponse.Size(m)
+}
+func (m *ListScheduleChangesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListScheduleChangesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListScheduleChangesResponse proto.InternalMessageInfo
+
+func (m *ListScheduleChangesResponse) GetChanges() []*ScheduleChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *ListScheduleChangesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// Request to compare the published schedule at two points in time.
+// to_time defaults to now and week_day, if set, limits the comparison to a day
+type DiffSchedulesRequest struct {
+	FromTime             *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`
+	ToTime               *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`
+	WeekDay              int32                  `protobuf:"varint,3,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *DiffSchedulesRequest) Reset()         { *m = DiffSchedulesRequest{} }
+func (m *DiffSchedulesRequest) String() string { return proto.CompactTextString(m) }
+func (*DiffSchedulesRequest) ProtoMessage()    {}
+func (*DiffSchedulesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{40}
+}
+
+func (m *DiffSchedulesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffSchedulesRequest.Unmarshal(m, b)
+}
+func (m *DiffSchedulesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffSchedulesRequest.Marshal(b, m, deterministic)
+}
+func (m *DiffSchedulesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffSchedulesRequest.Merge(m, src)
+}
+func (m *DiffSchedulesRequest) XXX_Size() int {
+	return xxx_messageInfo_DiffSchedulesRequest.Size(m)
+}
+func (m *DiffSchedulesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffSchedulesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffSchedulesRequest proto.InternalMessageInfo
+
+func (m *DiffSchedulesRequest) GetFromTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.FromTime
+	}
+	return nil
+}
+
+func (m *DiffSchedulesRequest) GetToTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.ToTime
+	}
+	return nil
+}
+
+func (m *DiffSchedulesRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+// Response containing the differences between the two schedules in day, screen and show order
+type DiffSchedulesResponse struct {
+	Differences          []*ScheduleDifference `protobuf:"bytes,1,rep,name=differences,proto3" json:"differences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DiffSchedulesResponse) Reset()         { *m = DiffSchedulesResponse{} }
+func (m *DiffSchedulesResponse) String() string { return proto.CompactTextString(m) }
+func (*DiffSchedulesResponse) ProtoMessage()    {}
+func (*DiffSchedulesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{41}
+}
+
+func (m *DiffSchedulesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffSchedulesResponse.Unmarshal(m, b)
+}
+func (m *DiffSchedulesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffSchedulesResponse.Marshal(b, m, deterministic)
+}
+func (m *DiffSchedulesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffSchedulesResponse.Merge(m, src)
+}
+func (m *DiffSchedulesResponse) XXX_Size() int {
+	return xxx_messageInfo_DiffSchedulesResponse.Size(m)
+}
+func (m *DiffSchedulesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffSchedulesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffSchedulesResponse proto.InternalMessageInfo
+
+func (m *DiffSchedulesResponse) GetDifferences() []*ScheduleDifference {
+	if m != nil {
+		return m.Differences
+	}
+	return nil
+}
+
+// Request to revert a recorded change to the published schedule
+type UndoChangeRequest struct {
+	ChangeId             int64    `protobuf:"varint,1,opt,name=change_id,json=changeId,proto3" json:"change_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UndoChangeRequest) Reset()         { *m = UndoChangeRequest{} }
+func (m *UndoChangeRequest) String() string { return proto.CompactTextString(m) }
+func (*UndoChangeRequest) ProtoMessage()    {}
+func (*UndoChangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{42}
+}
+
+func (m *UndoChangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndoChangeRequest.Unmarshal(m, b)
+}
+func (m *UndoChangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndoChangeRequest.Marshal(b, m, deterministic)
+}
+func (m *UndoChangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndoChangeRequest.Merge(m, src)
+}
+func (m *UndoChangeRequest) XXX_Size() int {
+	return xxx_messageInfo_UndoChangeRequest.Size(m)
+}
+func (m *UndoChangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndoChangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UndoChangeRequest proto.InternalMessageInfo
+
+func (m *UndoChangeRequest) GetChangeId() int64 {
+	if m != nil {
+		return m.ChangeId
+	}
+	return 0
+}
+
+// Response containing the changes made to the published schedule to revert the change
+type UndoChangeResponse struct {
+	Differences          []*ScheduleDifference `protobuf:"bytes,1,rep,name=differences,proto3" json:"differences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *UndoChangeResponse) Reset()         { *m = UndoChangeResponse{} }
+func (m *UndoChangeResponse) String() string { return proto.CompactTextString(m) }
+func (*UndoChangeResponse) ProtoMessage()    {}
+func (*UndoChangeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{43}
+}
+
+func (m *UndoChangeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndoChangeResponse.Unmarshal(m, b)
+}
+func (m *UndoChangeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndoChangeResponse.Marshal(b, m, deterministic)
+}
+func (m *UndoChangeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndoChangeResponse.Merge(m, src)
+}
+func (m *UndoChangeResponse) XXX_Size() int {
+	return xxx_messageInfo_UndoChangeResponse.Size(m)
+}
+func (m *UndoChangeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndoChangeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UndoChangeResponse proto.InternalMessageInfo
+
+func (m *UndoChangeResponse) GetDifferences() []*ScheduleDifference {
+	if m != nil {
+		return m.Differences
+	}
+	return nil
+}
+
+// Request to roll the published schedule back to a point in time.
+// week_day, if set, limits the restore to a day
+type RestoreScheduleRequest struct {
+	RestoreTime          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=restore_time,json=restoreTime,proto3" json:"restore_time,omitempty"`
+	WeekDay              int32                  `protobuf:"varint,2,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *RestoreScheduleRequest) Reset()         { *m = RestoreScheduleRequest{} }
+func (m *RestoreScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreScheduleRequest) ProtoMessage()    {}
+func (*RestoreScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{44}
+}
+
+func (m *RestoreScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreScheduleRequest.Unmarshal(m, b)
+}
+func (m *RestoreScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *RestoreScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreScheduleRequest.Merge(m, src)
+}
+func (m *RestoreScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreScheduleRequest.Size(m)
+}
+func (m *RestoreScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreScheduleRequest proto.InternalMessageInfo
+
+func (m *RestoreScheduleRequest) GetRestoreTime() *timestamppb.Timestamp {
+	if m != nil {
+		return m.RestoreTime
+	}
+	return nil
+}
+
+func (m *RestoreScheduleRequest) GetWeekDay() int32 {
+	if m != nil {
+		return m.WeekDay
+	}
+	return 0
+}
+
+// Response containing the changes made to the published schedule to restore it
+type RestoreScheduleResponse struct {
+	Differences          []*ScheduleDifference `protobuf:"bytes,1,rep,name=differences,proto3" json:"differences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *RestoreScheduleResponse) Reset()         { *m = RestoreScheduleResponse{} }
+func (m *RestoreScheduleResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreScheduleResponse) ProtoMessage()    {}
+func (*RestoreScheduleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{45}
+}
+
+func (m *RestoreScheduleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreScheduleResponse.Unmarshal(m, b)
+}
+func (m *RestoreScheduleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreScheduleResponse.Marshal(b, m, deterministic)
+}
+func (m *RestoreScheduleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreScheduleResponse.Merge(m, src)
+}
+func (m *RestoreScheduleResponse) XXX_Size() int {
+	return xxx_messageInfo_RestoreScheduleResponse.Size(m)
+}
+func (m *RestoreScheduleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreScheduleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreScheduleResponse proto.InternalMessageInfo
+
+func (m *RestoreScheduleResponse) GetDifferences() []*ScheduleDifference {
+	if m != nil {
+		return m.Differences
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("rupacinema.movie.ScheduleFormat", ScheduleFormat_name, ScheduleFormat_value)
+	proto.RegisterEnum("rupacinema.movie.MovieShowRole", MovieShowRole_name, MovieShowRole_value)
+	proto.RegisterType((*ShowSchedule)(nil), "rupacinema.movie.ShowSchedule")
+	proto.RegisterType((*ShowsSchedule)(nil), "rupacinema.movie.ShowsSchedule")
+	proto.RegisterMapType((map[int32]*ShowSchedule)(nil), "rupacinema.movie.ShowsSchedule.ShowsScheduleEntry")
+	proto.RegisterType((*ScreensSchedule)(nil), "rupacinema.movie.ScreensSchedule")
+	proto.RegisterMapType((map[string]*ShowsSchedule)(nil), "rupacinema.movie.ScreensSchedule.ScreensScheduleEntry")
+	proto.RegisterType((*DaysSchedule)(nil), "rupacinema.movie.DaysSchedule")
+	proto.RegisterMapType((map[int32]*ScreensSchedule)(nil), "rupacinema.movie.DaysSchedule.DaysScheduleEntry")
+	proto.RegisterType((*VoteUpMovieRequest)(nil), "rupacinema.movie.VoteUpMovieRequest")
+	proto.RegisterType((*GetDayScheduleRequest)(nil), "rupacinema.movie.GetDayScheduleRequest")
+	proto.RegisterType((*GetWeekScheduleRequest)(nil), "rupacinema.movie.GetWeekScheduleRequest")
+	proto.RegisterType((*GetShowScheduleRequest)(nil), "rupacinema.movie.GetShowScheduleRequest")
+	proto.RegisterType((*AddVotedMovieRequest)(nil), "rupacinema.movie.AddVotedMovieRequest")
+	proto.RegisterType((*RemoveVotedMovieRequest)(nil), "rupacinema.movie.RemoveVotedMovieRequest")
+	proto.RegisterType((*VoteRefund)(nil), "rupacinema.movie.VoteRefund")
+	proto.RegisterType((*RemoveVotedMovieResponse)(nil), "rupacinema.movie.RemoveVotedMovieResponse")
+	proto.RegisterType((*ReorderVotedMoviesRequest)(nil), "rupacinema.movie.ReorderVotedMoviesRequest")
+	proto.RegisterType((*CreateMovieDayScheduleRequest)(nil), "rupacinema.movie.CreateMovieDayScheduleRequest")
+	proto.RegisterType((*DeleteMovieDayScheduleRequest)(nil), "rupacinema.movie.DeleteMovieDayScheduleRequest")
+	proto.RegisterType((*UpdateMovieDayScheduleRequest)(nil), "rupacinema.movie.UpdateMovieDayScheduleRequest")
+	proto.RegisterType((*MoveMovieDayScheduleRequest)(nil), "rupacinema.movie.MoveMovieDayScheduleRequest")
+	proto.RegisterType((*SwapMovieDaySchedulesRequest)(nil), "rupacinema.movie.SwapMovieDaySchedulesRequest")
+	proto.RegisterType((*ScheduleOperation)(nil), "rupacinema.movie.ScheduleOperation")
+	proto.RegisterType((*BatchUpdateScheduleRequest)(nil), "rupacinema.movie.BatchUpdateScheduleRequest")
+	proto.RegisterType((*BatchOperationError)(nil), "rupacinema.movie.BatchOperationError")
+	proto.RegisterType((*BatchUpdateScheduleResponse)(nil), "rupacinema.movie.BatchUpdateScheduleResponse")
+	proto.RegisterType((*ScheduleRow)(nil), "rupacinema.movie.ScheduleRow")
+	proto.RegisterType((*ImportScheduleRequest)(nil), "rupacinema.movie.ImportScheduleRequest")
+	proto.RegisterType((*ImportRowError)(nil), "rupacinema.movie.ImportRowError")
+	proto.RegisterType((*ImportScheduleResponse)(nil), "rupacinema.movie.ImportScheduleResponse")
+	proto.RegisterType((*ExportScheduleRequest)(nil), "rupacinema.movie.ExportScheduleRequest")
+	proto.RegisterType((*ExportScheduleResponse)(nil), "rupacinema.movie.ExportScheduleResponse")
+	proto.RegisterType((*FindMovieShowsRequest)(nil), "rupacinema.movie.FindMovieShowsRequest")
+	proto.RegisterType((*MovieShow)(nil), "rupacinema.movie.MovieShow")
+	proto.RegisterType((*FindMovieShowsResponse)(nil), "rupacinema.movie.FindMovieShowsResponse")
+	proto.RegisterType((*ScheduleDifference)(nil), "rupacinema.movie.ScheduleDifference")
+	proto.RegisterType((*DiffDraftRequest)(nil), "rupacinema.movie.DiffDraftRequest")
+	proto.RegisterType((*DiffDraftResponse)(nil), "rupacinema.movie.DiffDraftResponse")
+	proto.RegisterType((*PublishDraftRequest)(nil), "rupacinema.movie.PublishDraftRequest")
+	proto.RegisterType((*PublishDraftResponse)(nil), "rupacinema.movie.PublishDraftResponse")
+	proto.RegisterType((*ShowChange)(nil), "rupacinema.movie.ShowChange")
+	proto.RegisterType((*ScheduleChange)(nil), "rupacinema.movie.ScheduleChange")
+	proto.RegisterType((*ListScheduleChangesRequest)(nil), "rupacinema.movie.ListScheduleChangesRequest")
+	proto.RegisterType((*ListScheduleChangesResponse)(nil), "rupacinema.movie.ListScheduleChangesResponse")
+	proto.RegisterType((*DiffSchedulesRequest)(nil), "rupacinema.movie.DiffSchedulesRequest")
+	proto.RegisterType((*DiffSchedulesResponse)(nil), "rupacinema.movie.DiffSchedulesResponse")
+	proto.RegisterType((*UndoChangeRequest)(nil), "rupacinema.movie.UndoChangeRequest")
+	proto.RegisterType((*UndoChangeResponse)(nil), "rupacinema.movie.UndoChangeResponse")
+	proto.RegisterType((*RestoreScheduleRequest)(nil), "rupacinema.movie.RestoreScheduleRequest")
+	proto.RegisterType((*RestoreScheduleResponse)(nil), "rupacinema.movie.RestoreScheduleResponse")
+}
+
+func init() { proto.RegisterFile("schedule.proto", fileDescriptor_d00842e68e05382a) }
+
+var fileDescriptor_d00842e68e05382a = []byte{
+	// 2684 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd5, 0x5a, 0xdd, 0x6f, 0x1c, 0x49,
+	0x11, 0xbf, 0xd9, 0xef, 0xad, 0xf5, 0xda, 0x4e, 0xc7, 0x76, 0x9c, 0x59, 0xc7, 0x4e, 0xc6, 0xbe,
+	0x7c, 0xf8, 0x12, 0xfb, 0xd8, 0x0b, 0xb9, 0xc3, 0x70, 0x0f, 0x97, 0xd8, 0xb9, 0x24, 0x90, 0xe4,
+	0x34, 0xce, 0x87, 0x80, 0x13, 0xcb, 0x78, 0xb7, 0x6d, 0xaf, 0xb2, 0xbb, 0xb3, 0xcc, 0xcc, 0x26,
+	0x31, 0x51, 0x24, 0x38, 0x09, 0x84, 0x84, 0x84, 0xd0, 0x1d, 0x12, 0x3c, 0x9c, 0xc4, 0x03, 0x7f,
+	0x00, 0xcf, 0x3c, 0xf1, 0x21, 0x84, 0x84, 0x84, 0x78, 0x41, 0x3c, 0x23, 0x21, 0xfe, 0x10, 0xba,
+	0xab, 0xbb, 0x77, 0x67, 0x66, 0x7b, 0x76, 0x9d, 0x63, 0xa5, 0xe3, 0x5e, 0x92, 0xed, 0xee, 0xea,
+	0xaa, 0x5f, 0x55, 0x75, 0x55, 0x77, 0xd5, 0x18, 0xa6, 0xfd, 0xfa, 0x21, 0x6d, 0xf4, 0x5a, 0x74,
+	0xa3, 0xeb, 0xb9, 0x81, 0x4b, 0x66, 0xbd, 0x5e, 0xd7, 0xa9, 0x37, 0x3b, 0xb4, 0xed, 0x6c, 0xb4,
+	0xdd, 0xa7, 0x4d, 0x6a, 0x56, 0x0e, 0x5c, 0xf7, 0xa0, 0x45, 0x37, 0x71, 0x7d, 0xaf, 0xb7, 0xbf,
+	0x49, 0xdb, 0xdd, 0xe0, 0x48, 0x90, 0x9b, 0x67, 0xe3, 0x8b, 0xfb, 0x4d, 0xda, 0x6a, 0xd4, 0xda,
+	0x8e, 0xff, 0x44, 0x52, 0xac, 0xc4, 0x29, 0x82, 0x66, 0x9b, 0xfa, 0x81, 0xd3, 0xee, 0x4a, 0x82,
+	0x25, 0x49, 0xe0, 0x74, 0x9b, 0x9b, 0x4e, 0xa7, 0xe3, 0x06, 0x4e, 0xd0, 0x74, 0x3b, 0xbe, 0x5c,
+	0xbd, 0x8c, 0xff, 0xd5, 0xaf, 0x1c, 0xd0, 0xce, 0x15, 0xff, 0x99, 0x73, 0x70, 0x40, 0xbd, 0x4d,
+	0xb7, 0x8b, 0x14, 0x1a, 0xea, 0x0a, 0x42, 0x46, 0x56, 0x38, 0xb1, 0x89, 0x63, 0xb1, 0x68, 0xfd,
+	0xd3, 0x80, 0xa9, 0xdd, 0x43, 0xf7, 0xd9, 0xae, 0xd4, 0x98, 0x54, 0xa0, 0xd8, 0x6d, 0x39, 0x47,
+	0x35, 0x8e, 0x68, 0xd1, 0x38, 0x6b, 0x5c, 0x2c, 0xda, 0x05, 0x3e, 0xf1, 0x80, 0x8d, 0xc9, 0x15,
+	0xc8, 0xe2, 0xe6, 0xc5, 0x14, 0x5b, 0x28, 0x55, 0x4f, 0x6d, 0xc4, 0x0d, 0xb3, 0x71, 0x97, 0xff,
+	0x6b, 0x0b, 0x2a, 0xb2, 0x05, 0x53, 0x4f, 0xdd, 0x80, 0x32, 0xd5, 0xf9, 0xd0, 0x5f, 0x4c, 0x9f,
+	0x4d, 0x8f, 0xda, 0x55, 0x42, 0x62, 0xfc, 0xed, 0x93, 0x45, 0xc8, 0x3f, 0xa5, 0x9e, 0xcf, 0xf4,
+	0x58, 0xcc, 0x30, 0x61, 0x69, 0x5b, 0x0d, 0xc9, 0x2a, 0x94, 0x39, 0xa1, 0x5f, 0x53, 0xeb, 0x59,
+	0x5c, 0x47, 0x51, 0xfe, 0x23, 0x31, 0x67, 0xfd, 0xcd, 0x80, 0x32, 0xd7, 0xcb, 0xef, 0x2b, 0xf6,
+	0x4d, 0x98, 0xf6, 0xf9, 0x44, 0x4d, 0x39, 0x97, 0x69, 0xc7, 0xe1, 0x54, 0x87, 0xe1, 0x44, 0x36,
+	0x46, 0x47, 0x3b, 0x9d, 0xc0, 0x3b, 0xb2, 0xcb, 0x7e, 0x78, 0xce, 0xfc, 0x2e, 0x90, 0x61, 0x22,
+	0x32, 0x0b, 0xe9, 0x27, 0xf4, 0x08, 0x6d, 0x98, 0xb5, 0xf9, 0x4f, 0x72, 0x15, 0xb2, 0x4f, 0x9d,
+	0x56, 0x4f, 0x99, 0x6f, 0x59, 0x2f, 0x59, 0x71, 0xb1, 0x05, 0xf1, 0x56, 0xea, 0x1d, 0xc3, 0xfa,
+	0x55, 0x0a, 0x66, 0x76, 0xeb, 0x1e, 0xa5, 0x9d, 0x81, 0x42, 0x0e, 0xcc, 0xfa, 0x62, 0x2a, 0xae,
+	0xd2, 0x35, 0x0d, 0xe3, 0xe8, 0xe6, 0xf8, 0x58, 0xa8, 0x35, 0xe3, 0xc7, 0x44, 0x84, 0x9c, 0x90,
+	0x1a, 0xe3, 0x84, 0xf4, 0xb0, 0x13, 0xcc, 0x3a, 0xcc, 0xe9, 0xe4, 0x84, 0x2d, 0x53, 0x14, 0x96,
+	0xf9, 0x72, 0xd4, 0x32, 0x2b, 0x63, 0x7c, 0x12, 0x36, 0xcd, 0x5f, 0xd9, 0x09, 0xde, 0x76, 0x8e,
+	0x06, 0xa0, 0x1f, 0x42, 0xb9, 0xc1, 0xc6, 0x71, 0xa3, 0xbc, 0x39, 0xcc, 0x33, 0xbc, 0x2d, 0x32,
+	0x10, 0xe6, 0x98, 0x6a, 0x84, 0xa6, 0xcc, 0x3d, 0x38, 0x31, 0x44, 0xa2, 0xf1, 0xf1, 0xdb, 0x51,
+	0x4d, 0xce, 0x8d, 0x75, 0x45, 0x58, 0x97, 0xdf, 0x19, 0x40, 0x1e, 0x31, 0x0b, 0x3e, 0xec, 0x8a,
+	0x88, 0xa0, 0xdf, 0xeb, 0xb1, 0xb4, 0x40, 0x4e, 0x43, 0x01, 0xb7, 0xd6, 0x9a, 0x0d, 0x69, 0xb4,
+	0x3c, 0x8e, 0x6f, 0x37, 0xc8, 0x29, 0xc8, 0xf7, 0x7c, 0xea, 0xf1, 0x95, 0x14, 0xae, 0xe4, 0xf8,
+	0x90, 0x2d, 0x2c, 0x40, 0x4e, 0x78, 0x13, 0xc3, 0x83, 0xcd, 0x8b, 0x11, 0x8f, 0x6f, 0x7e, 0x78,
+	0x45, 0x7c, 0xe7, 0x44, 0x7c, 0xf3, 0x09, 0x8c, 0x6f, 0x26, 0xe8, 0x19, 0xa5, 0x4f, 0x6a, 0x4c,
+	0x71, 0x74, 0x68, 0xd6, 0xce, 0xf3, 0x31, 0xd3, 0x9b, 0xac, 0x40, 0x09, 0xf7, 0x75, 0x7a, 0xed,
+	0x3d, 0xea, 0x61, 0x4c, 0x66, 0x6d, 0xe0, 0x53, 0xf7, 0x70, 0xc6, 0xba, 0x05, 0xf3, 0xef, 0xd3,
+	0x80, 0x91, 0xf6, 0x15, 0x1b, 0xa0, 0xef, 0x33, 0x35, 0xa2, 0x4c, 0xe7, 0x20, 0xdb, 0xf0, 0x9c,
+	0xfd, 0x00, 0xb1, 0x17, 0x6c, 0x31, 0xb0, 0xfe, 0x6d, 0xc0, 0x02, 0x63, 0xf5, 0x98, 0x11, 0xc5,
+	0x79, 0x59, 0x50, 0xde, 0xf7, 0xdc, 0x76, 0x2d, 0xc6, 0xb0, 0xc4, 0x27, 0x1f, 0x4b, 0xa6, 0xcb,
+	0x50, 0x0a, 0xdc, 0x01, 0x45, 0x0a, 0x29, 0x8a, 0x81, 0xab, 0xd6, 0xd9, 0xa1, 0x96, 0xe7, 0x1c,
+	0x13, 0x12, 0x33, 0xa6, 0x1c, 0x46, 0xec, 0x9c, 0x89, 0xda, 0xf9, 0x2b, 0x00, 0x83, 0x2c, 0x8e,
+	0x26, 0x2d, 0x55, 0xcd, 0x0d, 0x91, 0xa5, 0x37, 0x54, 0x1a, 0xdf, 0xb8, 0xc9, 0x49, 0xee, 0x32,
+	0x0a, 0xbb, 0xb8, 0xaf, 0x7e, 0x0e, 0x94, 0xcc, 0x85, 0x95, 0xec, 0xa1, 0x8e, 0x91, 0x78, 0x1f,
+	0x6f, 0x2f, 0x02, 0x19, 0x6e, 0x71, 0xa9, 0x13, 0xfe, 0x0e, 0x39, 0x3a, 0x1d, 0x71, 0x74, 0x5f,
+	0x6c, 0x26, 0x2c, 0x96, 0x9d, 0xb0, 0xb9, 0xf7, 0x1a, 0x8d, 0x47, 0xfd, 0x4c, 0x3b, 0x61, 0xa9,
+	0x23, 0x4c, 0x78, 0x09, 0x66, 0xe9, 0xf3, 0x2e, 0xad, 0xf3, 0x0b, 0x21, 0x9a, 0xba, 0x67, 0xd4,
+	0xbc, 0x4c, 0x1c, 0x09, 0x26, 0xfb, 0x97, 0x01, 0xa7, 0x6c, 0xca, 0xd8, 0xd1, 0xcf, 0x03, 0xfe,
+	0x39, 0x98, 0xf2, 0xe8, 0x7e, 0xaf, 0xc3, 0xc0, 0xf3, 0x1c, 0x87, 0xd0, 0x0b, 0x76, 0x49, 0xcc,
+	0x71, 0x40, 0xbe, 0x56, 0xc3, 0xdc, 0x18, 0x0d, 0xf3, 0x61, 0x0d, 0xef, 0x00, 0x70, 0x4e, 0x36,
+	0xf2, 0x0c, 0xc7, 0xb6, 0x11, 0x89, 0x6d, 0xb6, 0x59, 0x60, 0x10, 0x2a, 0x89, 0xc1, 0x9d, 0x4c,
+	0x21, 0x3d, 0x9b, 0xb1, 0xf3, 0x7b, 0x4e, 0xcb, 0xe9, 0xd4, 0xa9, 0xf5, 0x02, 0x16, 0x87, 0x8d,
+	0xe5, 0x77, 0xd9, 0xbb, 0x80, 0x92, 0x6b, 0x90, 0x17, 0xb8, 0x7d, 0x99, 0x1c, 0x97, 0x86, 0xd3,
+	0xd4, 0x00, 0x88, 0xad, 0x88, 0xc9, 0x05, 0x98, 0x69, 0x34, 0xfd, 0xba, 0xe3, 0x35, 0xa8, 0x32,
+	0x83, 0x80, 0x30, 0xdd, 0x9f, 0x46, 0x4b, 0x58, 0x7f, 0x30, 0xe0, 0xb4, 0x4d, 0x5d, 0x36, 0xe1,
+	0x0d, 0xc4, 0xfb, 0x13, 0x76, 0x16, 0x4b, 0x65, 0xca, 0x59, 0x3e, 0xf3, 0x16, 0x0f, 0xe5, 0x82,
+	0xf4, 0x96, 0xff, 0xbf, 0x9f, 0xb6, 0x3f, 0x19, 0x70, 0xe6, 0x86, 0x47, 0x9d, 0x80, 0x22, 0xf8,
+	0x57, 0x4b, 0x6c, 0xff, 0x2f, 0x21, 0xc3, 0x95, 0xd8, 0xa6, 0x2d, 0xfa, 0x85, 0x57, 0xe2, 0x61,
+	0xb7, 0xf1, 0xc5, 0xf6, 0xc4, 0x5f, 0x52, 0x50, 0x61, 0xf0, 0x3f, 0x47, 0x15, 0x62, 0xf7, 0x62,
+	0x36, 0x7e, 0x2f, 0xb2, 0x74, 0xc3, 0xd6, 0x51, 0x52, 0x0e, 0xd7, 0x72, 0x81, 0xcb, 0xef, 0x27,
+	0x1e, 0x67, 0x7c, 0x41, 0x88, 0xcb, 0x8b, 0x27, 0x03, 0x5b, 0x12, 0x02, 0x75, 0x86, 0x29, 0xe8,
+	0x0d, 0xb3, 0x01, 0x27, 0x19, 0x9f, 0x21, 0xea, 0x22, 0x52, 0x9f, 0x08, 0xdc, 0x9d, 0x24, 0x43,
+	0x42, 0xd8, 0x90, 0x7f, 0x4e, 0xc1, 0xd2, 0xee, 0x33, 0xa7, 0x1b, 0x37, 0xe4, 0xa4, 0xb3, 0xcb,
+	0x1a, 0x4c, 0xbb, 0xc1, 0x21, 0x4b, 0xbf, 0x7d, 0x66, 0xe2, 0xcd, 0x33, 0x85, 0xb3, 0xca, 0x68,
+	0x67, 0x00, 0x04, 0x15, 0xf2, 0x95, 0x36, 0xc5, 0x19, 0x34, 0x1d, 0xbb, 0x34, 0xe4, 0xb2, 0x10,
+	0x21, 0x1e, 0x5c, 0x25, 0x41, 0x90, 0x6c, 0xc0, 0xbc, 0xde, 0x80, 0x57, 0x61, 0x41, 0x70, 0x4b,
+	0xb0, 0xf8, 0x1c, 0xae, 0x26, 0x9a, 0xb1, 0x18, 0x09, 0xaa, 0x14, 0x9c, 0x50, 0xa6, 0xbb, 0xdf,
+	0xa5, 0x1e, 0x96, 0x8c, 0xe4, 0x36, 0xe4, 0xea, 0x98, 0xf3, 0xd0, 0x72, 0xa5, 0xea, 0xe6, 0xf0,
+	0xbd, 0x30, 0x32, 0x27, 0xde, 0x7a, 0xcd, 0x96, 0x0c, 0x38, 0xab, 0x06, 0x66, 0x1e, 0xf9, 0x12,
+	0xd6, 0xb0, 0x1a, 0x99, 0x99, 0x38, 0x2b, 0xc1, 0x80, 0xec, 0x40, 0xd1, 0x69, 0x88, 0x0b, 0xa7,
+	0x81, 0x5e, 0x2a, 0x55, 0xcf, 0x0f, 0x73, 0xd3, 0x3d, 0x6b, 0x18, 0x93, 0x82, 0x23, 0xe7, 0xc9,
+	0x0d, 0xc8, 0xf0, 0xfb, 0x10, 0xfd, 0x58, 0xaa, 0x5e, 0xd1, 0x96, 0xa1, 0x23, 0xd0, 0xe0, 0xe6,
+	0xeb, 0x25, 0x28, 0xba, 0xca, 0x5c, 0xd6, 0xa7, 0x06, 0x98, 0xd7, 0x9d, 0xa0, 0x7e, 0x28, 0xd2,
+	0x53, 0x3c, 0xa6, 0x6f, 0xb0, 0xc3, 0xa1, 0x68, 0xd5, 0x4d, 0xbb, 0xaa, 0x2b, 0x08, 0x62, 0x6e,
+	0xb0, 0x43, 0xdb, 0xb0, 0xd2, 0x72, 0x5a, 0x4d, 0xce, 0xbf, 0xe6, 0x76, 0x5a, 0x47, 0xf2, 0xad,
+	0x3c, 0xa5, 0x26, 0xef, 0xb3, 0xb9, 0x81, 0x8f, 0xd3, 0x61, 0x1f, 0x3b, 0x70, 0x12, 0xd1, 0xf5,
+	0x19, 0xef, 0x78, 0x9e, 0xeb, 0x91, 0xa5, 0x90, 0x0a, 0x32, 0x42, 0x06, 0x13, 0x3c, 0x46, 0xea,
+	0x6e, 0x83, 0xaa, 0x18, 0xe1, 0xbf, 0xf9, 0x93, 0xb9, 0x4d, 0x7d, 0xdf, 0x39, 0xa0, 0x32, 0x48,
+	0xd4, 0xd0, 0x7a, 0x0a, 0x15, 0xad, 0x01, 0xe4, 0x43, 0xe3, 0x5d, 0xc8, 0x51, 0x2e, 0x53, 0x69,
+	0xff, 0xfa, 0xb0, 0xf6, 0x1a, 0x84, 0xb6, 0xdc, 0xc4, 0xe5, 0x3a, 0xdd, 0x6e, 0xab, 0x49, 0x1b,
+	0x52, 0x6b, 0x35, 0xb4, 0x7e, 0x69, 0x40, 0xa9, 0x2f, 0x8d, 0x05, 0xda, 0x88, 0xa0, 0x1f, 0x04,
+	0x78, 0x2a, 0x12, 0xe0, 0x2a, 0x19, 0xa4, 0x43, 0xc9, 0x60, 0x44, 0xfa, 0x3c, 0x0f, 0x33, 0xa1,
+	0x66, 0x06, 0xbe, 0x39, 0xb2, 0xf8, 0xe6, 0x28, 0x0f, 0xda, 0x16, 0xec, 0xe1, 0x61, 0xfd, 0xc2,
+	0x80, 0xf9, 0xdb, 0xed, 0xae, 0xeb, 0x05, 0xf1, 0xe3, 0xf0, 0x0e, 0xe4, 0xf6, 0x5d, 0xaf, 0xed,
+	0x04, 0x88, 0x70, 0xba, 0x7a, 0x36, 0xf9, 0x28, 0xdc, 0x44, 0x3a, 0x5b, 0xd2, 0x73, 0xa8, 0xcc,
+	0xbc, 0x8e, 0x54, 0x00, 0x7f, 0xf3, 0x74, 0xdd, 0xf0, 0x8e, 0x6a, 0x5e, 0xaf, 0x23, 0x9d, 0x9e,
+	0x63, 0x43, 0xbb, 0x97, 0xf4, 0xf0, 0xff, 0x1a, 0x4c, 0x0b, 0x54, 0xcc, 0x5a, 0xe2, 0x18, 0xb0,
+	0xda, 0xd5, 0x63, 0xea, 0xcb, 0xda, 0x95, 0xfd, 0x0c, 0xbb, 0x39, 0x15, 0x75, 0xf3, 0x0f, 0x58,
+	0x49, 0x16, 0x57, 0x4a, 0xba, 0x98, 0x61, 0x63, 0x7b, 0x7d, 0xc9, 0x07, 0x7f, 0x73, 0x4d, 0xa5,
+	0xdb, 0x53, 0xe8, 0x76, 0x8d, 0xa6, 0x51, 0x30, 0x3a, 0x8f, 0xa7, 0xa3, 0x1e, 0x3f, 0x80, 0x79,
+	0x96, 0xd9, 0x26, 0x6a, 0x56, 0x7d, 0xf9, 0xb9, 0x0f, 0x0b, 0x71, 0x41, 0x52, 0xd5, 0x89, 0x3a,
+	0xd0, 0xaa, 0xc2, 0xfc, 0xcd, 0x66, 0x47, 0x1c, 0x1c, 0xec, 0x6e, 0x8c, 0x2f, 0xf7, 0xad, 0xbf,
+	0x1b, 0x50, 0xec, 0x6f, 0x98, 0xd4, 0xa1, 0x8f, 0xb4, 0xfc, 0x32, 0xb1, 0x96, 0xdf, 0x5b, 0xdc,
+	0xbd, 0x2d, 0x8a, 0x57, 0xdb, 0xb4, 0xae, 0x31, 0xd3, 0x87, 0x63, 0x33, 0x32, 0x1b, 0x89, 0x07,
+	0x05, 0x4a, 0x2e, 0x54, 0xa0, 0xe0, 0x49, 0x71, 0x3a, 0x4f, 0xf0, 0x76, 0xe3, 0x27, 0x85, 0xfd,
+	0xb6, 0xbe, 0x0e, 0x0b, 0x71, 0x23, 0x48, 0x63, 0x7f, 0x09, 0xb2, 0xd8, 0x65, 0x93, 0x99, 0xa3,
+	0x32, 0x4a, 0xb2, 0xa0, 0xb4, 0x7e, 0x6d, 0x00, 0x51, 0x0e, 0xd8, 0x6e, 0xee, 0xef, 0x53, 0x8f,
+	0xb2, 0x4a, 0x68, 0x52, 0x66, 0x62, 0x4a, 0x61, 0x51, 0x2f, 0x4d, 0x24, 0x06, 0x9c, 0xc3, 0x1e,
+	0x65, 0x5e, 0xa6, 0xaa, 0xcf, 0x22, 0x46, 0x9c, 0x9a, 0x1d, 0x26, 0xea, 0xc9, 0x2b, 0x5f, 0x0c,
+	0x2c, 0x02, 0xb3, 0x1c, 0xd8, 0x36, 0x3f, 0x68, 0xd2, 0xdd, 0xd6, 0xb7, 0xe1, 0x44, 0x68, 0x4e,
+	0x6a, 0x7f, 0x13, 0x4a, 0x8d, 0xbe, 0x06, 0xca, 0x06, 0x6b, 0xc9, 0xe7, 0x6d, 0xa0, 0xae, 0x1d,
+	0xde, 0x68, 0xcd, 0xc3, 0xc9, 0x0f, 0x7a, 0x7b, 0xad, 0xa6, 0x7f, 0x18, 0x91, 0xf9, 0x1d, 0x98,
+	0x8b, 0x4e, 0x4f, 0x58, 0xec, 0x1f, 0x0d, 0x00, 0xee, 0x99, 0x1b, 0x87, 0x4e, 0xe7, 0x60, 0x62,
+	0x1e, 0xb8, 0xd6, 0xb7, 0x75, 0xe6, 0x58, 0x0d, 0x54, 0xe5, 0x8b, 0xab, 0xca, 0x17, 0xd9, 0xe3,
+	0xf5, 0x5d, 0x85, 0xaf, 0x7e, 0x6f, 0xc0, 0xb4, 0x9a, 0x93, 0x7a, 0x4c, 0x43, 0x4a, 0xc6, 0x64,
+	0xda, 0x66, 0xbf, 0xc8, 0x57, 0xa1, 0x54, 0xc7, 0x15, 0x11, 0x3b, 0xa9, 0x84, 0xb6, 0xd0, 0x03,
+	0xd5, 0xdd, 0xb7, 0x41, 0x90, 0x63, 0x64, 0xf1, 0x13, 0x52, 0x0f, 0x5c, 0x4f, 0x5e, 0xa9, 0x62,
+	0xc0, 0xed, 0xd1, 0xa6, 0xc1, 0xa1, 0xab, 0x8e, 0x99, 0x1c, 0x91, 0xaa, 0x0a, 0x87, 0x6c, 0x52,
+	0xc1, 0x3e, 0xb0, 0xb7, 0x8a, 0x87, 0xdf, 0xa4, 0xc0, 0xfc, 0x46, 0xd3, 0x0f, 0xa2, 0x5a, 0x1c,
+	0xe7, 0xa1, 0xfc, 0x8a, 0x71, 0x21, 0xf4, 0xc8, 0x84, 0xf5, 0x78, 0x1b, 0x8a, 0xd8, 0xa9, 0x43,
+	0xc3, 0x64, 0xc7, 0x1a, 0xa6, 0xc0, 0x89, 0x65, 0xc2, 0xe1, 0x65, 0x48, 0xbf, 0x3d, 0x39, 0x7a,
+	0x1b, 0x2b, 0x51, 0x70, 0x13, 0x4f, 0x61, 0xec, 0x9e, 0xaa, 0xf9, 0xcd, 0xef, 0x53, 0x99, 0x5f,
+	0x0a, 0x7c, 0x62, 0x97, 0x8d, 0xf9, 0x1b, 0x1d, 0x17, 0x03, 0xf7, 0x09, 0x15, 0x4f, 0xe5, 0xa2,
+	0x8d, 0xe4, 0x0f, 0xf8, 0x84, 0xf5, 0x43, 0x03, 0x2a, 0x5a, 0x2b, 0xc9, 0x98, 0xd8, 0x82, 0xbc,
+	0xf0, 0x9a, 0x8a, 0x87, 0x11, 0x69, 0x5f, 0xda, 0x5f, 0x6d, 0xe0, 0x8f, 0x86, 0x0e, 0x7d, 0x1e,
+	0xd4, 0x42, 0xf2, 0x85, 0x41, 0xcb, 0x7c, 0xfa, 0x83, 0x3e, 0x06, 0x96, 0xb9, 0xe6, 0x78, 0x2c,
+	0x0d, 0x15, 0x33, 0x11, 0x33, 0x1a, 0x9f, 0xcd, 0x8c, 0xa9, 0x63, 0x9b, 0x31, 0xb9, 0xff, 0x6b,
+	0xd5, 0x60, 0x3e, 0x06, 0x70, 0xc2, 0x29, 0xe3, 0x4d, 0x38, 0xf1, 0xb0, 0xd3, 0x70, 0xa5, 0x05,
+	0xa5, 0xfa, 0xcc, 0xaf, 0x32, 0xc0, 0xfa, 0x71, 0x57, 0x10, 0x13, 0xec, 0x32, 0xfc, 0x10, 0x48,
+	0x78, 0xc7, 0x84, 0xf1, 0x78, 0xb0, 0xc0, 0x78, 0xb2, 0xa3, 0x3c, 0xf4, 0xac, 0x7f, 0x97, 0x77,
+	0x02, 0x71, 0xe5, 0xb8, 0x6e, 0x29, 0x49, 0xfa, 0x21, 0x23, 0xa7, 0xa2, 0x46, 0x76, 0x78, 0x83,
+	0x33, 0x26, 0x73, 0xb2, 0x6a, 0xad, 0xaf, 0x0e, 0x92, 0x9a, 0x78, 0xa3, 0x90, 0x3c, 0xa4, 0x6f,
+	0xec, 0x3e, 0x9a, 0x7d, 0x8d, 0x14, 0x20, 0x73, 0x67, 0xf7, 0xfe, 0xbd, 0x59, 0x63, 0xfd, 0x0d,
+	0x28, 0x47, 0xae, 0x75, 0x52, 0x82, 0xfc, 0xee, 0xad, 0xfb, 0x8f, 0x6f, 0xdf, 0x7b, 0x9f, 0xd1,
+	0x95, 0xa1, 0x78, 0xef, 0xfe, 0xdd, 0xdb, 0xf7, 0xde, 0x7b, 0xb0, 0xb3, 0x3d, 0x6b, 0x54, 0x7f,
+	0xbb, 0x28, 0x3e, 0xb5, 0x29, 0xb6, 0x1e, 0x69, 0x41, 0x29, 0xf4, 0x15, 0x83, 0xac, 0xe9, 0x9b,
+	0x8b, 0xd1, 0x8f, 0x1c, 0x66, 0xd2, 0x67, 0x41, 0x6b, 0xf9, 0xa3, 0x7f, 0xfc, 0xe7, 0x93, 0xd4,
+	0xe2, 0x96, 0xb1, 0x6e, 0x9d, 0xc4, 0xaf, 0x98, 0xea, 0xc3, 0x8e, 0xb7, 0xc9, 0xdf, 0x15, 0xc4,
+	0x87, 0x72, 0xa4, 0xf4, 0x23, 0xc7, 0xac, 0x0d, 0xcd, 0x85, 0x21, 0x8f, 0xed, 0xf0, 0xaf, 0xb8,
+	0x96, 0x85, 0x02, 0x97, 0xac, 0x53, 0x1a, 0x69, 0x5b, 0xac, 0x9a, 0x64, 0x48, 0xc8, 0xcf, 0x0d,
+	0x98, 0x8d, 0xb7, 0x57, 0xc9, 0xa5, 0x61, 0xc1, 0x09, 0xfd, 0x6a, 0x73, 0xfd, 0x38, 0xa4, 0xc2,
+	0xf5, 0xd6, 0xeb, 0x88, 0x67, 0xc5, 0x32, 0x75, 0x78, 0x3c, 0xdc, 0xc5, 0x21, 0xb1, 0x3c, 0x46,
+	0x86, 0x7b, 0xae, 0xe4, 0x0d, 0x9d, 0xa4, 0x84, 0xce, 0x6c, 0xa2, 0x49, 0xce, 0x23, 0x84, 0xb3,
+	0xdc, 0x07, 0x15, 0x3d, 0x0a, 0xe4, 0x48, 0x7e, 0xc4, 0xea, 0x04, 0x7d, 0x83, 0x80, 0xbc, 0x6a,
+	0x2b, 0x61, 0x9c, 0x7b, 0x38, 0x96, 0xb8, 0x87, 0xd4, 0x2f, 0xf2, 0x11, 0xc3, 0xa1, 0xef, 0x2e,
+	0x90, 0x57, 0xed, 0x43, 0x24, 0xe2, 0x58, 0x41, 0x1c, 0xa7, 0xd7, 0x13, 0x41, 0x70, 0x63, 0xe8,
+	0xfb, 0x96, 0x3a, 0x10, 0x23, 0x3b, 0x9c, 0xc7, 0x30, 0x86, 0x99, 0x88, 0xe3, 0xc7, 0xec, 0x72,
+	0xd1, 0xb5, 0x36, 0xc8, 0xab, 0xb5, 0x40, 0x12, 0x31, 0x5c, 0x40, 0x0c, 0xe7, 0xac, 0xa5, 0x04,
+	0x00, 0x5b, 0xea, 0x84, 0xfe, 0x84, 0x95, 0xc6, 0xda, 0xd6, 0x1d, 0xd9, 0xd0, 0x24, 0xb2, 0x11,
+	0x3d, 0xbe, 0xcf, 0x0e, 0xc5, 0x67, 0x5c, 0x39, 0x94, 0x4f, 0x0d, 0xd9, 0x1b, 0x89, 0x36, 0x2e,
+	0xc8, 0xe5, 0x84, 0x06, 0x85, 0xb6, 0xc1, 0x63, 0x5e, 0x39, 0x26, 0xb5, 0x0c, 0xe4, 0x8b, 0x88,
+	0xce, 0xe2, 0x27, 0xf7, 0x4c, 0x12, 0xc0, 0x3d, 0xbe, 0x9f, 0x5b, 0x6a, 0x3a, 0xfa, 0x35, 0x95,
+	0x5c, 0x18, 0x96, 0xa5, 0xfd, 0xde, 0x6a, 0x8e, 0xff, 0xe4, 0x6c, 0xad, 0x23, 0x90, 0x35, 0x62,
+	0x25, 0xa0, 0xd8, 0x7c, 0xa1, 0x6e, 0xa8, 0x97, 0x3c, 0xad, 0xcc, 0xc4, 0xbe, 0xc6, 0x92, 0x8b,
+	0x5a, 0x2c, 0x9a, 0x0f, 0xb6, 0xe6, 0xf2, 0xe8, 0xaf, 0xee, 0xd6, 0x1a, 0x22, 0x59, 0x26, 0x89,
+	0x0e, 0xe3, 0x40, 0xc8, 0x73, 0x84, 0x10, 0xf9, 0x3b, 0x15, 0x3d, 0x04, 0xcd, 0xf7, 0x54, 0x73,
+	0xcc, 0x73, 0xdf, 0xaa, 0x20, 0x84, 0x79, 0x12, 0xbf, 0x5c, 0xf0, 0x71, 0xfb, 0x33, 0x43, 0xf5,
+	0x4d, 0x46, 0x39, 0x42, 0xdb, 0xef, 0x31, 0x2f, 0x8e, 0x27, 0x94, 0x07, 0xe3, 0x12, 0x42, 0x58,
+	0xb5, 0x96, 0x93, 0xac, 0xd0, 0xc4, 0x7d, 0xfc, 0xe0, 0xfe, 0x94, 0x01, 0x8a, 0xb6, 0x27, 0x74,
+	0x80, 0xb4, 0x9d, 0x12, 0x1d, 0x20, 0x7d, 0xa7, 0x43, 0xe5, 0x7b, 0x92, 0x08, 0x88, 0xe2, 0x3e,
+	0xf2, 0x0c, 0x8a, 0xfd, 0xda, 0x95, 0x58, 0x1a, 0x5f, 0xc7, 0x8a, 0x5d, 0x73, 0x75, 0x24, 0x8d,
+	0x94, 0x7e, 0x0e, 0xa5, 0x57, 0xc8, 0xe9, 0x98, 0x74, 0xec, 0xcf, 0x6c, 0xf1, 0xd7, 0x0c, 0xcf,
+	0x69, 0x53, 0xe1, 0x0a, 0x96, 0x68, 0x3a, 0x8b, 0x9a, 0xc2, 0xd7, 0x3c, 0x3f, 0x8e, 0x4c, 0x42,
+	0x48, 0x4a, 0x24, 0x02, 0x42, 0x57, 0x6c, 0xe1, 0xfe, 0xf8, 0x10, 0xa6, 0xb6, 0xc5, 0xb7, 0x4f,
+	0x81, 0x23, 0x21, 0x33, 0x25, 0x66, 0xac, 0x25, 0x14, 0xb4, 0xb0, 0x3e, 0xa7, 0x13, 0x44, 0x3e,
+	0x66, 0x69, 0x4a, 0x53, 0x9b, 0xe8, 0xd2, 0x54, 0x72, 0xa1, 0xa7, 0x4b, 0x53, 0x23, 0x0a, 0x1e,
+	0xf5, 0xe0, 0x22, 0x0b, 0x31, 0x48, 0xaa, 0xa8, 0x61, 0xf7, 0x5a, 0x39, 0x52, 0x0b, 0xe8, 0x5e,
+	0x5c, 0xba, 0x6a, 0xc6, 0xbc, 0x30, 0x96, 0x4e, 0x42, 0x58, 0x45, 0x08, 0x67, 0x48, 0x45, 0x0f,
+	0x41, 0x9c, 0x01, 0x16, 0x0a, 0x30, 0x28, 0x00, 0x88, 0xe6, 0x68, 0x0d, 0x15, 0x14, 0xe6, 0xda,
+	0x68, 0x22, 0x29, 0xbe, 0x8a, 0xe2, 0x2f, 0x5b, 0x17, 0xf4, 0xe2, 0x37, 0x5f, 0xf4, 0x8b, 0x92,
+	0x97, 0x5b, 0x3d, 0xb6, 0x9d, 0x1f, 0x04, 0xe6, 0xaa, 0x99, 0xd8, 0xe3, 0x5d, 0x97, 0xa4, 0xf4,
+	0x35, 0x85, 0x79, 0xe9, 0x18, 0x94, 0x12, 0x9c, 0x4c, 0xde, 0xd6, 0x4a, 0x52, 0x6c, 0xca, 0x62,
+	0x83, 0x83, 0xfa, 0x84, 0x65, 0x8b, 0x68, 0x7f, 0x4d, 0x97, 0x2d, 0xb4, 0x6d, 0x48, 0x5d, 0xb6,
+	0xd0, 0xb7, 0xea, 0xac, 0x0d, 0x44, 0x74, 0x91, 0x9c, 0x8f, 0x21, 0x12, 0x7f, 0xf6, 0xb7, 0xf9,
+	0x42, 0x75, 0x33, 0x5f, 0x62, 0x4e, 0xf5, 0xaf, 0x97, 0xbe, 0x55, 0xec, 0x13, 0xed, 0xe5, 0x30,
+	0x20, 0xde, 0xfa, 0x2f, 0x35, 0xe7, 0x35, 0x8e, 0x75, 0x29, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ShowSchedulerClient is the client API for ShowScheduler service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type ShowSchedulerClient interface {
@@ -658,14 +3174,48 @@ type ShowSchedulerClient interface {
 	VoteUpMovie(ctx context.Context, in *VoteUpMovieRequest, opts ...grpc.CallOption) (*proto1.Movie, error)
 	// Adds a new movie to voted movies for a day's show. Requires authentication
 	AddVotedMovie(ctx context.Context, in *AddVotedMovieRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Removes a movie from voted movies for a day's show. Requires authentication
+	RemoveVotedMovie(ctx context.Context, in *RemoveVotedMovieRequest, opts ...grpc.CallOption) (*RemoveVotedMovieResponse, error)
+	// Reorders the voted movies for a day's show. Requires authentication
+	ReorderVotedMovies(ctx context.Context, in *ReorderVotedMoviesRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Creates schedule for a particular day and show. Requires authentication
 	CreateMovieDaySchedule(ctx context.Context, in *CreateMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Delete schedule for a particular show in a day. Requires authentication
 	DeleteMovieDaySchedule(ctx context.Context, in *DeleteMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Replaces the movie showing in a show. Requires authentication
+	UpdateMovieDaySchedule(ctx context.Context, in *UpdateMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Moves a show to an empty show on another day, screen or time. Requires authentication
+	MoveMovieDaySchedule(ctx context.Context, in *MoveMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Swaps the movies of two shows. Requires authentication
+	SwapMovieDaySchedules(ctx context.Context, in *SwapMovieDaySchedulesRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Applies create, delete, add voted and move operations all together or not at all. Requires authentication
+	BatchUpdateSchedule(ctx context.Context, in *BatchUpdateScheduleRequest, opts ...grpc.CallOption) (*BatchUpdateScheduleResponse, error)
 	// Retrieves day schedule for a particular week day
 	GetDaySchedule(ctx context.Context, in *GetDayScheduleRequest, opts ...grpc.CallOption) (*ScreensSchedule, error)
+	// Retrieves the schedule for the week, optionally filtered by days, screens and movie
+	GetWeekSchedule(ctx context.Context, in *GetWeekScheduleRequest, opts ...grpc.CallOption) (*DaysSchedule, error)
 	// Retrieves show for a particular week day and screen
 	GetShowSchedule(ctx context.Context, in *GetShowScheduleRequest, opts ...grpc.CallOption) (*ShowSchedule, error)
+	// Imports shows for the week from CSV or JSON. Requires authentication
+	ImportSchedule(ctx context.Context, in *ImportScheduleRequest, opts ...grpc.CallOption) (*ImportScheduleResponse, error)
+	// Exports shows for the week as CSV or JSON. Requires authentication
+	ExportSchedule(ctx context.Context, in *ExportScheduleRequest, opts ...grpc.CallOption) (*ExportScheduleResponse, error)
+	// Compares the draft with the published schedule. Requires the programmer role
+	DiffDraft(ctx context.Context, in *DiffDraftRequest, opts ...grpc.CallOption) (*DiffDraftResponse, error)
+	// Replaces the published schedule with the draft. Requires the programmer role
+	PublishDraft(ctx context.Context, in *PublishDraftRequest, opts ...grpc.CallOption) (*PublishDraftResponse, error)
+	// Discards the draft. Requires the programmer role
+	DiscardDraft(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	// Lists changes made to the published schedule, newest first. Requires authentication
+	ListScheduleChanges(ctx context.Context, in *ListScheduleChangesRequest, opts ...grpc.CallOption) (*ListScheduleChangesResponse, error)
+	// Compares the published schedule at two points in time. Requires authentication
+	DiffSchedules(ctx context.Context, in *DiffSchedulesRequest, opts ...grpc.CallOption) (*DiffSchedulesResponse, error)
+	// Reverts a recorded change if the shows it changed have not changed since. Requires the programmer or admin role
+	UndoChange(ctx context.Context, in *UndoChangeRequest, opts ...grpc.CallOption) (*UndoChangeResponse, error)
+	// Rolls the published schedule for a day or the week back to a point in time. Requires the admin role
+	RestoreSchedule(ctx context.Context, in *RestoreScheduleRequest, opts ...grpc.CallOption) (*RestoreScheduleResponse, error)
+	// Finds every show a movie is scheduled or nominated in for the week
+	FindMovieShows(ctx context.Context, in *FindMovieShowsRequest, opts ...grpc.CallOption) (*FindMovieShowsResponse, error)
 }
 
 type showSchedulerClient struct {
@@ -694,6 +3244,24 @@ func (c *showSchedulerClient) AddVotedMovie(ctx context.Context, in *AddVotedMov
 	return out, nil
 }
 
+func (c *showSchedulerClient) RemoveVotedMovie(ctx context.Context, in *RemoveVotedMovieRequest, opts ...grpc.CallOption) (*RemoveVotedMovieResponse, error) {
+	out := new(RemoveVotedMovieResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/RemoveVotedMovie", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) ReorderVotedMovies(ctx context.Context, in *ReorderVotedMoviesRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/ReorderVotedMovies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *showSchedulerClient) CreateMovieDaySchedule(ctx context.Context, in *CreateMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/CreateMovieDaySchedule", in, out, opts...)
@@ -712,6 +3280,42 @@ func (c *showSchedulerClient) DeleteMovieDaySchedule(ctx context.Context, in *De
 	return out, nil
 }
 
+func (c *showSchedulerClient) UpdateMovieDaySchedule(ctx context.Context, in *UpdateMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/UpdateMovieDaySchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) MoveMovieDaySchedule(ctx context.Context, in *MoveMovieDayScheduleRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/MoveMovieDaySchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) SwapMovieDaySchedules(ctx context.Context, in *SwapMovieDaySchedulesRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/SwapMovieDaySchedules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) BatchUpdateSchedule(ctx context.Context, in *BatchUpdateScheduleRequest, opts ...grpc.CallOption) (*BatchUpdateScheduleResponse, error) {
+	out := new(BatchUpdateScheduleResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/BatchUpdateSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *showSchedulerClient) GetDaySchedule(ctx context.Context, in *GetDayScheduleRequest, opts ...grpc.CallOption) (*ScreensSchedule, error) {
 	out := new(ScreensSchedule)
 	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/GetDaySchedule", in, out, opts...)
@@ -721,6 +3325,15 @@ func (c *showSchedulerClient) GetDaySchedule(ctx context.Context, in *GetDaySche
 	return out, nil
 }
 
+func (c *showSchedulerClient) GetWeekSchedule(ctx context.Context, in *GetWeekScheduleRequest, opts ...grpc.CallOption) (*DaysSchedule, error) {
+	out := new(DaysSchedule)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/GetWeekSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *showSchedulerClient) GetShowSchedule(ctx context.Context, in *GetShowScheduleRequest, opts ...grpc.CallOption) (*ShowSchedule, error) {
 	out := new(ShowSchedule)
 	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/GetShowSchedule", in, out, opts...)
@@ -730,20 +3343,144 @@ func (c *showSchedulerClient) GetShowSchedule(ctx context.Context, in *GetShowSc
 	return out, nil
 }
 
+func (c *showSchedulerClient) ImportSchedule(ctx context.Context, in *ImportScheduleRequest, opts ...grpc.CallOption) (*ImportScheduleResponse, error) {
+	out := new(ImportScheduleResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/ImportSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) ExportSchedule(ctx context.Context, in *ExportScheduleRequest, opts ...grpc.CallOption) (*ExportScheduleResponse, error) {
+	out := new(ExportScheduleResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/ExportSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) DiffDraft(ctx context.Context, in *DiffDraftRequest, opts ...grpc.CallOption) (*DiffDraftResponse, error) {
+	out := new(DiffDraftResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/DiffDraft", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) PublishDraft(ctx context.Context, in *PublishDraftRequest, opts ...grpc.CallOption) (*PublishDraftResponse, error) {
+	out := new(PublishDraftResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/PublishDraft", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) DiscardDraft(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/DiscardDraft", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) ListScheduleChanges(ctx context.Context, in *ListScheduleChangesRequest, opts ...grpc.CallOption) (*ListScheduleChangesResponse, error) {
+	out := new(ListScheduleChangesResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/ListScheduleChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) DiffSchedules(ctx context.Context, in *DiffSchedulesRequest, opts ...grpc.CallOption) (*DiffSchedulesResponse, error) {
+	out := new(DiffSchedulesResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/DiffSchedules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) UndoChange(ctx context.Context, in *UndoChangeRequest, opts ...grpc.CallOption) (*UndoChangeResponse, error) {
+	out := new(UndoChangeResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/UndoChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) RestoreSchedule(ctx context.Context, in *RestoreScheduleRequest, opts ...grpc.CallOption) (*RestoreScheduleResponse, error) {
+	out := new(RestoreScheduleResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/RestoreSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showSchedulerClient) FindMovieShows(ctx context.Context, in *FindMovieShowsRequest, opts ...grpc.CallOption) (*FindMovieShowsResponse, error) {
+	out := new(FindMovieShowsResponse)
+	err := c.cc.Invoke(ctx, "/rupacinema.movie.ShowScheduler/FindMovieShows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShowSchedulerServer is the server API for ShowScheduler service.
 type ShowSchedulerServer interface {
 	// Votes for a movie to be played at cinema. Requires authentication
 	VoteUpMovie(context.Context, *VoteUpMovieRequest) (*proto1.Movie, error)
 	// Adds a new movie to voted movies for a day's show. Requires authentication
 	AddVotedMovie(context.Context, *AddVotedMovieRequest) (*empty.Empty, error)
+	// Removes a movie from voted movies for a day's show. Requires authentication
+	RemoveVotedMovie(context.Context, *RemoveVotedMovieRequest) (*RemoveVotedMovieResponse, error)
+	// Reorders the voted movies for a day's show. Requires authentication
+	ReorderVotedMovies(context.Context, *ReorderVotedMoviesRequest) (*empty.Empty, error)
 	// Creates schedule for a particular day and show. Requires authentication
 	CreateMovieDaySchedule(context.Context, *CreateMovieDayScheduleRequest) (*empty.Empty, error)
 	// Delete schedule for a particular show in a day. Requires authentication
 	DeleteMovieDaySchedule(context.Context, *DeleteMovieDayScheduleRequest) (*empty.Empty, error)
+	// Replaces the movie showing in a show. Requires authentication
+	UpdateMovieDaySchedule(context.Context, *UpdateMovieDayScheduleRequest) (*empty.Empty, error)
+	// Moves a show to an empty show on another day, screen or time. Requires authentication
+	MoveMovieDaySchedule(context.Context, *MoveMovieDayScheduleRequest) (*empty.Empty, error)
+	// Swaps the movies of two shows. Requires authentication
+	SwapMovieDaySchedules(context.Context, *SwapMovieDaySchedulesRequest) (*empty.Empty, error)
+	// Applies create, delete, add voted and move operations all together or not at all. Requires authentication
+	BatchUpdateSchedule(context.Context, *BatchUpdateScheduleRequest) (*BatchUpdateScheduleResponse, error)
 	// Retrieves day schedule for a particular week day
 	GetDaySchedule(context.Context, *GetDayScheduleRequest) (*ScreensSchedule, error)
+	// Retrieves the schedule for the week, optionally filtered by days, screens and movie
+	GetWeekSchedule(context.Context, *GetWeekScheduleRequest) (*DaysSchedule, error)
 	// Retrieves show for a particular week day and screen
 	GetShowSchedule(context.Context, *GetShowScheduleRequest) (*ShowSchedule, error)
+	// Imports shows for the week from CSV or JSON. Requires authentication
+	ImportSchedule(context.Context, *ImportScheduleRequest) (*ImportScheduleResponse, error)
+	// Exports shows for the week as CSV or JSON. Requires authentication
+	ExportSchedule(context.Context, *ExportScheduleRequest) (*ExportScheduleResponse, error)
+	// Compares the draft with the published schedule. Requires the programmer role
+	DiffDraft(context.Context, *DiffDraftRequest) (*DiffDraftResponse, error)
+	// Replaces the published schedule with the draft. Requires the programmer role
+	PublishDraft(context.Context, *PublishDraftRequest) (*PublishDraftResponse, error)
+	// Discards the draft. Requires the programmer role
+	DiscardDraft(context.Context, *empty.Empty) (*empty.Empty, error)
+	// Lists changes made to the published schedule, newest first. Requires authentication
+	ListScheduleChanges(context.Context, *ListScheduleChangesRequest) (*ListScheduleChangesResponse, error)
+	// Compares the published schedule at two points in time. Requires authentication
+	DiffSchedules(context.Context, *DiffSchedulesRequest) (*DiffSchedulesResponse, error)
+	// Reverts a recorded change if the shows it changed have not changed since. Requires the programmer or admin role
+	UndoChange(context.Context, *UndoChangeRequest) (*UndoChangeResponse, error)
+	// Rolls the published schedule for a day or the week back to a point in time. Requires the admin role
+	RestoreSchedule(context.Context, *RestoreScheduleRequest) (*RestoreScheduleResponse, error)
+	// Finds every show a movie is scheduled or nominated in for the week
+	FindMovieShows(context.Context, *FindMovieShowsRequest) (*FindMovieShowsResponse, error)
 }
 
 func RegisterShowSchedulerServer(s *grpc.Server, srv ShowSchedulerServer) {
@@ -786,6 +3523,42 @@ func _ShowScheduler_AddVotedMovie_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShowScheduler_RemoveVotedMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveVotedMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).RemoveVotedMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/RemoveVotedMovie",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).RemoveVotedMovie(ctx, req.(*RemoveVotedMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_ReorderVotedMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderVotedMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).ReorderVotedMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/ReorderVotedMovies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).ReorderVotedMovies(ctx, req.(*ReorderVotedMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShowScheduler_CreateMovieDaySchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateMovieDayScheduleRequest)
 	if err := dec(in); err != nil {
@@ -822,6 +3595,78 @@ func _ShowScheduler_DeleteMovieDaySchedule_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShowScheduler_UpdateMovieDaySchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMovieDayScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).UpdateMovieDaySchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/UpdateMovieDaySchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).UpdateMovieDaySchedule(ctx, req.(*UpdateMovieDayScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_MoveMovieDaySchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveMovieDayScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).MoveMovieDaySchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/MoveMovieDaySchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).MoveMovieDaySchedule(ctx, req.(*MoveMovieDayScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_SwapMovieDaySchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapMovieDaySchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).SwapMovieDaySchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/SwapMovieDaySchedules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).SwapMovieDaySchedules(ctx, req.(*SwapMovieDaySchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_BatchUpdateSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).BatchUpdateSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/BatchUpdateSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).BatchUpdateSchedule(ctx, req.(*BatchUpdateScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShowScheduler_GetDaySchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetDayScheduleRequest)
 	if err := dec(in); err != nil {
@@ -840,6 +3685,24 @@ func _ShowScheduler_GetDaySchedule_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShowScheduler_GetWeekSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWeekScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).GetWeekSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/GetWeekSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).GetWeekSchedule(ctx, req.(*GetWeekScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShowScheduler_GetShowSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetShowScheduleRequest)
 	if err := dec(in); err != nil {
@@ -858,6 +3721,186 @@ func _ShowScheduler_GetShowSchedule_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShowScheduler_ImportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).ImportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/ImportSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).ImportSchedule(ctx, req.(*ImportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_ExportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).ExportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/ExportSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).ExportSchedule(ctx, req.(*ExportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_DiffDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffDraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).DiffDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/DiffDraft",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).DiffDraft(ctx, req.(*DiffDraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_PublishDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishDraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).PublishDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/PublishDraft",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).PublishDraft(ctx, req.(*PublishDraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_DiscardDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).DiscardDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/DiscardDraft",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).DiscardDraft(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_ListScheduleChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduleChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).ListScheduleChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/ListScheduleChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).ListScheduleChanges(ctx, req.(*ListScheduleChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_DiffSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).DiffSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/DiffSchedules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).DiffSchedules(ctx, req.(*DiffSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_UndoChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).UndoChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/UndoChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).UndoChange(ctx, req.(*UndoChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_RestoreSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).RestoreSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/RestoreSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).RestoreSchedule(ctx, req.(*RestoreScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowScheduler_FindMovieShows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindMovieShowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowSchedulerServer).FindMovieShows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rupacinema.movie.ShowScheduler/FindMovieShows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowSchedulerServer).FindMovieShows(ctx, req.(*FindMovieShowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ShowScheduler_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rupacinema.movie.ShowScheduler",
 	HandlerType: (*ShowSchedulerServer)(nil),
@@ -870,6 +3913,14 @@ var _ShowScheduler_serviceDesc = grpc.ServiceDesc{
 			MethodName: "AddVotedMovie",
 			Handler:    _ShowScheduler_AddVotedMovie_Handler,
 		},
+		{
+			MethodName: "RemoveVotedMovie",
+			Handler:    _ShowScheduler_RemoveVotedMovie_Handler,
+		},
+		{
+			MethodName: "ReorderVotedMovies",
+			Handler:    _ShowScheduler_ReorderVotedMovies_Handler,
+		},
 		{
 			MethodName: "CreateMovieDaySchedule",
 			Handler:    _ShowScheduler_CreateMovieDaySchedule_Handler,
@@ -878,14 +3929,74 @@ var _ShowScheduler_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteMovieDaySchedule",
 			Handler:    _ShowScheduler_DeleteMovieDaySchedule_Handler,
 		},
+		{
+			MethodName: "UpdateMovieDaySchedule",
+			Handler:    _ShowScheduler_UpdateMovieDaySchedule_Handler,
+		},
+		{
+			MethodName: "MoveMovieDaySchedule",
+			Handler:    _ShowScheduler_MoveMovieDaySchedule_Handler,
+		},
+		{
+			MethodName: "SwapMovieDaySchedules",
+			Handler:    _ShowScheduler_SwapMovieDaySchedules_Handler,
+		},
+		{
+			MethodName: "BatchUpdateSchedule",
+			Handler:    _ShowScheduler_BatchUpdateSchedule_Handler,
+		},
 		{
 			MethodName: "GetDaySchedule",
 			Handler:    _ShowScheduler_GetDaySchedule_Handler,
 		},
+		{
+			MethodName: "GetWeekSchedule",
+			Handler:    _ShowScheduler_GetWeekSchedule_Handler,
+		},
 		{
 			MethodName: "GetShowSchedule",
 			Handler:    _ShowScheduler_GetShowSchedule_Handler,
 		},
+		{
+			MethodName: "ImportSchedule",
+			Handler:    _ShowScheduler_ImportSchedule_Handler,
+		},
+		{
+			MethodName: "ExportSchedule",
+			Handler:    _ShowScheduler_ExportSchedule_Handler,
+		},
+		{
+			MethodName: "DiffDraft",
+			Handler:    _ShowScheduler_DiffDraft_Handler,
+		},
+		{
+			MethodName: "PublishDraft",
+			Handler:    _ShowScheduler_PublishDraft_Handler,
+		},
+		{
+			MethodName: "DiscardDraft",
+			Handler:    _ShowScheduler_DiscardDraft_Handler,
+		},
+		{
+			MethodName: "ListScheduleChanges",
+			Handler:    _ShowScheduler_ListScheduleChanges_Handler,
+		},
+		{
+			MethodName: "DiffSchedules",
+			Handler:    _ShowScheduler_DiffSchedules_Handler,
+		},
+		{
+			MethodName: "UndoChange",
+			Handler:    _ShowScheduler_UndoChange_Handler,
+		},
+		{
+			MethodName: "RestoreSchedule",
+			Handler:    _ShowScheduler_RestoreSchedule_Handler,
+		},
+		{
+			MethodName: "FindMovieShows",
+			Handler:    _ShowScheduler_FindMovieShows_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "schedule.proto",
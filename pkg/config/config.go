@@ -2,43 +2,392 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// Config contains configuration variables for service
+// Config contains configuration variables for service.
+//
+// Every field is described once by its tags: yaml and toml keys for the config file,
+// env for the environment variable, flag for the command line flag, a default value and usage text.
 type Config struct {
+	// ConfigFile is the YAML or TOML file the configuration was read from, if any
+	ConfigFile string `yaml:"-" toml:"-" flag:"config" usage:"Path to YAML or TOML config file"`
+
 	// gRPC server start parameters section
 	// gRPC is TCP port to listen by gRPC server
-	GRPCPort string
+	GRPCPort string `yaml:"grpc_port" toml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port" default:":5600" usage:"gRPC port to bind"`
+	// RESTPort is TCP port to listen by the REST gateway. It shares GRPCPort if empty
+	RESTPort string `yaml:"rest_port" toml:"rest_port" env:"REST_PORT" flag:"rest-port" usage:"REST gateway port to bind, shares the gRPC port if empty"`
+	// AdminPort is TCP port to listen by the internal health and metrics server. Served on the REST port if empty
+	AdminPort string `yaml:"admin_port" toml:"admin_port" env:"ADMIN_PORT" flag:"admin-port" usage:"Plaintext admin port for health probes and metrics, served on the REST port if empty"`
+	// AdminHost is the interface AdminPort binds to, such as a private address. All interfaces if empty
+	AdminHost string `yaml:"admin_host" toml:"admin_host" env:"ADMIN_HOST" flag:"admin-host" usage:"Interface the admin port binds to, all interfaces if empty"`
+	// GatewayUpstream is the address the REST gateway dials the gRPC server on. The gRPC port on localhost if empty
+	GatewayUpstream string `yaml:"gateway_upstream" toml:"gateway_upstream" env:"GATEWAY_UPSTREAM" flag:"gateway-upstream" usage:"Address of the gRPC server for the REST gateway, the gRPC port on localhost if empty"`
+	// ShutdownTimeout is how long in-flight requests are given to finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"30s" usage:"Time given to in-flight requests to finish on shutdown"`
 
 	// Logging section
 	// LogLevel id global loge Level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)
-	LogLevel int
+	LogLevel int `yaml:"log_level" toml:"log_level" env:"LOG_LEVEL" flag:"log-level" default:"0" usage:"Global log level"`
 	// LogTimeFormat id print time format for logger e.g 2006-01-02T15:04:05Z07:00
-	LogTimeFormat string
+	LogTimeFormat string `yaml:"log_time_format" toml:"log_time_format" env:"LOG_TIME_FORMAT" flag:"log-time-format" default:"2006-01-02T15:04:05Z07:00" usage:"Print time format for logger e.g 2006-01-02T15:04:05Z07:00"`
 
 	// Certificates and Key section
 	// Path to Certificate
-	TLSCertPath string
-	TLSKeyPath  string
+	TLSCertPath string `yaml:"tls_cert" toml:"tls_cert" env:"TLS_CERT_PATH" flag:"tls-cert" default:"certs/cert.pem" usage:"Path to TLS certificate for the service"`
+	TLSKeyPath  string `yaml:"tls_key" toml:"tls_key" env:"TLS_KEY_PATH" flag:"tls-key" default:"certs/key.pem" usage:"Path to Private key for the service"`
+	// TLSClientAuth is optional, to verify client certificates when given, or require, for strict mutual TLS
+	// with gRPC clients. REST clients are never required to present a certificate
+	TLSClientAuth string `yaml:"tls_client_auth" toml:"tls_client_auth" env:"TLS_CLIENT_AUTH" flag:"tls-client-auth" default:"optional" usage:"Client certificates: optional or require"`
+	// Path to CA bundle that signs client certificates. The service certificate is used if empty
+	TLSClientCAPath string `yaml:"tls_client_ca" toml:"tls_client_ca" env:"TLS_CLIENT_CA_PATH" flag:"tls-client-ca" usage:"Path to CA bundle for verifying client certificates"`
+
+	// Insecure serves gRPC and REST over plaintext h2c and dials upstream services without TLS.
+	// It is meant for local development and tests only
+	Insecure bool `yaml:"insecure" toml:"insecure" env:"INSECURE" flag:"insecure" default:"false" usage:"Serve over plaintext h2c and dial upstream services without TLS (development only)"`
 
 	// External services section
 	// Movie service
-	MovieAPIAddress  string
-	MovieAPIPort     string
-	MovieAPICertPath string
+	MovieAPIAddress string `yaml:"movie_host" toml:"movie_host" env:"MOVIE_ADDRESS" flag:"movie-host" default:"localhost" usage:"Address of the movie service"`
+	MovieAPIPort    string `yaml:"movie_port" toml:"movie_port" env:"MOVIE_PORT" flag:"movie-port" default:":5540" usage:"Port where the movie service is running"`
+	MovieAPICAPath  string `yaml:"movie_ca" toml:"movie_ca" env:"MOVIE_CA_PATH" flag:"movie-ca" default:"certs/cert.pem" usage:"Path to CA bundle for verifying the movie service"`
+	// Deprecated: MovieAPICertPath is the former name of MovieAPICAPath, used when that is not set
+	MovieAPICertPath string `yaml:"movie_cert" toml:"movie_cert" env:"MOVIE_CERT_PATH" flag:"movie-cert" usage:"Deprecated, use movie-ca"`
 
 	// Account service
-	AccountServiceAddress  string
-	AccountServicePort     string
-	AccountServiceCertPath string
+	AccountServiceAddress string `yaml:"account_host" toml:"account_host" env:"ACCOUNT_ADDRESS" flag:"account-host" default:"localhost" usage:"Address of the account service"`
+	AccountServicePort    string `yaml:"account_port" toml:"account_port" env:"ACCOUNT_PORT" flag:"account-port" default:":5540" usage:"Port where the account service is running"`
+	AccountServiceCAPath  string `yaml:"account_ca" toml:"account_ca" env:"ACCOUNT_CA_PATH" flag:"account-ca" default:"certs/cert.pem" usage:"Path to CA bundle for verifying the account service"`
+	// Deprecated: AccountServiceCertPath is the former name of AccountServiceCAPath, used when that is not set
+	AccountServiceCertPath string `yaml:"account_cert" toml:"account_cert" env:"ACCOUNT_CERT_PATH" flag:"account-cert" usage:"Deprecated, use account-ca"`
+
+	// Authentication section
+	// AuthMode is either remote, to authenticate with account service, or local, to verify JWT locally
+	AuthMode string `yaml:"auth_mode" toml:"auth_mode" env:"AUTH_MODE" flag:"auth-mode" default:"remote" usage:"Authentication mode: remote (account service) or local (JWT verification)"`
+	// Path to PEM encoded public key used to verify tokens in local mode
+	JWTPublicKeyPath string `yaml:"jwt_public_key" toml:"jwt_public_key" env:"JWT_PUBLIC_KEY_PATH" flag:"jwt-public-key" usage:"Path to PEM public key for verifying tokens in local auth mode"`
+	// Path to JSON Web Key Set used to verify tokens in local mode
+	JWKSPath string `yaml:"jwks" toml:"jwks" env:"JWKS_PATH" flag:"jwks" usage:"Path to JWKS file for verifying tokens in local auth mode"`
+
+	// Tracing section
+	// TraceExporter is where spans are exported: otlp, stdout or none
+	TraceExporter string `yaml:"trace_exporter" toml:"trace_exporter" env:"TRACE_EXPORTER" flag:"trace-exporter" default:"otlp" usage:"Exporter for trace spans: otlp, stdout or none"`
+	// OTLPEndpoint is address of the OTLP collector e.g localhost:4317
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"OTLP_ENDPOINT" flag:"otlp-endpoint" default:"localhost:4317" usage:"Address of the OTLP collector"`
+
+	// Store section
+	// StoreBackend is where the weekly schedule is persisted: file or memory
+	StoreBackend string `yaml:"store_backend" toml:"store_backend" env:"STORE_BACKEND" flag:"store-backend" default:"file" usage:"Where the schedule is persisted: file or memory"`
+	// SnapshotPath is the file the weekly schedule is saved to by the file backend
+	SnapshotPath string `yaml:"snapshot_path" toml:"snapshot_path" env:"SNAPSHOT_PATH" flag:"snapshot-path" default:"snapshot" usage:"Path to the schedule snapshot file"`
+
+	// Audit section
+	// AuditLogPath is the file audit records of authenticated mutations are appended to. Auditing is off if empty
+	AuditLogPath string `yaml:"audit_log_path" toml:"audit_log_path" env:"AUDIT_LOG_PATH" flag:"audit-log-path" default:"audit.log" usage:"Path to the audit log of authenticated mutations, auditing is off if empty"`
+	// AuditLogMaxSize is the size in megabytes at which the audit log is rotated
+	AuditLogMaxSize int `yaml:"audit_log_max_size" toml:"audit_log_max_size" env:"AUDIT_LOG_MAX_SIZE" flag:"audit-log-max-size" default:"100" usage:"Size in megabytes at which the audit log is rotated"`
+	// AuditLogMaxFiles is the number of rotated audit logs kept, the oldest are removed first. All are kept if 0
+	AuditLogMaxFiles int `yaml:"audit_log_max_files" toml:"audit_log_max_files" env:"AUDIT_LOG_MAX_FILES" flag:"audit-log-max-files" default:"10" usage:"Number of rotated audit logs kept, all if 0"`
+	// AuditTrustedProxies are addresses or CIDR ranges of proxies whose forwarded client address is audited
+	// instead of theirs. The REST gateway dials the service over loopback unless gateway_upstream is set
+	AuditTrustedProxies []string `yaml:"audit_trusted_proxies" toml:"audit_trusted_proxies" env:"AUDIT_TRUSTED_PROXIES" flag:"audit-trusted-proxies" default:"127.0.0.0/8,::1" usage:"Comma separated addresses or CIDR ranges of proxies trusted to forward client addresses"`
+
+	// Schedule section
+	// Screens available at the cinema
+	Screens []string `yaml:"screens" toml:"screens" env:"SCREENS" flag:"screens" default:"Screen 1" usage:"Comma separated list of screens"`
+	// Showtimes are the shows played on every screen each day
+	Showtimes Showtimes `yaml:"showtimes" toml:"showtimes" env:"SHOWTIMES" flag:"showtimes" default:"1=11am,2=3pm,3=6pm,4=9pm" usage:"Comma separated shows as number=playtime e.g 1=11am,2=3pm"`
+	// TimeZone of the cinema, used for show times in calendar feeds
+	TimeZone string `yaml:"time_zone" toml:"time_zone" env:"TIME_ZONE" flag:"time-zone" default:"UTC" usage:"IANA time zone of the cinema e.g Africa/Nairobi"`
+	// MaxMoviesVoted is how many movies can be nominated for a show
+	MaxMoviesVoted int `yaml:"max_movies_voted" toml:"max_movies_voted" env:"MAX_MOVIES_VOTED" flag:"max-movies-voted" default:"4" usage:"Maximum number of voted movies in a show"`
+	// HistorySize is how many changes to the schedule are kept in the change history
+	HistorySize int `yaml:"history_size" toml:"history_size" env:"HISTORY_SIZE" flag:"history-size" default:"10000" usage:"Number of schedule changes kept in the change history, 0 to keep none"`
+	// HistoryArchiveSize is how many changes dropped from the change history are archived with the snapshot
+	HistoryArchiveSize int `yaml:"history_archive_size" toml:"history_archive_size" env:"HISTORY_ARCHIVE_SIZE" flag:"history-archive-size" default:"100000" usage:"Number of schedule changes dropped from the change history kept in its archive for restores, 0 to keep none"`
+
+	// Intervals section
+	// SnapshotInterval is how often the weekly schedule is saved
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" toml:"snapshot_interval" env:"SNAPSHOT_INTERVAL" flag:"snapshot-interval" default:"5m" usage:"How often the schedule is saved"`
+	// MovieRefreshInterval is how often movies in the schedule are refreshed from the movie service
+	MovieRefreshInterval time.Duration `yaml:"movie_refresh_interval" toml:"movie_refresh_interval" env:"MOVIE_REFRESH_INTERVAL" flag:"movie-refresh-interval" default:"20m" usage:"How often movies are refreshed from the movie service"`
+	// IdempotencyWindow is how long results of requests made with an idempotency key are replayed
+	IdempotencyWindow time.Duration `yaml:"idempotency_window" toml:"idempotency_window" env:"IDEMPOTENCY_WINDOW" flag:"idempotency-window" default:"24h" usage:"How long results of requests with an idempotency key are replayed"`
+	// IdempotencyMaxKeys is how many results of requests made with an idempotency key are kept at most
+	IdempotencyMaxKeys int `yaml:"idempotency_max_keys" toml:"idempotency_max_keys" env:"IDEMPOTENCY_MAX_KEYS" flag:"idempotency-max-keys" default:"100000" usage:"Maximum number of results of requests with an idempotency key kept, the oldest are dropped first"`
+
+	// command line arguments the configuration was loaded with
+	args []string
+	// deprecated settings that were used
+	deprecated []string
+}
+
+// Showtime is a show played at a particular time of day
+type Showtime struct {
+	ID       int32  `yaml:"id" toml:"id"`
+	PlayTime string `yaml:"play_time" toml:"play_time"`
 }
 
-// Parse validates configuration data
-func (cfg *Config) Parse() error {
-	if strings.Trim(cfg.GRPCPort, " ") == "" {
-		return fmt.Errorf("TCP port for gRPC server is required")
+// Showtimes is a list of shows. In flags and environment variables it is written as 1=11am,2=3pm
+type Showtimes []Showtime
+
+// String formats the showtimes as number=playtime pairs
+func (showtimes *Showtimes) String() string {
+	pairs := make([]string, 0, len(*showtimes))
+	for _, showtime := range *showtimes {
+		pairs = append(pairs, fmt.Sprintf("%d=%s", showtime.ID, showtime.PlayTime))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set parses showtimes written as number=playtime pairs
+func (showtimes *Showtimes) Set(val string) error {
+	parsed := make(Showtimes, 0)
+	for _, pair := range strings.Split(val, ",") {
+		pair = strings.Trim(pair, " ")
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("showtime %q is not in number=playtime form", pair)
+		}
+		id, err := strconv.ParseInt(strings.Trim(kv[0], " "), 10, 32)
+		if err != nil {
+			return fmt.Errorf("show number %q is not a number", kv[0])
+		}
+		parsed = append(parsed, Showtime{ID: int32(id), PlayTime: strings.Trim(kv[1], " ")})
+	}
+	*showtimes = parsed
+	return nil
+}
+
+// Validate checks the configuration and reports every invalid or missing field
+func (cfg *Config) Validate() error {
+	errs := make(Errors, 0)
+
+	required := []struct {
+		val, name string
+	}{
+		{cfg.GRPCPort, "grpc_port"},
+		{cfg.MovieAPIAddress, "movie_host"},
+		{cfg.MovieAPIPort, "movie_port"},
+		{cfg.AccountServiceAddress, "account_host"},
+		{cfg.AccountServicePort, "account_port"},
+	}
+	if !cfg.Insecure {
+		required = append(required, []struct {
+			val, name string
+		}{
+			{cfg.TLSCertPath, "tls_cert"},
+			{cfg.TLSKeyPath, "tls_key"},
+			{cfg.MovieAPICAPath, "movie_ca"},
+			{cfg.AccountServiceCAPath, "account_ca"},
+		}...)
+	}
+	for _, field := range required {
+		if strings.Trim(field.val, " ") == "" {
+			errs = append(errs, fmt.Sprintf("%s is required", field.name))
+		}
+	}
+
+	if cfg.AdminPort != "" && (cfg.AdminPort == cfg.GRPCPort || cfg.AdminPort == cfg.RESTPort) {
+		errs = append(errs, fmt.Sprintf("admin_port %s must differ from grpc_port and rest_port", cfg.AdminPort))
+	}
+	if strings.Trim(cfg.AdminHost, " ") != "" && !strings.HasPrefix(cfg.AdminPort, ":") {
+		errs = append(errs, "admin_host requires admin_port to be a port such as :5700")
+	}
+
+	if cfg.ShutdownTimeout <= 0 {
+		errs = append(errs, "shutdown_timeout must be positive")
+	}
+
+	if cfg.LogLevel < -1 || cfg.LogLevel > 5 {
+		errs = append(errs, fmt.Sprintf("log_level %d is not between -1 and 5", cfg.LogLevel))
+	}
+
+	switch strings.ToLower(cfg.TLSClientAuth) {
+	case "", "optional":
+	case "require":
+		if cfg.Insecure {
+			errs = append(errs, "tls_client_auth require cannot be used with insecure")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown tls_client_auth %q", cfg.TLSClientAuth))
+	}
+
+	switch strings.ToLower(cfg.AuthMode) {
+	case "", "remote":
+	case "local":
+		if strings.Trim(cfg.JWTPublicKeyPath, " ") == "" && strings.Trim(cfg.JWKSPath, " ") == "" {
+			errs = append(errs, "jwt_public_key or jwks is required for local authentication")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown auth_mode %q", cfg.AuthMode))
+	}
+
+	switch strings.ToLower(cfg.TraceExporter) {
+	case "", "otlp", "stdout", "none":
+	default:
+		errs = append(errs, fmt.Sprintf("unknown trace_exporter %q", cfg.TraceExporter))
+	}
+
+	switch strings.ToLower(cfg.StoreBackend) {
+	case "memory":
+	case "file":
+		if strings.Trim(cfg.SnapshotPath, " ") == "" {
+			errs = append(errs, "snapshot_path is required for file store backend")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown store_backend %q", cfg.StoreBackend))
+	}
+
+	if strings.Trim(cfg.AuditLogPath, " ") != "" && cfg.AuditLogMaxSize <= 0 {
+		errs = append(errs, "audit_log_max_size must be positive")
+	}
+	if cfg.AuditLogMaxFiles < 0 {
+		errs = append(errs, "audit_log_max_files must not be negative")
+	}
+	for _, proxy := range cfg.AuditTrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Sprintf("audit_trusted_proxies %q is not an address or CIDR range", proxy))
+		}
+	}
+
+	errs = append(errs, cfg.validateSchedule()...)
+
+	if len(errs) != 0 {
+		return errs
 	}
 
 	return nil
 }
+
+// validates screens, showtimes, voting and intervals
+func (cfg *Config) validateSchedule() Errors {
+	errs := make(Errors, 0)
+
+	if len(cfg.Screens) == 0 {
+		errs = append(errs, "at least one screen is required")
+	}
+	screens := make(map[string]bool, len(cfg.Screens))
+	for _, screen := range cfg.Screens {
+		switch {
+		case strings.Trim(screen, " ") == "":
+			errs = append(errs, "screen name cannot be empty")
+		case screens[screen]:
+			errs = append(errs, fmt.Sprintf("screen %q is listed more than once", screen))
+		}
+		screens[screen] = true
+	}
+
+	if len(cfg.Showtimes) == 0 {
+		errs = append(errs, "at least one showtime is required")
+	}
+	shows := make(map[int32]bool, len(cfg.Showtimes))
+	for _, showtime := range cfg.Showtimes {
+		switch {
+		case showtime.ID <= 0:
+			errs = append(errs, fmt.Sprintf("show number %d must be positive", showtime.ID))
+		case shows[showtime.ID]:
+			errs = append(errs, fmt.Sprintf("show number %d is listed more than once", showtime.ID))
+		}
+		if strings.Trim(showtime.PlayTime, " ") == "" {
+			errs = append(errs, fmt.Sprintf("play time for show %d is required", showtime.ID))
+		}
+		shows[showtime.ID] = true
+	}
+
+	if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+		errs = append(errs, fmt.Sprintf("time_zone %q: %v", cfg.TimeZone, err))
+	}
+
+	if cfg.MaxMoviesVoted <= 0 {
+		errs = append(errs, "max_movies_voted must be positive")
+	}
+
+	if cfg.HistorySize < 0 {
+		errs = append(errs, "history_size must not be negative")
+	}
+
+	if cfg.HistoryArchiveSize < 0 {
+		errs = append(errs, "history_archive_size must not be negative")
+	}
+
+	if cfg.SnapshotInterval <= 0 {
+		errs = append(errs, "snapshot_interval must be positive")
+	}
+
+	if cfg.MovieRefreshInterval <= 0 {
+		errs = append(errs, "movie_refresh_interval must be positive")
+	}
+
+	if cfg.IdempotencyWindow <= 0 {
+		errs = append(errs, "idempotency_window must be positive")
+	}
+
+	if cfg.IdempotencyMaxKeys <= 0 {
+		errs = append(errs, "idempotency_max_keys must be positive")
+	}
+
+	return errs
+}
+
+// deprecatedSetting is a setting that was renamed. Its old name is still read
+type deprecatedSetting struct {
+	oldName, newName string
+	old, new         *string
+	def              string
+}
+
+// applies deprecated settings to the settings that replaced them, unless those are set to something else
+func (cfg *Config) applyDeprecated() Errors {
+	errs := make(Errors, 0)
+
+	settings := []deprecatedSetting{
+		{"movie_cert", "movie_ca", &cfg.MovieAPICertPath, &cfg.MovieAPICAPath, defaultOf("MovieAPICAPath")},
+		{
+			"account_cert", "account_ca", &cfg.AccountServiceCertPath, &cfg.AccountServiceCAPath,
+			defaultOf("AccountServiceCAPath"),
+		},
+	}
+	for _, setting := range settings {
+		switch {
+		case *setting.old == "":
+		case *setting.new == setting.def || *setting.new == *setting.old:
+			*setting.new = *setting.old
+			cfg.deprecated = append(cfg.deprecated, fmt.Sprintf(
+				"%s is deprecated, use %s instead", setting.oldName, setting.newName,
+			))
+		default:
+			errs = append(errs, fmt.Sprintf(
+				"%s is deprecated and conflicts with %s, set only %s", setting.oldName, setting.newName, setting.newName,
+			))
+		}
+	}
+
+	return errs
+}
+
+// Deprecated returns a warning for every deprecated setting used by the configuration
+func (cfg *Config) Deprecated() []string {
+	return cfg.deprecated
+}
+
+// returns the default value of a field of Config
+func defaultOf(fieldName string) string {
+	field, _ := reflect.TypeOf(Config{}).FieldByName(fieldName)
+	return field.Tag.Get("default")
+}
+
+// Errors contains every problem found while loading or validating configuration
+type Errors []string
+
+func (errs Errors) Error() string {
+	return "invalid configuration:\n\t" + strings.Join(errs, "\n\t")
+}
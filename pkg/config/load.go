@@ -0,0 +1,209 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Load builds the configuration from defaults, an optional config file, environment variables and flags.
+// Later sources override earlier ones, so a flag always wins over the same setting in the environment or file.
+// args are the command line arguments without the program name.
+// The config file is given by the -config flag or the CONFIG_FILE environment variable.
+// Calling Load again with the same args picks up changes made to the config file.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{args: args}
+
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+
+	flagVals := make(map[string]*flagValue)
+	forEachField(cfg, func(field reflect.StructField, _ reflect.Value) {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return
+		}
+		flagVals[name] = &flagValue{
+			val:    field.Tag.Get("default"),
+			isBool: field.Type.Kind() == reflect.Bool,
+		}
+		fs.Var(flagVals[name], name, field.Tag.Get("usage"))
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	errs := make(Errors, 0)
+
+	// Defaults
+	forEachField(cfg, func(field reflect.StructField, val reflect.Value) {
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setField(val, def); err != nil {
+				errs = append(errs, fmt.Sprintf("default for %s: %v", field.Name, err))
+			}
+		}
+	})
+
+	// Config file
+	cfg.ConfigFile = os.Getenv("CONFIG_FILE")
+	if setFlags["config"] {
+		cfg.ConfigFile = flagVals["config"].val
+	}
+	if cfg.ConfigFile != "" {
+		if err := loadFile(cfg.ConfigFile, cfg); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// Environment variables
+	forEachField(cfg, func(field reflect.StructField, val reflect.Value) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		if raw, ok := os.LookupEnv(name); ok {
+			if err := setField(val, raw); err != nil {
+				errs = append(errs, fmt.Sprintf("environment variable %s: %v", name, err))
+			}
+		}
+	})
+
+	// Flags that were passed explicitly
+	forEachField(cfg, func(field reflect.StructField, val reflect.Value) {
+		name := field.Tag.Get("flag")
+		if !setFlags[name] {
+			return
+		}
+		if err := setField(val, flagVals[name].val); err != nil {
+			errs = append(errs, fmt.Sprintf("flag -%s: %v", name, err))
+		}
+	})
+
+	// Settings that were renamed are still read under their old names
+	errs = append(errs, cfg.applyDeprecated()...)
+
+	// Report problems with values together with those found by validation
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err.(Errors)...)
+	}
+
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	return cfg, nil
+}
+
+// decodes a YAML or TOML file into cfg, choosing the format from the file extension
+func loadFile(path string, cfg *Config) error {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(bs, cfg)
+	case ".toml":
+		var md toml.MetaData
+		md, err = toml.Decode(string(bs), cfg)
+		if err == nil && len(md.Undecoded()) != 0 {
+			err = fmt.Errorf("unknown keys %v", md.Undecoded())
+		}
+	default:
+		return fmt.Errorf("config file %s must have .yaml, .yml or .toml extension", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode config file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// calls fn for every field of cfg
+func forEachField(cfg *Config, fn func(reflect.StructField, reflect.Value)) {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fn(t.Field(i), v.Field(i))
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// sets the field from its string representation
+func setField(val reflect.Value, raw string) error {
+	if fv, ok := val.Addr().Interface().(flag.Value); ok {
+		return fv.Set(raw)
+	}
+
+	switch {
+	case val.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		val.SetInt(int64(d))
+	case val.Kind() == reflect.String:
+		val.SetString(raw)
+	case val.Kind() == reflect.Int:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		val.SetInt(int64(i))
+	case val.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		val.SetBool(b)
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.String:
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.Trim(item, " "); item != "" {
+				items = append(items, item)
+			}
+		}
+		val.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", val.Type())
+	}
+
+	return nil
+}
+
+// flagValue holds the raw value of a flag until it is applied over the other sources
+type flagValue struct {
+	val    string
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.val
+}
+
+func (f *flagValue) Set(val string) error {
+	f.val = val
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be passed without a value e.g -insecure
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
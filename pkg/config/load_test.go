@@ -0,0 +1,198 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("grpc_port: \":7000\"\nlog_level: 1\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		args         []string
+		wantGRPCPort string
+		wantLogLevel int
+		wantInsecure bool
+	}{
+		{
+			name:         "defaults",
+			wantGRPCPort: ":5600",
+			wantLogLevel: 0,
+		},
+		{
+			name:         "file over defaults",
+			args:         []string{"-config", configFile},
+			wantGRPCPort: ":7000",
+			wantLogLevel: 1,
+		},
+		{
+			name:         "file from environment",
+			env:          map[string]string{"CONFIG_FILE": configFile},
+			wantGRPCPort: ":7000",
+			wantLogLevel: 1,
+		},
+		{
+			name:         "environment over file",
+			env:          map[string]string{"GRPC_PORT": ":7100"},
+			args:         []string{"-config", configFile},
+			wantGRPCPort: ":7100",
+			wantLogLevel: 1,
+		},
+		{
+			name:         "flag over environment and file",
+			env:          map[string]string{"GRPC_PORT": ":7100", "LOG_LEVEL": "2"},
+			args:         []string{"-config", configFile, "-grpc-port", ":7200"},
+			wantGRPCPort: ":7200",
+			wantLogLevel: 2,
+		},
+		{
+			name:         "flag set to the default over environment",
+			env:          map[string]string{"GRPC_PORT": ":7100"},
+			args:         []string{"-grpc-port", ":5600"},
+			wantGRPCPort: ":5600",
+			wantLogLevel: 0,
+		},
+		{
+			name:         "boolean flag without value",
+			env:          map[string]string{"INSECURE": "false"},
+			args:         []string{"-insecure"},
+			wantGRPCPort: ":5600",
+			wantLogLevel: 0,
+			wantInsecure: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"CONFIG_FILE", "GRPC_PORT", "LOG_LEVEL", "INSECURE"} {
+				t.Setenv(name, tt.env[name])
+				if _, ok := tt.env[name]; !ok {
+					os.Unsetenv(name)
+				}
+			}
+
+			cfg, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("Load(%q) failed: %v", tt.args, err)
+			}
+			if cfg.GRPCPort != tt.wantGRPCPort {
+				t.Errorf("GRPCPort = %q, want %q", cfg.GRPCPort, tt.wantGRPCPort)
+			}
+			if cfg.LogLevel != tt.wantLogLevel {
+				t.Errorf("LogLevel = %d, want %d", cfg.LogLevel, tt.wantLogLevel)
+			}
+			if cfg.Insecure != tt.wantInsecure {
+				t.Errorf("Insecure = %t, want %t", cfg.Insecure, tt.wantInsecure)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{name: "unknown flag", args: []string{"-no-such-flag"}},
+		{name: "bad number in flag", args: []string{"-log-level", "high"}},
+		{name: "bad number in environment", env: map[string]string{"LOG_LEVEL": "high"}},
+		{name: "invalid value", args: []string{"-store-backend", "tape"}},
+		{name: "missing config file", args: []string{"-config", "no-such-file.yaml"}},
+		{name: "admin host without admin port", args: []string{"-admin-host", "10.0.0.5"}},
+		{name: "negative audit log files", args: []string{"-audit-log-max-files", "-1"}},
+		{name: "trusted proxy host name", args: []string{"-audit-trusted-proxies", "127.0.0.1,gateway"}},
+		{name: "admin host with admin address", args: []string{"-admin-host", "10.0.0.5", "-admin-port", "10.0.0.6:5700"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"CONFIG_FILE", "LOG_LEVEL"} {
+				t.Setenv(name, tt.env[name])
+				if _, ok := tt.env[name]; !ok {
+					os.Unsetenv(name)
+				}
+			}
+
+			if _, err := Load(tt.args); err == nil {
+				t.Errorf("Load(%q) succeeded, want error", tt.args)
+			}
+		})
+	}
+}
+
+func TestUpstreamCAPaths(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantMovieCA    string
+		wantAccountCA  string
+		wantDeprecated int
+		wantErr        bool
+	}{
+		{
+			name:          "defaults of the deprecated settings",
+			wantMovieCA:   "certs/cert.pem",
+			wantAccountCA: "certs/cert.pem",
+		},
+		{
+			name:           "deprecated settings",
+			args:           []string{"-movie-cert", "movie.pem", "-account-cert", "account.pem"},
+			wantMovieCA:    "movie.pem",
+			wantAccountCA:  "account.pem",
+			wantDeprecated: 2,
+		},
+		{
+			name:          "new settings",
+			args:          []string{"-movie-ca", "movie-ca.pem"},
+			wantMovieCA:   "movie-ca.pem",
+			wantAccountCA: "certs/cert.pem",
+		},
+		{
+			name:    "conflicting settings",
+			args:    []string{"-movie-ca", "movie-ca.pem", "-movie-cert", "movie.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{"CONFIG_FILE", "MOVIE_CA_PATH", "MOVIE_CERT_PATH", "ACCOUNT_CA_PATH", "ACCOUNT_CERT_PATH"}
+			for _, name := range names {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+
+			cfg, err := Load(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load(%q) error = %v, want error %t", tt.args, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cfg.MovieAPICAPath != tt.wantMovieCA {
+				t.Errorf("MovieAPICAPath = %q, want %q", cfg.MovieAPICAPath, tt.wantMovieCA)
+			}
+			if cfg.AccountServiceCAPath != tt.wantAccountCA {
+				t.Errorf("AccountServiceCAPath = %q, want %q", cfg.AccountServiceCAPath, tt.wantAccountCA)
+			}
+			if len(cfg.Deprecated()) != tt.wantDeprecated {
+				t.Errorf("Deprecated() = %q, want %d warnings", cfg.Deprecated(), tt.wantDeprecated)
+			}
+		})
+	}
+}
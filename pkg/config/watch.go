@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// writes to the config file often arrive as several events; they are handled once things settle
+const reloadDebounce = 500 * time.Millisecond
+
+// Reload loads the configuration again using the arguments cfg was loaded with
+func (cfg *Config) Reload() (*Config, error) {
+	return Load(cfg.args)
+}
+
+// Watch reloads the configuration when the config file changes or the process receives SIGHUP.
+// onReload is called with the new configuration, or the error that prevented loading it.
+// Watch blocks until ctx is cancelled.
+func Watch(ctx context.Context, cfg *Config, onReload func(*Config, error)) error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var events <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if cfg.ConfigFile != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		// Watch the directory since editors and config maps replace the file rather than write to it.
+		// Config maps swap a symlink to a new directory, so events may not name the file at all
+		err = watcher.Add(filepath.Dir(cfg.ConfigFile))
+		if err != nil {
+			return err
+		}
+		events, watchErrs = watcher.Events, watcher.Errors
+	}
+
+	configFile := filepath.Clean(cfg.ConfigFile)
+	contents := fingerprint(configFile)
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sighup:
+			contents = fingerprint(configFile)
+			onReload(cfg.Reload())
+		case event := <-events:
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			debounce = nil
+			// Events in the directory may be for other files
+			if latest := fingerprint(configFile); latest != contents {
+				contents = latest
+				onReload(cfg.Reload())
+			}
+		case err := <-watchErrs:
+			onReload(nil, err)
+		}
+	}
+}
+
+// returns a hash of the contents of the config file, following symlinks, or empty if it cannot be read
+func fingerprint(path string) string {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(bs)
+	return string(sum[:])
+}
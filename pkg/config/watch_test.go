@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// time given to the watcher to start, and to a change to be reloaded
+const (
+	watchStart   = 200 * time.Millisecond
+	watchTimeout = 5 * time.Second
+)
+
+type reload struct {
+	cfg *Config
+	err error
+}
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		change       func(t *testing.T, dir, configFile string)
+		wantReload   bool
+		wantErr      bool
+		wantGRPCPort string
+	}{
+		{
+			name: "file written",
+			change: func(t *testing.T, dir, configFile string) {
+				writeConfig(t, configFile, ":7001")
+			},
+			wantReload:   true,
+			wantGRPCPort: ":7001",
+		},
+		{
+			name: "file replaced",
+			change: func(t *testing.T, dir, configFile string) {
+				replacement := filepath.Join(dir, "config.yaml.new")
+				writeConfig(t, replacement, ":7002")
+				if err := os.Rename(replacement, configFile); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantReload:   true,
+			wantGRPCPort: ":7002",
+		},
+		{
+			name: "SIGHUP",
+			change: func(t *testing.T, dir, configFile string) {
+				if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantReload:   true,
+			wantGRPCPort: ":7000",
+		},
+		{
+			name: "invalid file",
+			change: func(t *testing.T, dir, configFile string) {
+				writeFile(t, configFile, "log_level: loud\n")
+			},
+			wantReload: true,
+			wantErr:    true,
+		},
+		{
+			name: "other file in the directory",
+			change: func(t *testing.T, dir, configFile string) {
+				writeFile(t, filepath.Join(dir, "other.yaml"), "grpc_port: \":7003\"\n")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t)
+			dir := tempDir(t)
+			configFile := filepath.Join(dir, "config.yaml")
+			writeConfig(t, configFile, ":7000")
+
+			reloads := watch(t, configFile)
+			tt.change(t, dir, configFile)
+
+			// Changes are reloaded once they settle
+			timeout := watchTimeout
+			if !tt.wantReload {
+				timeout = 3 * reloadDebounce
+			}
+
+			select {
+			case got := <-reloads:
+				if !tt.wantReload {
+					t.Fatalf("reloaded after an unrelated change: %v", got.err)
+				}
+				if (got.err != nil) != tt.wantErr {
+					t.Fatalf("reload error = %v, want error %t", got.err, tt.wantErr)
+				}
+				if got.err == nil && got.cfg.GRPCPort != tt.wantGRPCPort {
+					t.Errorf("GRPCPort = %q, want %q", got.cfg.GRPCPort, tt.wantGRPCPort)
+				}
+			case <-time.After(timeout):
+				if tt.wantReload {
+					t.Fatal("configuration was not reloaded")
+				}
+			}
+		})
+	}
+}
+
+// starts watching the configuration loaded from configFile and returns its reloads
+func watch(t *testing.T, configFile string) <-chan reload {
+	t.Helper()
+	cfg, err := Load([]string{"-config", configFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloads := make(chan reload, 10)
+	stopped := make(chan error)
+	go func() {
+		stopped <- Watch(ctx, cfg, func(newCfg *Config, err error) {
+			reloads <- reload{newCfg, err}
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-stopped; err != nil {
+			t.Errorf("Watch() failed: %v", err)
+		}
+	})
+
+	time.Sleep(watchStart)
+	return reloads
+}
+
+func writeConfig(t *testing.T, path, grpcPort string) {
+	t.Helper()
+	writeFile(t, path, "grpc_port: \""+grpcPort+"\"\n")
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// unsets the environment variables that would override the config file
+func unsetEnv(t *testing.T) {
+	for _, name := range []string{"CONFIG_FILE", "GRPC_PORT", "LOG_LEVEL"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	unsetEnv(t)
+	dir := tempDir(t)
+
+	// Config maps are mounted as a symlink to a data directory that is swapped on update
+	data := func(name, grpcPort string) {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		writeConfig(t, filepath.Join(dir, name, "config.yaml"), grpcPort)
+		if err := os.Symlink(name, filepath.Join(dir, "..data_tmp")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data("..v1", ":7000")
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := watch(t, configFile)
+	data("..v2", ":7001")
+
+	select {
+	case got := <-reloads:
+		if got.err != nil {
+			t.Fatalf("reload failed: %v", got.err)
+		}
+		if got.cfg.GRPCPort != ":7001" {
+			t.Errorf("GRPCPort = %q, want %q", got.cfg.GRPCPort, ":7001")
+		}
+	case <-time.After(watchTimeout):
+		t.Fatal("configuration was not reloaded")
+	}
+}
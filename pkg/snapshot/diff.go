@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+)
+
+// Slot identifies a show on a screen on a day of the week
+type Slot struct {
+	WeekDay int32
+	Screen  string
+	Show    int32
+}
+
+func (slot Slot) String() string {
+	return fmt.Sprintf("day %d %s show %d", slot.WeekDay, slot.Screen, slot.Show)
+}
+
+// Difference is a change to one field of a slot between two schedules
+type Difference struct {
+	Slot   Slot
+	Field  string
+	Before string
+	After  string
+}
+
+func (diff Difference) String() string {
+	return fmt.Sprintf("%s: %s %q -> %q", diff.Slot, diff.Field, diff.Before, diff.After)
+}
+
+// Slots returns every slot in the weekly schedule in day, screen and show order
+func Slots(weeklySchedule *scheduler.DaysSchedule) []Slot {
+	slots := make([]Slot, 0)
+	for weekDay, daySchedule := range weeklySchedule.GetDaysSchedule() {
+		for screen, screenSchedule := range daySchedule.GetScreensSchedule() {
+			for show := range screenSchedule.GetShowsSchedule() {
+				slots = append(slots, Slot{WeekDay: weekDay, Screen: screen, Show: show})
+			}
+		}
+	}
+	SortSlots(slots)
+	return slots
+}
+
+// SortSlots sorts slots in day, screen and show order
+func SortSlots(slots []Slot) {
+	sort.Slice(slots, func(i, j int) bool {
+		switch {
+		case slots[i].WeekDay != slots[j].WeekDay:
+			return slots[i].WeekDay < slots[j].WeekDay
+		case slots[i].Screen != slots[j].Screen:
+			return slots[i].Screen < slots[j].Screen
+		}
+		return slots[i].Show < slots[j].Show
+	})
+}
+
+// Lookup returns the show in a slot, or nil if the schedule has no such slot
+func Lookup(weeklySchedule *scheduler.DaysSchedule, slot Slot) *scheduler.ShowSchedule {
+	return weeklySchedule.GetDaysSchedule()[slot.WeekDay].
+		GetScreensSchedule()[slot.Screen].
+		GetShowsSchedule()[slot.Show]
+}
+
+// Diff returns the differences between two weekly schedules in slot order
+func Diff(before, after *scheduler.DaysSchedule) []Difference {
+	seen := make(map[Slot]bool)
+	slots := make([]Slot, 0)
+	for _, slot := range append(Slots(before), Slots(after)...) {
+		if !seen[slot] {
+			seen[slot] = true
+			slots = append(slots, slot)
+		}
+	}
+	SortSlots(slots)
+
+	diffs := make([]Difference, 0)
+	for _, slot := range slots {
+		diffs = append(diffs, diffShow(slot, Lookup(before, slot), Lookup(after, slot))...)
+	}
+	return diffs
+}
+
+func diffShow(slot Slot, before, after *scheduler.ShowSchedule) []Difference {
+	diffs := make([]Difference, 0)
+	add := func(field, beforeVal, afterVal string) {
+		if beforeVal != afterVal {
+			diffs = append(diffs, Difference{Slot: slot, Field: field, Before: beforeVal, After: afterVal})
+		}
+	}
+
+	switch {
+	case before == nil:
+		add("slot", "", "added")
+	case after == nil:
+		add("slot", "removed", "")
+	}
+
+	add("play_time", before.GetPlayTime(), after.GetPlayTime())
+	add("movie", before.GetMovie().GetId(), after.GetMovie().GetId())
+	add(
+		"votes",
+		fmt.Sprint(before.GetMovie().GetCurrentVotes()),
+		fmt.Sprint(after.GetMovie().GetCurrentVotes()),
+	)
+	add("voted_movies", votedMovies(before.GetVotedMovies()), votedMovies(after.GetVotedMovies()))
+
+	return diffs
+}
+
+// formats voted movies as id:votes pairs
+func votedMovies(movies []*movie.Movie) string {
+	pairs := make([]string, 0, len(movies))
+	for _, movieItem := range movies {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", movieItem.GetId(), movieItem.GetCurrentVotes()))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,141 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+)
+
+// builds a weekly schedule from the shows in each slot
+func weekOf(shows map[Slot]*scheduler.ShowSchedule) *scheduler.DaysSchedule {
+	weeklySchedule := &scheduler.DaysSchedule{DaysSchedule: make(map[int32]*scheduler.ScreensSchedule)}
+	for slot, showSchedule := range shows {
+		daySchedule, ok := weeklySchedule.DaysSchedule[slot.WeekDay]
+		if !ok {
+			daySchedule = &scheduler.ScreensSchedule{ScreensSchedule: make(map[string]*scheduler.ShowsSchedule)}
+			weeklySchedule.DaysSchedule[slot.WeekDay] = daySchedule
+		}
+		screenSchedule, ok := daySchedule.ScreensSchedule[slot.Screen]
+		if !ok {
+			screenSchedule = &scheduler.ShowsSchedule{ShowsSchedule: make(map[int32]*scheduler.ShowSchedule)}
+			daySchedule.ScreensSchedule[slot.Screen] = screenSchedule
+		}
+		screenSchedule.ShowsSchedule[slot.Show] = showSchedule
+	}
+	return weeklySchedule
+}
+
+// builds a show playing a movie at a time, with voted movies
+func showOf(playTime string, movieID string, votes int32, votedMovies ...*movie.Movie) *scheduler.ShowSchedule {
+	return &scheduler.ShowSchedule{
+		PlayTime:    playTime,
+		Movie:       &movie.Movie{Id: movieID, CurrentVotes: votes},
+		VotedMovies: votedMovies,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	slotA := Slot{WeekDay: 1, Screen: "A", Show: 1}
+	slotB := Slot{WeekDay: 2, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name   string
+		before map[Slot]*scheduler.ShowSchedule
+		after  map[Slot]*scheduler.ShowSchedule
+		want   []Difference
+	}{
+		{
+			name:   "same schedule",
+			before: map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 2)},
+			after:  map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 2)},
+			want:   []Difference{},
+		},
+		{
+			name:   "movie and votes changed",
+			before: map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 2)},
+			after:  map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m2", 0)},
+			want: []Difference{
+				{Slot: slotA, Field: "movie", Before: "m1", After: "m2"},
+				{Slot: slotA, Field: "votes", Before: "2", After: "0"},
+			},
+		},
+		{
+			name:   "play time changed",
+			before: map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 0)},
+			after:  map[Slot]*scheduler.ShowSchedule{slotA: showOf("11:00", "m1", 0)},
+			want:   []Difference{{Slot: slotA, Field: "play_time", Before: "10:00", After: "11:00"}},
+		},
+		{
+			name:   "voted movies changed",
+			before: map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 0, &movie.Movie{Id: "v1"})},
+			after: map[Slot]*scheduler.ShowSchedule{
+				slotA: showOf("10:00", "m1", 0, &movie.Movie{Id: "v1", CurrentVotes: 3}, &movie.Movie{Id: "v2"}),
+			},
+			want: []Difference{{Slot: slotA, Field: "voted_movies", Before: "v1:0", After: "v1:3,v2:0"}},
+		},
+		{
+			name:   "slot added",
+			before: map[Slot]*scheduler.ShowSchedule{},
+			after:  map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "m1", 0)},
+			want: []Difference{
+				{Slot: slotA, Field: "slot", Before: "", After: "added"},
+				{Slot: slotA, Field: "play_time", Before: "", After: "10:00"},
+				{Slot: slotA, Field: "movie", Before: "", After: "m1"},
+			},
+		},
+		{
+			name:   "slot removed",
+			before: map[Slot]*scheduler.ShowSchedule{slotA: showOf("10:00", "", 0)},
+			after:  map[Slot]*scheduler.ShowSchedule{},
+			want: []Difference{
+				{Slot: slotA, Field: "slot", Before: "removed", After: ""},
+				{Slot: slotA, Field: "play_time", Before: "10:00", After: ""},
+			},
+		},
+		{
+			name: "differences in slot order",
+			before: map[Slot]*scheduler.ShowSchedule{
+				slotA: showOf("10:00", "m1", 0),
+				slotB: showOf("10:00", "m1", 0),
+			},
+			after: map[Slot]*scheduler.ShowSchedule{
+				slotA: showOf("10:00", "m2", 0),
+				slotB: showOf("10:00", "m3", 0),
+			},
+			want: []Difference{
+				{Slot: slotA, Field: "movie", Before: "m1", After: "m2"},
+				{Slot: slotB, Field: "movie", Before: "m1", After: "m3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(weekOf(tt.before), weekOf(tt.after))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlots(t *testing.T) {
+	weeklySchedule := weekOf(map[Slot]*scheduler.ShowSchedule{
+		{WeekDay: 2, Screen: "A", Show: 1}: showOf("10:00", "", 0),
+		{WeekDay: 1, Screen: "B", Show: 1}: showOf("10:00", "", 0),
+		{WeekDay: 1, Screen: "A", Show: 2}: showOf("12:00", "", 0),
+		{WeekDay: 1, Screen: "A", Show: 1}: showOf("10:00", "", 0),
+	})
+
+	want := []Slot{
+		{WeekDay: 1, Screen: "A", Show: 1},
+		{WeekDay: 1, Screen: "A", Show: 2},
+		{WeekDay: 1, Screen: "B", Show: 1},
+		{WeekDay: 2, Screen: "A", Show: 1},
+	}
+	if got := Slots(weeklySchedule); !reflect.DeepEqual(got, want) {
+		t.Errorf("Slots() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+// Package snapshot reads, writes, compares and checks snapshots of the weekly schedule
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// Unmarshal decodes a snapshot in binary protobuf or JSON form
+func Unmarshal(bs []byte) (*scheduler.DaysSchedule, error) {
+	weeklySchedule := &scheduler.DaysSchedule{}
+
+	var err error
+	if IsJSON(bs) {
+		err = jsonpb.Unmarshal(bytes.NewReader(bs), weeklySchedule)
+	} else {
+		err = proto.Unmarshal(bs, weeklySchedule)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if weeklySchedule.DaysSchedule == nil {
+		weeklySchedule.DaysSchedule = make(map[int32]*scheduler.ScreensSchedule)
+	}
+
+	return weeklySchedule, nil
+}
+
+// IsJSON reports whether the snapshot is in JSON form. Binary snapshots never start with {
+func IsJSON(bs []byte) bool {
+	trimmed := bytes.TrimLeft(bs, " \t\r\n")
+	return len(trimmed) != 0 && trimmed[0] == '{'
+}
+
+// MarshalJSON encodes a snapshot as indented JSON suitable for editing by hand
+func MarshalJSON(weeklySchedule *scheduler.DaysSchedule) ([]byte, error) {
+	marshaler := &jsonpb.Marshaler{OrigName: true, EmitDefaults: true, Indent: "  "}
+	buf := &bytes.Buffer{}
+	err := marshaler.Marshal(buf, weeklySchedule)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// ReadFile reads a snapshot file in binary or JSON form
+func ReadFile(path string) (*scheduler.DaysSchedule, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	weeklySchedule, err := Unmarshal(bs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %v", path, err)
+	}
+
+	return weeklySchedule, nil
+}
+
+// WriteFile writes a snapshot file in binary form, or JSON if asJSON is true.
+// The file is replaced atomically.
+func WriteFile(path string, weeklySchedule *scheduler.DaysSchedule, asJSON bool) error {
+	var (
+		bs  []byte
+		err error
+	)
+	if asJSON {
+		bs, err = MarshalJSON(weeklySchedule)
+	} else {
+		bs, err = proto.Marshal(weeklySchedule)
+	}
+	if err != nil {
+		return err
+	}
+
+	tmpFile := path + ".tmp"
+	err = ioutil.WriteFile(tmpFile, bs, 0666)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, path)
+}
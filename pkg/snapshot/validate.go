@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+)
+
+// Show is a show that every screen plays each day
+type Show struct {
+	ID       int32
+	PlayTime string
+}
+
+// Layout is the configured shape of the weekly schedule
+type Layout struct {
+	Screens        []string
+	Shows          []Show
+	MaxMoviesVoted int
+}
+
+// Validate checks the weekly schedule against the layout and returns every violation found.
+// Every configured slot must exist with its configured play time, a movie may appear only once within a show,
+// and no show may have more than MaxMoviesVoted voted movies.
+func Validate(weeklySchedule *scheduler.DaysSchedule, layout Layout) []string {
+	violations := make([]string, 0)
+
+	for weekDay := int32(1); weekDay <= 7; weekDay++ {
+		for _, screen := range layout.Screens {
+			for _, show := range layout.Shows {
+				slot := Slot{WeekDay: weekDay, Screen: screen, Show: show.ID}
+				showSchedule := Lookup(weeklySchedule, slot)
+				switch {
+				case showSchedule == nil:
+					violations = append(violations, fmt.Sprintf("%s: slot is missing", slot))
+				case showSchedule.GetPlayTime() != show.PlayTime:
+					violations = append(violations, fmt.Sprintf(
+						"%s: play time %q, configured %q", slot, showSchedule.GetPlayTime(), show.PlayTime,
+					))
+				}
+			}
+		}
+	}
+
+	for _, slot := range Slots(weeklySchedule) {
+		showSchedule := Lookup(weeklySchedule, slot)
+
+		seen := make(map[string]bool)
+		if id := showSchedule.GetMovie().GetId(); id != "" {
+			seen[id] = true
+		}
+		for _, votedMovie := range showSchedule.GetVotedMovies() {
+			id := votedMovie.GetId()
+			if seen[id] {
+				violations = append(violations, fmt.Sprintf("%s: movie %q appears more than once", slot, id))
+			}
+			seen[id] = true
+		}
+
+		if layout.MaxMoviesVoted > 0 && len(showSchedule.GetVotedMovies()) > layout.MaxMoviesVoted {
+			violations = append(violations, fmt.Sprintf(
+				"%s: %d voted movies, more than the maximum of %d",
+				slot, len(showSchedule.GetVotedMovies()), layout.MaxMoviesVoted,
+			))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gidyon/rupacinema/movie/pkg/api"
+	"github.com/gidyon/rupacinema/scheduling/pkg/api"
+)
+
+// builds a weekly schedule with every slot of the layout, changing the shows with edit
+func fullWeek(layout Layout, edit func(shows map[Slot]*scheduler.ShowSchedule)) *scheduler.DaysSchedule {
+	shows := make(map[Slot]*scheduler.ShowSchedule)
+	for weekDay := int32(1); weekDay <= 7; weekDay++ {
+		for _, screen := range layout.Screens {
+			for _, show := range layout.Shows {
+				shows[Slot{WeekDay: weekDay, Screen: screen, Show: show.ID}] = showOf(show.PlayTime, "", 0)
+			}
+		}
+	}
+	if edit != nil {
+		edit(shows)
+	}
+	return weekOf(shows)
+}
+
+func TestValidate(t *testing.T) {
+	layout := Layout{
+		Screens:        []string{"A"},
+		Shows:          []Show{{ID: 1, PlayTime: "10:00"}},
+		MaxMoviesVoted: 2,
+	}
+	slot := Slot{WeekDay: 3, Screen: "A", Show: 1}
+
+	tests := []struct {
+		name string
+		edit func(shows map[Slot]*scheduler.ShowSchedule)
+		want []string
+	}{
+		{
+			name: "valid",
+			want: []string{},
+		},
+		{
+			name: "missing slot",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) { delete(shows, slot) },
+			want: []string{"day 3 A show 1: slot is missing"},
+		},
+		{
+			name: "wrong play time",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) { shows[slot].PlayTime = "11:00" },
+			want: []string{`day 3 A show 1: play time "11:00", configured "10:00"`},
+		},
+		{
+			name: "movie showing and voted",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) {
+				shows[slot] = showOf("10:00", "m1", 0, &movie.Movie{Id: "m1"})
+			},
+			want: []string{`day 3 A show 1: movie "m1" appears more than once`},
+		},
+		{
+			name: "movie voted twice",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) {
+				shows[slot] = showOf("10:00", "", 0, &movie.Movie{Id: "m1"}, &movie.Movie{Id: "m1"})
+			},
+			want: []string{`day 3 A show 1: movie "m1" appears more than once`},
+		},
+		{
+			name: "too many voted movies",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) {
+				shows[slot] = showOf(
+					"10:00", "", 0, &movie.Movie{Id: "m1"}, &movie.Movie{Id: "m2"}, &movie.Movie{Id: "m3"},
+				)
+			},
+			want: []string{"day 3 A show 1: 3 voted movies, more than the maximum of 2"},
+		},
+		{
+			name: "slot outside the layout",
+			edit: func(shows map[Slot]*scheduler.ShowSchedule) {
+				shows[Slot{WeekDay: 3, Screen: "B", Show: 1}] = showOf(
+					"10:00", "", 0, &movie.Movie{Id: "m1"}, &movie.Movie{Id: "m2"}, &movie.Movie{Id: "m3"},
+				)
+			},
+			want: []string{"day 3 B show 1: 3 voted movies, more than the maximum of 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(fullWeek(layout, tt.edit), layout)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	// ServiceName is the name of the service reported in traces
+	ServiceName = "rupacinema-scheduling"
+
+	// ExporterOTLP exports spans to an OTLP collector over gRPC
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to standard output
+	ExporterStdout = "stdout"
+	// ExporterNone disables exporting of spans
+	ExporterNone = "none"
+)
+
+// Init sets the global tracer provider and propagator.
+// exporter - one of otlp, stdout or none
+// otlpEndpoint - address of the OTLP collector e.g localhost:4317
+// The returned function flushes pending spans and stops the tracer provider.
+func Init(ctx context.Context, exporter, otlpEndpoint string) (func(context.Context) error, error) {
+	// Propagate trace context and baggage over HTTP headers and gRPC metadata
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var (
+		spanExporter sdktrace.SpanExporter
+		err          error
+	)
+
+	switch strings.ToLower(strings.Trim(exporter, " ")) {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP, "":
+		if strings.Trim(otlpEndpoint, " ") == "" {
+			otlpEndpoint = "localhost:4317"
+		}
+		spanExporter, err = otlptracegrpc.New(
+			ctx,
+			otlptracegrpc.WithEndpoint(otlpEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(ServiceName),
+		)),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestInit(t *testing.T) {
+	global := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(global)
+
+	tests := []struct {
+		exporter     string
+		wantErr      bool
+		wantProvider bool // whether spans are exported by a tracer provider set globally
+	}{
+		{exporter: "otlp", wantProvider: true},
+		{exporter: "", wantProvider: true},
+		{exporter: " OTLP ", wantProvider: true},
+		{exporter: "stdout", wantProvider: true},
+		{exporter: "none"},
+		{exporter: "jaeger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			otel.SetTracerProvider(global)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			shutdown, err := Init(ctx, tt.exporter, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init(%q) error = %v, want error %t", tt.exporter, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			_, isSDK := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			if isSDK != tt.wantProvider {
+				t.Errorf("Init(%q) set a tracer provider = %t, want %t", tt.exporter, isSDK, tt.wantProvider)
+			}
+
+			err = shutdown(ctx)
+			if err != nil {
+				t.Errorf("shutdown failed: %v", err)
+			}
+		})
+	}
+}